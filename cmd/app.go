@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/config"
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+	"github.com/amir-amirov/go-news-feed-bot/internal/fetcher"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
+)
+
+// Settings used by the subcommands, see config.FlagSet.Load.
+var (
+	databaseSettings = []string{"database_dsn"}
+	fetchSettings    = append([]string{"fetch_interval", "filter_keywords"}, databaseSettings...)
+	summarySettings  = []string{"openai_key", "openai_model", "openai_prompt", "summary_max_sentences"}
+)
+
+// newFlagSet returns the flags of the subcommand, usage is printed on -h and invalid flags.
+func newFlagSet(name, usage string) *config.FlagSet {
+	flags := config.NewFlagSet("news-feed-bot " + name)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		fmt.Fprintln(flags.Output(), "\nThe configuration flags of config check are accepted as well.")
+	}
+	return flags
+}
+
+// parse parses the flags and loads the settings, printing errors. It returns the exit code
+// for the subcommand to return if it can't go on.
+func parse(flags *config.FlagSet, args []string, settings ...string) (*config.Config, int, bool) {
+	if err := flags.Parse(args); err != nil {
+		return nil, parseErrorCode(err), false
+	}
+
+	cfg, err := flags.Load(settings...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return nil, 1, false
+	}
+	return cfg, 0, true
+}
+
+// parseErrorCode returns the exit code of a flag parsing error, the flag package has already
+// printed the usage.
+func parseErrorCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+// signalContext returns a context canceled on SIGINT (Ctrl+C) and SIGTERM (docker stop).
+// A second signal kills the process right away.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// openStorage connects to the database, applies pending migrations and returns the repositories.
+// Dry runs leave the schema as it is and fail if migrations are pending.
+func openStorage(ctx context.Context, cfg *config.Config, dryRun bool) (*db.Database, *storage.Storage, error) {
+	database, err := db.Open(cfg.DatabaseDSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	migrate := database.Migrate
+	if dryRun {
+		migrate = database.CheckMigrated
+	}
+	if err := migrate(ctx); err != nil {
+		database.Close()
+		return nil, nil, err
+	}
+
+	repositories, err := storage.New(database)
+	if err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	return database, repositories, nil
+}
+
+func newSummarizer(cfg *config.Config) (*summary.OpenAISummarizer, error) {
+	summarizer, err := summary.NewOpenAISummarizer(cfg.OpenAIKey, cfg.OpenAIModel, cfg.OpenAIPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summarizer: %w", err)
+	}
+	return summarizer, nil
+}
+
+func newFetcher(cfg *config.Config, repositories *storage.Storage) *fetcher.Fetcher {
+	return fetcher.New(repositories.Articles, repositories.Sources, cfg.FetchInterval, cfg.FilterKeywords)
+}
+
+func newNotifier(
+	cfg *config.Config,
+	repositories *storage.Storage,
+	summarizer notifier.Summarizer,
+	sender notifier.Sender,
+) *notifier.Notifier {
+	return notifier.New(
+		repositories.Articles,
+		repositories.Sources,
+		repositories.Channels,
+		repositories.Deliveries,
+		summarizer,
+		rank.New(cfg.RankKeywords, cfg.RankHalfLife),
+		sender,
+		cfg.ModerationChatID,
+		cfg.NotificationInterval,
+		cfg.LookupTimeWindow,
+		cfg.ClaimLease,
+		cfg.SummaryMaxSentences,
+		cfg.DeleteRetractedPosts,
+	)
+}
+
+// registerChannel adds the channel from the configuration to the channels table. It is always
+// posted to, others can be added to the table.
+func registerChannel(ctx context.Context, cfg *config.Config, repositories *storage.Storage) error {
+	if err := repositories.Channels.Upsert(ctx, model.Channel{
+		ID:        cfg.TelegramChannelID,
+		Language:  cfg.TelegramChannelLang,
+		Moderated: cfg.ModerateChannel,
+		Tags:      cfg.TelegramChannelTags,
+	}); err != nil {
+		return fmt.Errorf("failed to register channel %d: %w", cfg.TelegramChannelID, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/config"
+)
+
+const configUsage = `Usage: news-feed-bot config check [flags]
+
+Loads the configuration like the bot does (defaults, the config file, environment variables
+and flags), prints the settings and where they come from, and lists every invalid one.
+Settings applied on SIGHUP without a restart: %s.`
+
+// runConfig runs the config subcommand and returns the exit code.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintf(os.Stderr, configUsage+"\n", strings.Join(config.Reloadable(), ", "))
+		return 2
+	}
+
+	cfg, err := config.Load(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, value := range cfg.Values() {
+		v := value.Value
+		if value.Secret && v != "" {
+			v = "********"
+		}
+		fmt.Fprintf(w, "%s\t%q\t%s\n", value.Setting, v, value.Source)
+	}
+	w.Flush()
+
+	fmt.Println("configuration is valid")
+	return 0
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/fetcher"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const fetchUsage = `Usage: news-feed-bot fetch [-once] [-source ID] [-dry-run] [flags]
+
+Fetches the sources every fetch interval until SIGINT or SIGTERM, or once with -once.
+With -dry-run the sources are fetched once and the articles that would be stored are
+printed instead.`
+
+// runFetch runs the fetch subcommand and returns the exit code.
+func runFetch(args []string) int {
+	flags := newFlagSet("fetch", fetchUsage)
+	once := flags.Bool("once", false, "fetch once and exit")
+	sourceID := flags.Int64("source", 0, "fetch only the source with the ID")
+	dryRun := flags.Bool("dry-run", false, "print the articles instead of storing them")
+
+	cfg, code, ok := parse(flags, args, fetchSettings...)
+	if !ok {
+		return code
+	}
+	if flags.NArg() > 0 || *sourceID != 0 && !*once && !*dryRun {
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	database, repositories, err := openStorage(ctx, cfg, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.Close()
+
+	f := newFetcher(cfg, repositories)
+	if !*once && !*dryRun {
+		f.Start(ctx)
+		return 0
+	}
+
+	sources, err := selectSources(ctx, repositories.Sources, *sourceID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if !*dryRun {
+		f.FetchSources(ctx, sources)
+		return 0
+	}
+
+	if err := printArticles(ctx, f, sources); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// selectSources returns the source with the ID, paused or not, or all sources that aren't
+// paused if the ID is zero.
+func selectSources(ctx context.Context, sources storage.SourceRepository, id int64) ([]model.Source, error) {
+	if id == 0 {
+		list, err := sources.Sources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch sources: %w", err)
+		}
+		return slices.DeleteFunc(list, func(source model.Source) bool { return source.Paused }), nil
+	}
+
+	source, err := sources.SourceByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source %d: %w", id, err)
+	}
+	return []model.Source{*source}, nil
+}
+
+func printArticles(ctx context.Context, f *fetcher.Fetcher, sources []model.Source) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tPUBLISHED\tLANG\tTITLE\tLINK")
+
+	var failed int
+	for _, source := range sources {
+		articles, err := f.Articles(ctx, source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to fetch %s: %v\n", source.Name, err)
+			failed++
+			continue
+		}
+
+		for _, article := range articles {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				source.Name,
+				article.PublishedAt.Format("2006-01-02 15:04"),
+				article.Language,
+				article.Title,
+				article.Link,
+			)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sources failed", failed, len(sources))
+	}
+	return nil
+}
@@ -1,68 +1,49 @@
 package main
 
 import (
-	"context"
-	"log"
-
-	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
-	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
-	"github.com/amir-amirov/go-news-feed-bot/internal/config"
-	"github.com/amir-amirov/go-news-feed-bot/internal/db"
-	"github.com/amir-amirov/go-news-feed-bot/internal/fetcher"
-	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
-	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
-	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"fmt"
+	"os"
+	"strings"
 )
 
-func main() {
-
-	config := config.Load()
-
-	botAPI, err := tgbotapi.NewBotAPI(config.TelegramBotToken)
-	if err != nil {
-		log.Fatalf("Failed to create bot API: %v", err)
-	}
-	log.Printf("Bot authorized as %s", botAPI.Self.UserName)
-
-	db.InitDB(config.DatabaseDSN)
+const usage = `Usage: news-feed-bot <command> [arguments]
 
-	filterKeywords := []string{"leetcode"}
+Commands:
+  run        run the fetcher, the notifier and the bot (the default)
+  migrate    apply or revert database migrations
+  sources    add, list, remove and import sources
+  fetch      fetch sources, once or until interrupted
+  notify     send due posts, once or until interrupted
+  summarize  summarize an article page with the configured prompt
+  config     check the configuration
 
-	var (
-		sourceRespository  = storage.NewSourcePostgresStorage(db.DB)
-		articleRespository = storage.NewArticlePostgresStorage(db.DB)
-		fetcher            = fetcher.New(articleRespository, sourceRespository, config.FetchInterval, filterKeywords)
+Run news-feed-bot <command> -h for the arguments of a command.`
 
-		summarizer = summary.NewOpenAISummarizer(
-			config.OpenAIKey,
-			// "",
-			config.OpenAIModel,
-			config.OpenAIPrompt,
-		)
-
-		notifier = notifier.New(
-			articleRespository,
-			summarizer,
-			botAPI,
-			config.NotificationInterval,
-			config.LookupTimeWindow,
-			config.TelegramChannelID,
-		)
-	)
-
-	newsBot := botkit.New(botAPI)
-	newsBot.RegisterCommand("start", bot.ViewCmdStart())
-
-	go func() {
-		fetcher.Start(context.TODO())
-	}()
-
-	go func() {
-		notifier.Start(context.TODO())
-	}()
+func main() {
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	if err := newsBot.Run(context.TODO()); err != nil {
-		log.Printf("[ERROR] failed to run botkit: %v", err)
+	switch command {
+	case "run":
+		os.Exit(runBot(args))
+	case "migrate":
+		os.Exit(runMigrate(args))
+	case "sources":
+		os.Exit(runSources(args))
+	case "fetch":
+		os.Exit(runFetch(args))
+	case "notify":
+		os.Exit(runNotify(args))
+	case "summarize":
+		os.Exit(runSummarize(args))
+	case "config":
+		os.Exit(runConfig(args))
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", command, usage)
+		os.Exit(2)
 	}
 }
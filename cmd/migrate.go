@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+)
+
+const migrateUsage = `Usage: news-feed-bot migrate <command> [flags]
+
+Commands:
+  up                 apply pending migrations
+  down [flags] [N]   revert the last N migrations, 1 by default
+  status             list migrations and when they were applied`
+
+// runMigrate runs the migrate subcommand and returns the exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	flags := newFlagSet("migrate", migrateUsage)
+	cfg, code, ok := parse(flags, args[1:], databaseSettings...)
+	if !ok {
+		return code
+	}
+	if flags.NArg() > 1 || flags.NArg() == 1 && args[0] != "down" {
+		flags.Usage()
+		return 2
+	}
+
+	database, err := db.Open(cfg.DatabaseDSN)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.Close()
+
+	migrator, err := database.Migrator()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load migrations:", err)
+		return 1
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if flags.NArg() == 1 {
+			if steps, err = strconv.Atoi(flags.Arg(0)); err != nil || steps < 1 {
+				flags.Usage()
+				return 2
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if !status.AppliedAt.IsZero() {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-19s  %d_%s\n", applied, status.Version, status.Name)
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	return 0
+}
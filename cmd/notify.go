@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/config"
+	"github.com/amir-amirov/go-news-feed-bot/internal/delivery"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const notifyUsage = `Usage: news-feed-bot notify [-once] [-dry-run] [-chat ID] [flags]
+
+Sends the due posts, digests and moderation previews every minute until SIGINT or SIGTERM,
+or once with -once. With -dry-run the next post of every channel, or of the -chat, is
+printed as it would be sent instead, whether it is due or not; nothing is stored.`
+
+// runNotify runs the notify subcommand and returns the exit code.
+func runNotify(args []string) int {
+	flags := newFlagSet("notify", notifyUsage)
+	once := flags.Bool("once", false, "send the due posts once and exit")
+	dryRun := flags.Bool("dry-run", false, "print the next posts instead of sending them")
+	chatID := flags.Int64("chat", 0, "with -dry-run, print only the next post of the chat")
+
+	if err := flags.Parse(args); err != nil {
+		return parseErrorCode(err)
+	}
+	if flags.NArg() > 0 || *chatID != 0 && !*dryRun {
+		flags.Usage()
+		return 2
+	}
+
+	// Dry runs don't talk to Telegram.
+	settings := config.Names()
+	if *dryRun {
+		settings = slices.DeleteFunc(settings, func(name string) bool { return name == "telegram_bot_token" })
+	}
+	cfg, err := flags.Load(settings...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	database, repositories, err := openStorage(ctx, cfg, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.Close()
+
+	summarizer, err := newSummarizer(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *dryRun {
+		n := newNotifier(cfg, repositories, summarizer, nil)
+
+		chats := []int64{*chatID}
+		if *chatID == 0 {
+			channels, err := repositories.Channels.Channels(ctx)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "failed to fetch channels:", err)
+				return 1
+			}
+
+			// The channel from the configuration is registered when the bot runs.
+			chats = []int64{cfg.TelegramChannelID}
+			for _, channel := range channels {
+				if channel.ID != cfg.TelegramChannelID {
+					chats = append(chats, channel.ID)
+				}
+			}
+		}
+
+		code := 0
+		for _, id := range chats {
+			item, ok, err := n.Preview(ctx, id, printSender{})
+			switch {
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "failed to preview the next post to %d: %v\n", id, err)
+				code = 1
+			case !ok:
+				fmt.Printf("== %d: nothing to post\n\n", id)
+			default:
+				fmt.Printf("== article %d from %s, score %.2f\n\n", item.Article.ID, item.Source.Name, item.Score)
+			}
+		}
+		return code
+	}
+
+	if err := registerChannel(ctx, cfg, repositories); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramBotToken)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create bot API:", err)
+		return 1
+	}
+
+	deliveryQueue := delivery.New(delivery.BotSender(botAPI), repositories.DeadLetters, delivery.DefaultLimits())
+	n := newNotifier(cfg, repositories, summarizer, deliveryQueue)
+	if *once {
+		n.RunOnce(ctx)
+	} else {
+		n.Start(ctx)
+	}
+	return 0
+}
+
+// printSender prints the messages of posts instead of sending them.
+type printSender struct{}
+
+func (printSender) Send(_ context.Context, req botkit.Request) (tgbotapi.Message, error) {
+	fmt.Printf("== %s to %s", req.Method, req.Params["chat_id"])
+	if parseMode := req.Params["parse_mode"]; parseMode != "" {
+		fmt.Printf(" (%s)", parseMode)
+	}
+	fmt.Println()
+
+	if photo := req.Params["photo"]; photo != "" {
+		fmt.Println("photo:", photo)
+	}
+	for _, key := range []string{"text", "caption"} {
+		if text := req.Params[key]; text != "" {
+			fmt.Println(text)
+		}
+	}
+
+	if raw := req.Params["reply_markup"]; raw != "" {
+		var keyboard tgbotapi.InlineKeyboardMarkup
+		if err := json.Unmarshal([]byte(raw), &keyboard); err == nil {
+			for _, row := range keyboard.InlineKeyboard {
+				for _, button := range row {
+					target := ""
+					if button.URL != nil {
+						target = *button.URL
+					}
+					fmt.Printf("[%s] %s\n", button.Text, target)
+				}
+			}
+		}
+	}
+	fmt.Println()
+
+	return tgbotapi.Message{Chat: &tgbotapi.Chat{ID: req.ChatID()}}, nil
+}
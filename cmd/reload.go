@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/config"
+	"github.com/amir-amirov/go-news-feed-bot/internal/fetcher"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
+)
+
+// reloader reloads the configuration on SIGHUP and applies the settings that can change
+// without a restart, see config.Reloadable.
+type reloader struct {
+	args       []string
+	started    *config.Config
+	fetcher    *fetcher.Fetcher
+	notifier   *notifier.Notifier
+	summarizer *summary.OpenAISummarizer
+}
+
+func (r *reloader) Run(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-hup:
+			r.reload()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *reloader) reload() {
+	next, err := config.Load(r.args)
+	if err != nil {
+		log.Printf("[ERROR] failed to reload the configuration, keeping the current one:\n%v", err)
+		return
+	}
+
+	if err := r.summarizer.Reconfigure(next.OpenAIModel, next.OpenAIPrompt); err != nil {
+		log.Printf("[ERROR] failed to reload the configuration, keeping the current one: %v", err)
+		return
+	}
+	r.fetcher.Reconfigure(next.FetchInterval, next.FilterKeywords)
+	r.notifier.Reconfigure(
+		rank.New(next.RankKeywords, next.RankHalfLife),
+		next.NotificationInterval,
+		next.LookupTimeWindow,
+		next.SummaryMaxSentences,
+		next.DeleteRetractedPosts,
+	)
+	log.Printf("Reloaded the configuration")
+
+	if names := r.started.Restart(next); len(names) > 0 {
+		log.Printf("[WARN] changes to %s are applied after a restart", strings.Join(names, ", "))
+	}
+}
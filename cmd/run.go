@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot/middleware"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/delivery"
+	"github.com/amir-amirov/go-news-feed-bot/internal/supervisor"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const runUsage = `Usage: news-feed-bot [run] [flags]
+
+Runs the fetcher, the notifier and the bot until SIGINT or SIGTERM.`
+
+// runBot runs the run subcommand and returns the exit code.
+func runBot(args []string) int {
+	cfg, code, ok := parse(newFlagSet("run", runUsage), args)
+	if !ok {
+		return code
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramBotToken)
+	if err != nil {
+		log.Printf("[ERROR] failed to create bot API: %v", err)
+		return 1
+	}
+	log.Printf("Bot authorized as %s", botAPI.Self.UserName)
+
+	database, repositories, err := openStorage(ctx, cfg, false)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		return 1
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			log.Printf("[ERROR] failed to close the database: %v", err)
+		}
+	}()
+
+	summarizer, err := newSummarizer(cfg)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		return 1
+	}
+
+	var (
+		fetcher = newFetcher(cfg, repositories)
+
+		deliveryQueue = delivery.New(
+			delivery.BotSender(botAPI),
+			repositories.DeadLetters,
+			delivery.DefaultLimits(),
+		)
+
+		notifier = newNotifier(cfg, repositories, summarizer, deliveryQueue)
+	)
+
+	if err := registerChannel(ctx, cfg, repositories); err != nil {
+		log.Printf("[ERROR] %v", err)
+		return 1
+	}
+
+	newsBot := botkit.New(botAPI)
+	newsBot.RegisterCommand("start", bot.ViewCmdStart())
+	newsBot.RegisterCommand("queue", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdQueue(notifier, cfg.TelegramChannelID)))
+	newsBot.RegisterCommand("upcoming", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdUpcoming(notifier, cfg.TelegramChannelID)))
+	newsBot.RegisterCommand("postnow", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdPostNow(notifier, cfg.TelegramChannelID)))
+	newsBot.RegisterCommand("schedule", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdSchedule(notifier)))
+	newsBot.RegisterCommand("skip", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdSkip(notifier)))
+	newsBot.RegisterCommand("editpost", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdEditPost(notifier)))
+	newsBot.RegisterCommand("deletepost", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdDeletePost(notifier)))
+	newsBot.RegisterCommand("opml", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdOPML(repositories.Sources)))
+	newsBot.RegisterCommand("sources", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdSources(repositories.Sources)))
+	newsBot.RegisterCommand("source", middleware.AdminsOnly(cfg.AdminIDs, bot.ViewCmdSource(repositories.Sources)))
+
+	onAddSource, onFeedButton := bot.ViewCmdAddSource(repositories.Sources)
+	newsBot.RegisterCommand("addsource", middleware.AdminsOnly(cfg.AdminIDs, onAddSource))
+	newsBot.RegisterCallback(bot.AddSourceCallbackPrefix, middleware.AdminsOnly(cfg.AdminIDs, onFeedButton))
+
+	onModerationButton, onEditedSummary := bot.ViewModeration(notifier)
+	newsBot.RegisterCallback(bot.ModerationCallbackPrefix, middleware.AdminsOnly(cfg.AdminIDs, onModerationButton))
+	newsBot.RegisterText(onEditedSummary)
+
+	onSearch, onSearchButton := bot.ViewCmdSearch(repositories.Articles)
+	newsBot.RegisterCommand("search", onSearch)
+	newsBot.RegisterCallback(bot.SearchCallbackPrefix, onSearchButton)
+	newsBot.RegisterInline(bot.ViewInlineShare(repositories.Articles))
+
+	workers := supervisor.New(cfg.ShutdownTimeout)
+	workers.Add("fetcher", func(ctx context.Context) error {
+		fetcher.Start(ctx)
+		return nil
+	})
+	workers.Add("notifier", func(ctx context.Context) error {
+		notifier.Start(ctx)
+		return nil
+	})
+	workers.Add("bot", newsBot.Run)
+	workers.Add("config", (&reloader{
+		args:       args,
+		started:    cfg,
+		fetcher:    fetcher,
+		notifier:   notifier,
+		summarizer: summarizer,
+	}).Run)
+
+	if err := workers.Run(ctx); err != nil {
+		log.Printf("[ERROR] failed to shut down cleanly: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/opml"
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const sourcesUsage = `Usage: news-feed-bot sources <command> [flags] [arguments]
+
+Commands:
+  list                  list the sources
+  add <name> <URL>      add the RSS, Atom or JSON feed as a source; the feed of a website
+                        page is discovered, if it has several they are listed to choose from
+  remove <ID>           remove the source and its articles
+  import [-dry-run] <file>
+                        add the sources of an OPML file or a CSV file with name,feed lines,
+                        - for stdin; feeds that are already sources are skipped. The folders
+                        of OPML feeds become tags. -dry-run prints the report without adding
+  export [file]         write the sources as an OPML file, to stdout by default`
+
+// runSources runs the sources subcommand and returns the exit code.
+func runSources(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, sourcesUsage)
+		return 2
+	}
+
+	flags := newFlagSet("sources", sourcesUsage)
+	dryRun := flags.Bool("dry-run", false, "with import, print what would be imported")
+	cfg, code, ok := parse(flags, args[1:], databaseSettings...)
+	if !ok {
+		return code
+	}
+
+	want := map[string][]int{"list": {0}, "add": {2}, "remove": {1}, "import": {1}, "export": {0, 1}}
+	if n, ok := want[args[0]]; !ok || !slices.Contains(n, flags.NArg()) || *dryRun && args[0] != "import" {
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	database, repositories, err := openStorage(ctx, cfg, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.Close()
+
+	switch args[0] {
+	case "list":
+		err = listSources(ctx, repositories.Sources)
+	case "add":
+		err = addDiscoveredSource(ctx, repositories.Sources, flags.Arg(0), flags.Arg(1))
+	case "remove":
+		err = removeSource(ctx, repositories.Sources, flags.Arg(0))
+	case "import":
+		err = importSources(ctx, repositories.Sources, flags.Arg(0), *dryRun)
+	case "export":
+		err = exportSources(ctx, repositories.Sources, flags.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func listSources(ctx context.Context, sources storage.SourceRepository) error {
+	list, err := sources.Sources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sources: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPRIORITY\tTAGS\tLANG\tSTATUS\tFEED")
+	for _, source := range list {
+		status := "active"
+		if source.Paused {
+			status = "paused"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%g\t%s\t%s\t%s\t%s\n",
+			source.ID, source.Name, source.Priority, strings.Join(source.Tags, ","), source.Language, status, source.FeedURL)
+	}
+	return w.Flush()
+}
+
+func addSource(ctx context.Context, sources storage.SourceRepository, name, feedURL string) error {
+	if err := validateSource(name, feedURL); err != nil {
+		return err
+	}
+
+	id, err := sources.Add(ctx, model.Source{Name: name, FeedURL: feedURL})
+	if err != nil {
+		return fmt.Errorf("failed to add source: %w", err)
+	}
+
+	fmt.Printf("added source %d: %s\n", id, name)
+	return nil
+}
+
+// addDiscoveredSource adds the feed at pageURL, or the feed of the website page.
+func addDiscoveredSource(ctx context.Context, sources storage.SourceRepository, name, pageURL string) error {
+	if err := validateSource(name, pageURL); err != nil {
+		return err
+	}
+
+	feeds, err := source.Discover(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+
+	if len(feeds) > 1 {
+		var list strings.Builder
+		for _, feed := range feeds {
+			fmt.Fprintf(&list, "\n  %s  %s (%d items)", feed.URL, feed.Title, feed.Items)
+		}
+		return fmt.Errorf("%s has %d feeds, add one of them:%s", pageURL, len(feeds), list.String())
+	}
+
+	if feeds[0].URL != pageURL {
+		fmt.Printf("found feed %s\n", feeds[0].URL)
+	}
+	return addSource(ctx, sources, name, feeds[0].URL)
+}
+
+func removeSource(ctx context.Context, sources storage.SourceRepository, idArg string) error {
+	id, err := strconv.ParseInt(idArg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid source ID %q", idArg)
+	}
+
+	source, err := sources.SourceByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("source %d not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch source %d: %w", id, err)
+	}
+
+	if err := sources.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove source %d: %w", id, err)
+	}
+
+	fmt.Printf("removed source %d: %s\n", id, source.Name)
+	return nil
+}
+
+// importSources adds the sources of the OPML or CSV file at path. CSV lines starting with #
+// are comments.
+func importSources(ctx context.Context, sources storage.SourceRepository, path string, dryRun bool) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	// OPML files are XML, CSV lines start with a name.
+	buffered := bufio.NewReader(r)
+	if isXML(buffered) {
+		report, err := opml.Import(ctx, sources, buffered, dryRun)
+		if report.Added != nil || report.Duplicates != nil || report.Invalid != nil {
+			fmt.Println(report)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", path, err)
+		}
+		return nil
+	}
+
+	return importCSV(ctx, sources, buffered, path, dryRun)
+}
+
+// isXML reports whether the first character after spaces and a byte order mark is "<".
+func isXML(r *bufio.Reader) bool {
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return false
+		}
+		if c != '\uFEFF' && !unicode.IsSpace(c) {
+			_ = r.UnreadRune()
+			return c == '<'
+		}
+	}
+}
+
+func importCSV(ctx context.Context, sources storage.SourceRepository, r io.Reader, path string, dryRun bool) error {
+	existing, err := sources.Sources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sources: %w", err)
+	}
+	feeds := make(map[string]bool, len(existing))
+	for _, src := range existing {
+		feeds[source.NormalizeURL(src.FeedURL)] = true
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var added, skipped int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		name, feedURL := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		key := source.NormalizeURL(feedURL)
+		if feeds[key] {
+			skipped++
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if dryRun {
+			if err := validateSource(name, feedURL); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			fmt.Printf("would add source: %s\n", name)
+		} else if err := addSource(ctx, sources, name, feedURL); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		feeds[key] = true
+		added++
+	}
+
+	if dryRun {
+		fmt.Printf("would import %d sources, %d already exist\n", added, skipped)
+		return nil
+	}
+	fmt.Printf("imported %d sources, %d already existed\n", added, skipped)
+	return nil
+}
+
+// exportSources writes the sources as an OPML file at path, or to stdout if it is empty or -.
+func exportSources(ctx context.Context, sources storage.SourceRepository, path string) error {
+	if path == "" || path == "-" {
+		return opml.Export(ctx, sources, os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := opml.Export(ctx, sources, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func validateSource(name, feedURL string) error {
+	if name == "" {
+		return errors.New("source name is empty")
+	}
+
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid feed URL %q", feedURL)
+	}
+	return nil
+}
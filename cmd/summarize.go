@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
+)
+
+const summarizeUsage = `Usage: news-feed-bot summarize [-lang code] [flags] <URL>
+
+Downloads the article page and prints its summary, e.g. to try a prompt:
+
+  news-feed-bot summarize -openai-prompt "$(cat prompt.tmpl)" https://go.dev/blog/go1.24
+
+The database is not used.`
+
+// runSummarize runs the summarize subcommand and returns the exit code.
+func runSummarize(args []string) int {
+	flags := newFlagSet("summarize", summarizeUsage)
+	language := flags.String("lang", "", "language of the summary, the language of the article by default")
+
+	cfg, code, ok := parse(flags, args, summarySettings...)
+	if !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	item, err := source.FetchPage(ctx, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	text, _, err := notifier.ExtractText(model.Article{Title: item.Title, Link: item.Link, Summary: item.Summary})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *language == "" {
+		*language = lang.Detect(text)
+	}
+	if *language == "" {
+		*language = lang.English
+	}
+
+	sourceName := item.SourceName
+	if u, err := url.Parse(item.Link); sourceName == "" && err == nil {
+		sourceName = u.Host
+	}
+
+	summarizer, err := newSummarizer(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := summarizer.Summarizer(text, summary.PromptData{
+		Title:        item.Title,
+		Source:       sourceName,
+		Language:     *language,
+		MaxSentences: cfg.SummaryMaxSentences,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to summarize:", err)
+		return 1
+	}
+
+	fmt.Printf("%s\n%s, %s, %d words\n\n%s\n", item.Title, sourceName, *language, len(strings.Fields(text)), result)
+	return 0
+}
@@ -3,22 +3,32 @@ module github.com/amir-amirov/go-news-feed-bot
 go 1.24.1
 
 require (
-	github.com/SlyMarbo/rss v1.0.5 // indirect
+	github.com/SlyMarbo/rss v1.0.5
+	github.com/go-shiori/go-readability v0.0.0-20250217085726-9f5bf5ca7612
+	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
+	github.com/hashicorp/hcl v1.0.0
+	github.com/lib/pq v1.10.9
+	github.com/sashabaranov/go-openai v1.40.1
+	go.tomakado.io/containers v0.0.0-20240306123358-5f64d4e0f4f3
+	golang.org/x/net v0.35.0
+	modernc.org/sqlite v1.40.1
+)
+
+require (
 	github.com/andybalholm/cascadia v1.3.3 // indirect
 	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de // indirect
 	github.com/axgle/mahonia v0.0.0-20180208002826-3358181d7394 // indirect
-	github.com/cristalhq/aconfig v0.18.7 // indirect
-	github.com/cristalhq/aconfig/aconfighcl v0.17.1 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/go-shiori/dom v0.0.0-20230515143342-73569d674e1c // indirect
-	github.com/go-shiori/go-readability v0.0.0-20250217085726-9f5bf5ca7612 // indirect
-	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1 // indirect
 	github.com/gogs/chardet v0.0.0-20211120154057-b7413eaefb8f // indirect
-	github.com/hashicorp/hcl v1.0.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
-	github.com/mitchellh/mapstructure v1.5.0 // indirect
-	github.com/sashabaranov/go-openai v1.40.1 // indirect
-	go.tomakado.io/containers v0.0.0-20240306123358-5f64d4e0f4f3 // indirect
-	golang.org/x/net v0.35.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
+	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
+	modernc.org/libc v1.66.10 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
 )
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AdminsOnly runs the view only for updates from the given users, others are ignored.
+func AdminsOnly(adminIDs []int64, next botkit.ViewFunc) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		user := update.SentFrom()
+		if user == nil || !slices.Contains(adminIDs, user.ID) {
+			if user != nil {
+				log.Printf("[WARN] user %d is not an admin, ignoring the update", user.ID)
+			}
+			return nil
+		}
+
+		return next(ctx, bot, update)
+	}
+}
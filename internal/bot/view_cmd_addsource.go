@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AddSourceCallbackPrefix is the callback data prefix of the buttons choosing a feed.
+const AddSourceCallbackPrefix = "addsource"
+
+type SourceAdder interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	Add(ctx context.Context, source model.Source) (int64, error)
+}
+
+// feedChoice is a page with several feeds the admin is asked to choose from.
+type feedChoice struct {
+	message *tgbotapi.Message
+	name    string
+	feeds   []source.DiscoveredFeed
+}
+
+// ViewCmdAddSource returns the views adding the feed of a page, "/addsource https://go.dev/blog Go Blog",
+// and handling the buttons offered when the page has several feeds. The name defaults to the
+// title of the feed.
+func ViewCmdAddSource(sources SourceAdder) (onCommand, onButton botkit.ViewFunc) {
+	var (
+		mu      sync.Mutex
+		choices = make(map[int64]feedChoice) // by user ID
+	)
+
+	onCommand = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		pageURL, name, _ := strings.Cut(strings.TrimSpace(update.Message.CommandArguments()), " ")
+		if pageURL == "" {
+			return reply(bot, update, "Usage: /addsource <feed or website URL> [name]")
+		}
+
+		feeds, err := source.Discover(ctx, pageURL)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find a feed: %v", err))
+		}
+
+		if len(feeds) == 1 {
+			text, err := addFeed(ctx, sources, strings.TrimSpace(name), feeds[0])
+			if err != nil {
+				return err
+			}
+			return reply(bot, update, text)
+		}
+
+		var (
+			text     strings.Builder
+			keyboard [][]tgbotapi.InlineKeyboardButton
+		)
+		fmt.Fprintf(&text, "%s has %d feeds, which one should be added?", pageURL, len(feeds))
+		for i, feed := range feeds {
+			fmt.Fprintf(&text, "\n%d. %s (%d items) %s", i+1, feedTitle(feed), feed.Items, feed.URL)
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%d. %s", i+1, feedTitle(feed)),
+				fmt.Sprintf("%s:%d", AddSourceCallbackPrefix, i),
+			)))
+		}
+
+		msg := tgbotapi.NewMessage(update.FromChat().ID, text.String())
+		msg.DisableWebPagePreview = true
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+
+		sent, err := bot.Send(msg)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		choices[update.SentFrom().ID] = feedChoice{message: &sent, name: strings.TrimSpace(name), feeds: feeds}
+		mu.Unlock()
+		return nil
+	}
+
+	onButton = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := update.CallbackQuery
+
+		_, arg, _ := strings.Cut(query.Data, ":")
+		i, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid feed index in callback data %q: %w", query.Data, err)
+		}
+
+		mu.Lock()
+		choice, ok := choices[query.From.ID]
+		ok = ok && query.Message != nil && choice.message.MessageID == query.Message.MessageID &&
+			choice.message.Chat.ID == query.Message.Chat.ID && i >= 0 && i < len(choice.feeds)
+		if ok {
+			delete(choices, query.From.ID)
+		}
+		mu.Unlock()
+
+		if !ok {
+			return resolve(bot, query.Message, "The choice is no longer available, send /addsource again.")
+		}
+
+		text, err := addFeed(ctx, sources, choice.name, choice.feeds[i])
+		if err != nil {
+			return err
+		}
+		return resolve(bot, query.Message, text)
+	}
+
+	return onCommand, onButton
+}
+
+// addFeed adds the feed as a source unless it already is one, and returns the reply.
+func addFeed(ctx context.Context, sources SourceAdder, name string, feed source.DiscoveredFeed) (string, error) {
+	existing, err := sources.Sources(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, src := range existing {
+		if source.NormalizeURL(src.FeedURL) == source.NormalizeURL(feed.URL) {
+			return fmt.Sprintf("%s is already source %d: %s", feed.URL, src.ID, src.Name), nil
+		}
+	}
+
+	if name == "" {
+		name = feedTitle(feed)
+	}
+
+	id, err := sources.Add(ctx, model.Source{Name: name, FeedURL: feed.URL})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Source %d is added: %s, %s", id, name, feed.URL), nil
+}
+
+// feedTitle returns the title of the feed, or the host of its URL if it has none.
+func feedTitle(feed source.DiscoveredFeed) string {
+	if feed.Title != "" {
+		return feed.Title
+	}
+	if u, err := url.Parse(feed.URL); err == nil {
+		return u.Host
+	}
+	return feed.URL
+}
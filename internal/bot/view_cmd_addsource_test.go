@@ -0,0 +1,64 @@
+package bot_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot/middleware"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestViewCmdAddSource(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head>
+				<link rel="alternate" type="application/atom+xml" href="/atom.xml">
+				<link rel="alternate" type="application/rss+xml" href="/comments.xml">
+			</head></html>`))
+		case "/atom.xml":
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Gopher blog</title></feed>`))
+		case "/comments.xml":
+			w.Write([]byte(`<rss version="2.0"><channel><title>Gopher comments</title></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	server := bottest.NewServer(t)
+	repositories := storage.NewMemory()
+
+	onCommand, onButton := bot.ViewCmdAddSource(repositories.Sources)
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterCommand("addsource", middleware.AdminsOnly([]int64{adminID}, onCommand))
+	newsBot.RegisterCallback(bot.AddSourceCallbackPrefix, middleware.AdminsOnly([]int64{adminID}, onButton))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+	admin := conversation.User(adminID)
+
+	admin.Sends("/addsource " + site.URL)
+	conversation.ExpectReply("has 2 feeds, which one should be added?\n1. Gopher blog (0 items) " + site.URL + "/atom.xml")
+
+	admin.Presses("addsource:0")
+	conversation.ExpectEdit("")
+	conversation.ExpectReply("Source 1 is added: Gopher blog, " + site.URL + "/atom.xml")
+	conversation.ExpectAnswer("", false)
+
+	// A feed URL is added right away, unless it is already a source.
+	admin.Sends("/addsource " + site.URL + "/comments.xml Comments")
+	conversation.ExpectReply("Source 2 is added: Comments")
+	admin.Sends("/addsource " + site.URL + "/atom.xml")
+	conversation.ExpectReply("is already source 1: Gopher blog")
+
+	sources, err := repositories.Sources.Sources(context.Background())
+	if err != nil || len(sources) != 2 {
+		t.Fatalf("got sources %+v, %v", sources, err)
+	}
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type PostDeleter interface {
+	ArticleProvider
+	DeletePosts(ctx context.Context, articleID int64) (int, error)
+}
+
+// ViewCmdDeletePost deletes the published posts of an article, it is not posted again.
+func ViewCmdDeletePost(deleter PostDeleter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		ref := strings.TrimSpace(update.Message.CommandArguments())
+		if ref == "" {
+			return reply(bot, update, "Usage: /deletepost <article ID or URL>")
+		}
+
+		article, err := deleter.Article(ctx, ref)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find the article: %v", err))
+		}
+
+		deleted, err := deleter.DeletePosts(ctx, article.ID)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Deleted %d posts of article %d, then failed: %v", deleted, article.ID, err))
+		}
+
+		return reply(bot, update, fmt.Sprintf("Deleted %d posts of article %d.", deleted, article.ID))
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type PostEditor interface {
+	ArticleProvider
+	EditTitle(ctx context.Context, articleID int64, title string) error
+	SetSummary(ctx context.Context, articleID int64, language, summary string) error
+	EditPosts(ctx context.Context, articleID int64) (int, error)
+}
+
+const editPostUsage = `Usage:
+/editpost <article ID or URL> — render the posts again with the current template
+/editpost <article ID or URL> title <new title>
+/editpost <article ID or URL> summary <language code> <new summary>`
+
+// ViewCmdEditPost changes the title or a summary of an article and edits its published posts.
+func ViewCmdEditPost(editor PostEditor) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		ref, rest := cutWord(update.Message.CommandArguments())
+		if ref == "" {
+			return reply(bot, update, editPostUsage)
+		}
+
+		article, err := editor.Article(ctx, ref)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find the article: %v", err))
+		}
+
+		field, value := cutWord(rest)
+		switch field {
+		case "":
+		case "title":
+			if value == "" {
+				return reply(bot, update, editPostUsage)
+			}
+			if err := editor.EditTitle(ctx, article.ID, value); err != nil {
+				return err
+			}
+		case "summary":
+			language, summary := cutWord(value)
+			if language == "" || summary == "" {
+				return reply(bot, update, editPostUsage)
+			}
+			if err := editor.SetSummary(ctx, article.ID, language, summary); err != nil {
+				return err
+			}
+		default:
+			return reply(bot, update, editPostUsage)
+		}
+
+		edited, err := editor.EditPosts(ctx, article.ID)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Edited %d posts of article %d, then failed: %v", edited, article.ID, err))
+		}
+
+		return reply(bot, update, fmt.Sprintf("Edited %d posts of article %d.", edited, article.ID))
+	}
+}
+
+// cutWord returns the first word of s and the rest of s without the whitespace around them.
+func cutWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/opml"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const opmlUsage = "Usage: /opml sends the sources as an OPML file. Upload an .opml file with the caption " +
+	"\"/opml\" to import its feeds, or \"/opml dry-run\" to see what would be imported."
+
+// ViewCmdOPML replies to "/opml" with the sources as an OPML file. An uploaded OPML document
+// with the caption "/opml" is imported first, with "/opml dry-run" only the report is sent.
+func ViewCmdOPML(sources opml.SourceStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args := strings.Fields(update.Message.CommandArguments())
+		dryRun := len(args) == 1 && args[0] == "dry-run"
+		if len(args) > 1 || len(args) == 1 && !dryRun || dryRun && update.Message.Document == nil {
+			return reply(bot, update, opmlUsage)
+		}
+
+		if document := update.Message.Document; document != nil {
+			content, err := botkit.DownloadFile(ctx, bot, document.FileID)
+			if err != nil {
+				return err
+			}
+
+			report, err := opml.Import(ctx, sources, bytes.NewReader(content), dryRun)
+			if err != nil {
+				return reply(bot, update, fmt.Sprintf("Failed to import %s: %v", document.FileName, err))
+			}
+			// Reports of large imports don't fit in a message.
+			if err := replyLines(bot, update, strings.Split(report.String(), "\n")); err != nil {
+				return err
+			}
+			if dryRun {
+				return nil
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := opml.Export(ctx, sources, &buf); err != nil {
+			return err
+		}
+
+		_, err := bot.Send(tgbotapi.NewDocument(update.FromChat().ID, tgbotapi.FileBytes{
+			Name:  "sources.opml",
+			Bytes: buf.Bytes(),
+		}))
+		return err
+	}
+}
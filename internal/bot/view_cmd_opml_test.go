@@ -0,0 +1,108 @@
+package bot_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot/middleware"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const subscriptions = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <body>
+    <outline text="Go">
+      <outline text="The Go Blog" xmlUrl="https://go.dev/blog/feed.atom"/>
+    </outline>
+    <outline text="Example" xmlUrl="http://www.example.com/feed/"/>
+  </body>
+</opml>`
+
+func TestViewCmdOPML(t *testing.T) {
+	server := bottest.NewServer(t)
+
+	fileEndpoint := botkit.FileEndpoint
+	botkit.FileEndpoint = server.FileEndpoint()
+	t.Cleanup(func() { botkit.FileEndpoint = fileEndpoint })
+
+	repositories := storage.NewMemory()
+	if _, err := repositories.Sources.Add(context.Background(), model.Source{Name: "Example", FeedURL: "https://example.com/feed"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterCommand("opml", middleware.AdminsOnly([]int64{adminID}, bot.ViewCmdOPML(repositories.Sources)))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+
+	conversation.User(adminID).Uploads("subscriptions.opml", []byte(subscriptions), "/opml dry-run")
+	conversation.ExpectReply("Would import 1 sources, 1 duplicates, 0 invalid.\n+ The Go Blog https://go.dev/blog/feed.atom [Go]")
+	if sources, _ := repositories.Sources.Sources(context.Background()); len(sources) != 1 {
+		t.Fatalf("dry run added %d sources", len(sources)-1)
+	}
+
+	conversation.User(adminID).Uploads("subscriptions.opml", []byte(subscriptions), "/opml")
+	conversation.ExpectReply("Imported 1 sources, 1 duplicates, 0 invalid.")
+	export := conversation.ExpectReply("")
+	if export.Method != "sendDocument" || export.Params["document"] != "sources.opml" {
+		t.Fatalf("expected sources.opml, got %s %q", export.Method, export.Params["document"])
+	}
+	if !strings.Contains(string(export.File), `<outline text="go" title="go">`) ||
+		!strings.Contains(string(export.File), `xmlUrl="https://go.dev/blog/feed.atom"`) {
+		t.Errorf("unexpected export:\n%s", export.File)
+	}
+
+	conversation.Script(`
+		user 42 sends "/opml"
+		expect reply
+		user 42 sends "/opml dry-run"
+		expect reply containing "Usage: /opml"
+	`)
+}
+
+func TestViewCmdOPML_LargeReport(t *testing.T) {
+	server := bottest.NewServer(t)
+
+	fileEndpoint := botkit.FileEndpoint
+	botkit.FileEndpoint = server.FileEndpoint()
+	t.Cleanup(func() { botkit.FileEndpoint = fileEndpoint })
+
+	// Tagged feeds are listed as "[go, news]", which isn't a link in a plain text report.
+	var outlines strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&outlines, `<outline text="Gopher weekly %d" xmlUrl="https://news%d.example.com/feed.xml"/>`, i+1, i+1)
+	}
+	subscriptions := `<opml version="2.0"><body><outline text="go"><outline text="news">` + outlines.String() + `</outline></outline></body></opml>`
+
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterCommand("opml", middleware.AdminsOnly([]int64{adminID}, bot.ViewCmdOPML(storage.NewMemory().Sources)))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+	conversation.User(adminID).Uploads("subscriptions.opml", []byte(subscriptions), "/opml dry-run")
+
+	var report []string
+	for !slices.ContainsFunc(report, func(line string) bool { return strings.Contains(line, "Gopher weekly 200 ") }) {
+		msg := conversation.ExpectReply("")
+		if len(msg.Text) > markup.MaxMessageLength {
+			t.Fatalf("got a reply of %d characters", len(msg.Text))
+		}
+		report = append(report, strings.Split(msg.Text, "\n")...)
+	}
+
+	if len(report) != 201 || report[0] != "Would import 200 sources, 0 duplicates, 0 invalid." {
+		t.Fatalf("got %d lines of the report starting with %q", len(report), report[0])
+	}
+	if want := "+ Gopher weekly 1 https://news1.example.com/feed.xml [go, news]"; report[1] != want {
+		t.Errorf("got %q, want %q", report[1], want)
+	}
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ArticleProvider finds an article by its ID or link, fetching unknown links.
+type ArticleProvider interface {
+	Article(ctx context.Context, ref string) (model.Article, error)
+}
+
+type ArticlePoster interface {
+	ArticleProvider
+	PostNow(ctx context.Context, chatID int64, article model.Article) error
+}
+
+// ViewCmdPostNow posts an article to the chat given as the second argument or to the default chat
+// right away, "/postnow 123" or "/postnow https://go.dev/blog/go1.24 -100123".
+func ViewCmdPostNow(poster ArticlePoster, defaultChatID int64) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args := strings.Fields(update.Message.CommandArguments())
+		if len(args) == 0 || len(args) > 2 {
+			return reply(bot, update, "Usage: /postnow <article ID or URL> [chat ID]")
+		}
+
+		chatID := defaultChatID
+		if len(args) == 2 {
+			id, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return reply(bot, update, "Usage: /postnow <article ID or URL> [chat ID]")
+			}
+			chatID = id
+		}
+
+		article, err := poster.Article(ctx, args[0])
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find the article: %v", err))
+		}
+
+		err = poster.PostNow(ctx, chatID, article)
+		if errors.Is(err, notifier.ErrAlreadyPosted) {
+			return reply(bot, update, fmt.Sprintf("Article %d is already posted to %d.", article.ID, chatID))
+		}
+		if err != nil {
+			return err
+		}
+
+		return reply(bot, update, fmt.Sprintf("Article %d is posted to %d: %s", article.ID, chatID, article.Title))
+	}
+}
+
+func reply(bot *tgbotapi.BotAPI, update tgbotapi.Update, text string) error {
+	msg := tgbotapi.NewMessage(update.FromChat().ID, text)
+	msg.DisableWebPagePreview = true
+
+	_, err := bot.Send(msg)
+	return err
+}
+
+// replyLines replies with the plain text lines, split into as many messages as they need.
+func replyLines(bot *tgbotapi.BotAPI, update tgbotapi.Update, lines []string) error {
+	var text strings.Builder
+	for _, line := range lines {
+		if text.Len() > 0 && text.Len()+len(line)+1 > markup.MaxMessageLength {
+			if err := reply(bot, update, text.String()); err != nil {
+				return err
+			}
+			text.Reset()
+		}
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString(line)
+	}
+	return reply(bot, update, text.String())
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type QueueProvider interface {
+	Queue(ctx context.Context, chatID int64) ([]rank.Ranked, error)
+}
+
+// queueSize is how many of the next articles /queue shows.
+const queueSize = 10
+
+// ViewCmdQueue shows the queue of the chat given as the argument, "/queue -100123", or of the default chat.
+func ViewCmdQueue(provider QueueProvider, defaultChatID int64) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		chatID := defaultChatID
+		if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				_, err := bot.Send(tgbotapi.NewMessage(update.FromChat().ID, "Usage: /queue [chat ID]"))
+				return err
+			}
+			chatID = id
+		}
+
+		queue, err := provider.Queue(ctx, chatID)
+		if err != nil {
+			return err
+		}
+
+		text := "The queue is empty."
+		if len(queue) > 0 {
+			text = formatQueue(queue)
+		}
+
+		msg := tgbotapi.NewMessage(update.FromChat().ID, text)
+		msg.DisableWebPagePreview = true
+
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+func formatQueue(queue []rank.Ranked) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Next %d of %d articles:\n", min(len(queue), queueSize), len(queue))
+
+	for i, item := range queue[:min(len(queue), queueSize)] {
+		source := item.Source.Name
+		if source == "" {
+			source = fmt.Sprintf("source %d", item.Article.SourceID)
+		}
+
+		fmt.Fprintf(&sb, "\n%d. #%d %s (%s) — %.3f\n", i+1, item.Article.ID, item.Article.Title, source, item.Score)
+
+		var factors []string
+		for _, f := range item.Factors {
+			factors = append(factors, f.String())
+		}
+		sb.WriteString(strings.Join(factors, " · "))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
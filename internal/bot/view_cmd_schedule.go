@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ArticleScheduler interface {
+	ArticleProvider
+	Schedule(ctx context.Context, articleID int64, at time.Time) error
+}
+
+const scheduleUsage = `Usage: /schedule <article ID or URL> <time>
+The time is UTC, e.g. "15:30", "2026-10-20 09:00" or "+2h".`
+
+// ViewCmdSchedule schedules an article to be posted to every channel at the given time.
+func ViewCmdSchedule(scheduler ArticleScheduler) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		ref, when, ok := strings.Cut(strings.TrimSpace(update.Message.CommandArguments()), " ")
+		if !ok {
+			return reply(bot, update, scheduleUsage)
+		}
+
+		at, err := parseTime(strings.TrimSpace(when), time.Now())
+		if err != nil {
+			return reply(bot, update, scheduleUsage)
+		}
+
+		article, err := scheduler.Article(ctx, ref)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find the article: %v", err))
+		}
+
+		if err := scheduler.Schedule(ctx, article.ID, at); err != nil {
+			return err
+		}
+
+		return reply(bot, update, fmt.Sprintf("Article %d is scheduled for %s: %s", article.ID, at.Format(timeLayout), article.Title))
+	}
+}
+
+const timeLayout = "2006-01-02 15:04 MST"
+
+// parseTime parses a UTC time as "15:04" (the next such time), "2006-01-02 15:04" or a duration from now, "+2h".
+func parseTime(s string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+
+	if d, ok := strings.CutPrefix(s, "+"); ok {
+		duration, err := time.ParseDuration(d)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(duration).Truncate(time.Minute), nil
+	}
+
+	if t, err := time.Parse("2006-01-02 15:04", s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if at.Before(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
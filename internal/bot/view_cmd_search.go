@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SearchCallbackPrefix is the callback data prefix of the buttons paging search results.
+const SearchCallbackPrefix = "search"
+
+const searchPageSize = 5
+
+type ArticleSearcher interface {
+	Search(ctx context.Context, query string, offset, limit uint64) ([]model.SearchResult, int, error)
+}
+
+// ViewCmdSearch returns the views searching the stored articles, "/search golang generics",
+// and paging the results with the buttons under them.
+func ViewCmdSearch(searcher ArticleSearcher) (onCommand, onButton botkit.ViewFunc) {
+	onCommand = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := strings.Join(strings.Fields(update.Message.CommandArguments()), " ")
+		if query == "" {
+			return reply(bot, update, `Usage: /search <words>, e.g. /search golang generics, /search "type parameters", /search maps -rust`)
+		}
+
+		text, keyboard, err := searchPage(ctx, searcher, query, 0)
+		if err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.FromChat().ID, text)
+		msg.ParseMode = markup.ModeHTML
+		msg.DisableWebPagePreview = true
+		if keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+
+		_, err = bot.Send(msg)
+		return err
+	}
+
+	onButton = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := update.CallbackQuery
+		if query.Message == nil {
+			return nil
+		}
+
+		_, arg, _ := strings.Cut(query.Data, ":")
+		offset, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset in callback data %q: %w", query.Data, err)
+		}
+
+		// The query is read back from the first line of the results rather than kept in memory.
+		searchQuery, ok := queryOfResults(query.Message.Text)
+		if !ok {
+			return fmt.Errorf("no search query in message %d", query.Message.MessageID)
+		}
+
+		text, keyboard, err := searchPage(ctx, searcher, searchQuery, offset)
+		if err != nil {
+			return err
+		}
+
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+		edit.ParseMode = markup.ModeHTML
+		edit.DisableWebPagePreview = true
+		edit.ReplyMarkup = keyboard
+
+		_, err = bot.Send(edit)
+		return err
+	}
+
+	return onCommand, onButton
+}
+
+// searchPage returns the results of the query starting at the offset and the buttons paging
+// them, nil if all results fit on one page.
+func searchPage(ctx context.Context, searcher ArticleSearcher, query string, offset uint64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	results, total, err := searcher.Search(ctx, query, offset, searchPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := markup.NewBuilder(markup.ModeHTML)
+	if len(results) == 0 {
+		b.Text(fmt.Sprintf("Nothing found for %q.", query))
+		return b.String(), nil, nil
+	}
+
+	b.Text(fmt.Sprintf("Results %d–%d of %d for %q:", offset+1, offset+uint64(len(results)), total, query))
+	for i, result := range results {
+		article := result.Article
+
+		b.Line().Line().
+			Text(fmt.Sprintf("%d. ", offset+uint64(i)+1)).
+			Append(markup.Link(article.Link, markup.Text(article.Title))).
+			Text(" · " + article.PublishedAt.UTC().Format("2 Jan 2006"))
+		if result.Headline != "" {
+			b.Line().Append(highlight(result.Headline)...)
+		}
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	if offset > 0 {
+		previous := offset - min(offset, searchPageSize)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("« Previous", fmt.Sprintf("%s:%d", SearchCallbackPrefix, previous)))
+	}
+	if next := offset + uint64(len(results)); next < uint64(total) {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("Next »", fmt.Sprintf("%s:%d", SearchCallbackPrefix, next)))
+	}
+	if len(row) == 0 {
+		return b.String(), nil, nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return b.String(), &keyboard, nil
+}
+
+// queryOfResults returns the query from the first line of the results, `Results 1–5 of 12 for "go":`.
+func queryOfResults(text string) (string, bool) {
+	firstLine, _, _ := strings.Cut(text, "\n")
+	_, quoted, ok := strings.Cut(firstLine, " for ")
+	if !ok {
+		return "", false
+	}
+
+	query, err := strconv.Unquote(strings.TrimSuffix(html.UnescapeString(quoted), ":"))
+	return query, err == nil
+}
+
+// highlight returns the headline with the matches of the query in bold.
+func highlight(headline string) []markup.Node {
+	var nodes []markup.Node
+	for {
+		before, rest, ok := strings.Cut(headline, model.HighlightStart)
+		nodes = append(nodes, markup.Text(before))
+		if !ok {
+			return nodes
+		}
+
+		match, after, _ := strings.Cut(rest, model.HighlightEnd)
+		nodes = append(nodes, markup.Bold(markup.Text(match)))
+		headline = after
+	}
+}
+
+// plainHeadline returns the headline without the markers of the matches.
+func plainHeadline(headline string) string {
+	return strings.NewReplacer(model.HighlightStart, "", model.HighlightEnd, "").Replace(headline)
+}
@@ -0,0 +1,60 @@
+package bot_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestViewCmdSearch(t *testing.T) {
+	server := bottest.NewServer(t)
+	repositories := storage.NewMemory()
+
+	ctx := context.Background()
+	published := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 7 {
+		article := model.Article{
+			SourceID:    1,
+			Title:       fmt.Sprintf("Gopher news %d", i+1),
+			Link:        fmt.Sprintf("https://go.dev/news/%d", i+1),
+			Summary:     "What the gopher <b>did</b> this week.",
+			PublishedAt: published.Add(-time.Duration(i) * time.Hour),
+		}
+		if err := repositories.Articles.Store(ctx, article); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+
+	onCommand, onButton := bot.ViewCmdSearch(repositories.Articles)
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterCommand("search", onCommand)
+	newsBot.RegisterCallback(bot.SearchCallbackPrefix, onButton)
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+	user := conversation.User(7)
+
+	user.Sends("/search")
+	conversation.ExpectReply("Usage: /search")
+	user.Sends("/search rust")
+	conversation.ExpectReply(`Nothing found for &quot;rust&quot;.`)
+
+	user.Sends("/search  gopher ")
+	page := conversation.ExpectReply(`Results 1–5 of 7 for &quot;gopher&quot;:`)
+	if !strings.Contains(page.Text, `1. <a href="https://go.dev/news/1">Gopher news 1</a> · 1 Oct 2026`) ||
+		!strings.Contains(page.Text, "What the <b>gopher</b> did this week.") {
+		t.Errorf("unexpected results %q", page.Text)
+	}
+
+	user.Presses("search:5")
+	conversation.ExpectEdit(`Results 6–7 of 7 for &quot;gopher&quot;:`)
+	conversation.ExpectAnswer("", false)
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ArticleSkipper interface {
+	ArticleProvider
+	Skip(ctx context.Context, articleID int64) error
+}
+
+// ViewCmdSkip keeps an article from being posted, "/skip 123" or "/skip <URL>". Unknown links are stored
+// as skipped, so the article is not posted when it is fetched.
+func ViewCmdSkip(skipper ArticleSkipper) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		ref := strings.TrimSpace(update.Message.CommandArguments())
+		if ref == "" {
+			return reply(bot, update, "Usage: /skip <article ID or URL>")
+		}
+
+		article, err := skipper.Article(ctx, ref)
+		if err != nil {
+			return reply(bot, update, fmt.Sprintf("Failed to find the article: %v", err))
+		}
+
+		if err := skipper.Skip(ctx, article.ID); err != nil {
+			return err
+		}
+
+		return reply(bot, update, fmt.Sprintf("Article %d is skipped: %s", article.ID, article.Title))
+	}
+}
@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const sourceUsage = `Usage: /source <ID> [field value]
+
+Fields:
+name <name>
+tags <tag, tag...> (empty to clear)
+language <code> (empty to detect it for every article)
+priority <weight>
+homepage <URL>
+pause, resume`
+
+type SourceLister interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+}
+
+type SourceEditor interface {
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	Update(ctx context.Context, source model.Source) error
+}
+
+// ViewCmdSources lists the sources with their tags, paused ones are marked.
+func ViewCmdSources(sources SourceLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		list, err := sources.Sources(ctx)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return reply(bot, update, "There are no sources, add one with /addsource.")
+		}
+
+		lines := make([]string, 0, len(list))
+		for _, source := range list {
+			line := fmt.Sprintf("%d. %s", source.ID, source.Name)
+			if source.Paused {
+				line += " (paused)"
+			}
+			if len(source.Tags) > 0 {
+				line += " [" + strings.Join(source.Tags, ", ") + "]"
+			}
+			lines = append(lines, line)
+		}
+		return replyLines(bot, update, lines)
+	}
+}
+
+// ViewCmdSource shows a source, "/source 3", or changes one of the fields admins edit, e.g.
+// "/source 3 tags go, releases" or "/source 3 pause".
+func ViewCmdSource(sources SourceEditor) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args := strings.Fields(update.Message.CommandArguments())
+		if len(args) == 0 {
+			return reply(bot, update, sourceUsage)
+		}
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return reply(bot, update, sourceUsage)
+		}
+
+		source, err := sources.SourceByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return reply(bot, update, fmt.Sprintf("Source %d doesn't exist.", id))
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 {
+			return reply(bot, update, formatSource(*source))
+		}
+
+		if problem := editSource(source, strings.ToLower(args[1]), strings.Join(args[2:], " ")); problem != "" {
+			return reply(bot, update, problem+"\n\n"+sourceUsage)
+		}
+
+		if err := sources.Update(ctx, *source); err != nil {
+			return err
+		}
+
+		// Tags are normalized by the storage.
+		updated, err := sources.SourceByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return reply(bot, update, "Source is updated.\n\n"+formatSource(*updated))
+	}
+}
+
+// editSource sets the field of the source to the value, it returns what is wrong with the
+// field or the value, empty if nothing.
+func editSource(source *model.Source, field, value string) string {
+	switch field {
+	case "name":
+		if value == "" {
+			return "The name can't be empty."
+		}
+		source.Name = value
+	case "tags":
+		source.Tags = strings.Split(value, ",")
+	case "language", "lang":
+		if len(value) > 8 {
+			return fmt.Sprintf("Invalid language code %q.", value)
+		}
+		source.Language = strings.ToLower(value)
+	case "priority":
+		priority, err := strconv.ParseFloat(value, 64)
+		if err != nil || priority <= 0 {
+			return fmt.Sprintf("Invalid priority %q, it must be a positive number.", value)
+		}
+		source.Priority = priority
+	case "homepage":
+		if u, err := url.Parse(value); value != "" && (err != nil || u.Host == "") {
+			return fmt.Sprintf("Invalid URL %q.", value)
+		}
+		source.HomepageURL = value
+	case "pause":
+		source.Paused = true
+	case "resume":
+		source.Paused = false
+	default:
+		return fmt.Sprintf("Unknown field %q.", field)
+	}
+	return ""
+}
+
+func formatSource(source model.Source) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d. %s\n", source.ID, source.Name)
+	fmt.Fprintf(&sb, "Feed: %s\n", source.FeedURL)
+
+	status := "active"
+	if source.Paused {
+		status = "paused"
+	}
+	fmt.Fprintf(&sb, "Status: %s\n", status)
+
+	tags := "none"
+	if len(source.Tags) > 0 {
+		tags = strings.Join(source.Tags, ", ")
+	}
+	fmt.Fprintf(&sb, "Tags: %s\n", tags)
+
+	language := "detected"
+	if source.Language != "" {
+		language = source.Language
+	}
+	fmt.Fprintf(&sb, "Language: %s\n", language)
+	fmt.Fprintf(&sb, "Priority: %g\n", source.Priority)
+
+	if source.HomepageURL != "" {
+		fmt.Fprintf(&sb, "Homepage: %s\n", source.HomepageURL)
+	}
+	if source.Description != "" {
+		fmt.Fprintf(&sb, "Description: %s\n", source.Description)
+	}
+	if source.IconURL != "" {
+		fmt.Fprintf(&sb, "Icon: %s\n", source.IconURL)
+	}
+
+	fmt.Fprintf(&sb, "Added: %s", source.CreatedAt.UTC().Format(timeLayout))
+	if !source.UpdatedAt.IsZero() {
+		fmt.Fprintf(&sb, ", updated: %s", source.UpdatedAt.UTC().Format(timeLayout))
+	}
+	return sb.String()
+}
@@ -0,0 +1,58 @@
+package bot_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot/middleware"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestViewCmdSource(t *testing.T) {
+	server := bottest.NewServer(t)
+	repositories := storage.NewMemory()
+
+	ctx := context.Background()
+	id, err := repositories.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterCommand("sources", middleware.AdminsOnly([]int64{adminID}, bot.ViewCmdSources(repositories.Sources)))
+	newsBot.RegisterCommand("source", middleware.AdminsOnly([]int64{adminID}, bot.ViewCmdSource(repositories.Sources)))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+	admin := conversation.User(adminID)
+
+	admin.Sends("/source 1 tags Go, Releases")
+	conversation.ExpectReply("Tags: go, releases")
+	admin.Sends("/source 1 language DE")
+	conversation.ExpectReply("Language: de")
+	admin.Sends("/source 1 pause")
+	conversation.ExpectReply("Status: paused")
+
+	admin.Sends("/source 1 priority high")
+	conversation.ExpectReply(`Invalid priority "high"`)
+	admin.Sends("/source 1 priority 0")
+	conversation.ExpectReply(`Invalid priority "0"`)
+	admin.Sends("/source 2")
+	conversation.ExpectReply("Source 2 doesn't exist.")
+
+	admin.Sends("/sources")
+	conversation.ExpectReply("1. Go Blog (paused) [go, releases]")
+
+	source, err := repositories.Sources.SourceByID(ctx, id)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+	if !source.Paused || source.Language != "de" || source.Priority != 1 || !slices.Equal(source.Tags, []string{"go", "releases"}) {
+		t.Errorf("unexpected source %+v", source)
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type UpcomingProvider interface {
+	QueueProvider
+	Scheduled(ctx context.Context, chatID int64) ([]model.Article, error)
+}
+
+// upcomingSize is how many of the next queued articles /upcoming shows.
+const upcomingSize = 5
+
+// ViewCmdUpcoming lists the scheduled articles and the next queued ones of the chat given as the argument
+// or of the default chat.
+func ViewCmdUpcoming(provider UpcomingProvider, defaultChatID int64) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		chatID := defaultChatID
+		if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return reply(bot, update, "Usage: /upcoming [chat ID]")
+			}
+			chatID = id
+		}
+
+		scheduled, err := provider.Scheduled(ctx, chatID)
+		if err != nil {
+			return err
+		}
+
+		queue, err := provider.Queue(ctx, chatID)
+		if err != nil {
+			return err
+		}
+
+		var sb strings.Builder
+		if len(scheduled) > 0 {
+			sb.WriteString("Scheduled:\n")
+			for _, article := range scheduled {
+				fmt.Fprintf(&sb, "%s — #%d %s\n", article.ScheduledAt.UTC().Format(timeLayout), article.ID, article.Title)
+			}
+			sb.WriteString("\n")
+		}
+
+		if len(queue) == 0 {
+			sb.WriteString("The queue is empty.")
+		} else {
+			sb.WriteString("Next in the queue:\n")
+			for _, item := range queue[:min(len(queue), upcomingSize)] {
+				fmt.Fprintf(&sb, "#%d %s\n", item.Article.ID, item.Article.Title)
+			}
+		}
+
+		return reply(bot, update, sb.String())
+	}
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/post"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	inlinePageSize = 20
+
+	// inlineCacheTime is short so that new articles show up soon after they are fetched.
+	inlineCacheTime = time.Minute
+
+	// maxSharedSummary leaves room for the title and the link in a message.
+	maxSharedSummary = 3000
+)
+
+type ArticleSharer interface {
+	ArticleSearcher
+	Summaries(ctx context.Context, articleIDs []int64) (map[int64]map[string]string, error)
+}
+
+// ViewInlineShare answers inline queries, "@bot golang generics" typed in any chat, with the
+// matching articles, or the newest ones for an empty query. Choosing one sends it like a
+// channel post, with the summary stored in the user's language if there is one. More results
+// are loaded as the user scrolls.
+func ViewInlineShare(articles ArticleSharer) botkit.ViewFunc {
+	// The default template can't fail to parse.
+	tmpl, _ := post.New("", markup.ModeHTML)
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := update.InlineQuery
+		offset := botkit.InlineOffset(query)
+
+		results, total, err := articles.Search(ctx, strings.TrimSpace(query.Query), offset, inlinePageSize)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, len(results))
+		for i, result := range results {
+			ids[i] = result.Article.ID
+		}
+		summaries, err := articles.Summaries(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to fetch stored summaries: %w", err)
+		}
+
+		// Summaries depend on the language of the user.
+		answer := botkit.NewInlineAnswer(query).Cache(botkit.InlineCache{Time: inlineCacheTime, IsPersonal: true})
+		for _, result := range results {
+			article := result.Article
+			language, summary := sharedSummary(summaries[article.ID], article, query.From)
+
+			text, err := tmpl.Render(post.Data{
+				Title:       article.Title,
+				Summary:     post.Truncate(maxSharedSummary, summary),
+				Link:        article.Link,
+				Language:    language,
+				PublishedAt: article.PublishedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			// The passage matching the query, or else the beginning of the summary.
+			var description string
+			switch {
+			case strings.Contains(result.Headline, model.HighlightStart):
+				description = plainHeadline(result.Headline)
+			case summary != "":
+				description = post.Truncate(100, summary)
+			case result.Headline != "":
+				description = plainHeadline(result.Headline)
+			default:
+				description = article.PublishedAt.UTC().Format("2 Jan 2006")
+			}
+
+			answer.Article(botkit.InlineArticle{
+				ID:           strconv.FormatInt(article.ID, 10),
+				Title:        article.Title,
+				Description:  description,
+				URL:          article.Link,
+				ThumbnailURL: article.ImageURL,
+				Text:         text,
+				ParseMode:    tmpl.ParseMode(),
+			})
+		}
+		if next := offset + uint64(answer.Len()); next < uint64(total) {
+			answer.NextPage(next)
+		}
+
+		_, err = botkit.Send(bot, answer.Request())
+		return err
+	}
+}
+
+// sharedSummary returns the language and the summary of the article, among its stored ones,
+// in the language of the user, or else in the language of the article or English. The summary
+// is empty if the article hasn't been summarized yet.
+func sharedSummary(summaries map[string]string, article model.Article, user *tgbotapi.User) (string, string) {
+	var languages []string
+	if user != nil && user.LanguageCode != "" {
+		// Telegram sends IETF tags such as "pt-br", summaries are stored by ISO 639-1 codes.
+		code, _, _ := strings.Cut(strings.ToLower(user.LanguageCode), "-")
+		languages = append(languages, code)
+	}
+	languages = append(languages, article.Language, lang.English)
+
+	for _, language := range languages {
+		if summary := summaries[language]; language != "" && summary != "" {
+			return language, summary
+		}
+	}
+	return article.Language, ""
+}
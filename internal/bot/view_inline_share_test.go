@@ -0,0 +1,88 @@
+package bot_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestViewInlineShare(t *testing.T) {
+	server := bottest.NewServer(t)
+	repositories := storage.NewMemory()
+
+	ctx := context.Background()
+	published := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 25 {
+		article := model.Article{
+			SourceID:    1,
+			Title:       fmt.Sprintf("News %d", i+1),
+			Link:        fmt.Sprintf("https://go.dev/news/%d", i+1),
+			Summary:     "<p>What happened this week.</p>",
+			Language:    "en",
+			PublishedAt: published.Add(-time.Duration(i) * time.Hour),
+		}
+		if i == 0 {
+			article.Title, article.ImageURL = "Generic type aliases", "https://go.dev/aliases.png"
+		}
+		if err := repositories.Articles.Store(ctx, article); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+
+	aliases, err := repositories.Articles.ArticleByLink(ctx, "https://go.dev/news/1")
+	if err != nil {
+		t.Fatalf("ArticleByLink failed: %v", err)
+	}
+	if err := repositories.Articles.StoreSummary(ctx, aliases.ID, "en", "Type aliases & generics."); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+
+	newsBot := botkit.New(server.BotAPI(t))
+	newsBot.RegisterInline(bot.ViewInlineShare(repositories.Articles))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+	user := conversation.User(7)
+
+	// The stored summary is shared like a channel post.
+	user.Queries("aliases", "")
+	answer := conversation.ExpectInlineAnswer()
+	if len(answer.Results) != 1 || !answer.IsPersonal || answer.CacheTime != 60 {
+		t.Fatalf("unexpected answer %+v", answer)
+	}
+	result := answer.Results[0]
+	if result.Title != "Generic type aliases" || result.Description != "Type aliases & generics." ||
+		result.URL != aliases.Link || result.Thumbnail != aliases.ImageURL {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if want := "<b>Generic type aliases</b>\n\nType aliases &amp; generics.\n\nhttps://go.dev/news/1"; result.Message.Text != want || result.Message.ParseMode != "HTML" {
+		t.Errorf("got message %q (%s), want %q", result.Message.Text, result.Message.ParseMode, want)
+	}
+
+	// An empty query pages through the newest articles.
+	user.Queries("", "")
+	answer = conversation.ExpectInlineAnswer()
+	if len(answer.Results) != 20 || answer.NextOffset != "20" || answer.Results[0].ID != fmt.Sprint(aliases.ID) {
+		t.Fatalf("got %d results, next offset %q", len(answer.Results), answer.NextOffset)
+	}
+	// Articles without a stored summary are shared with the title and link only.
+	if text := answer.Results[1].Message.Text; strings.Contains(text, "happened") {
+		t.Errorf("unexpected message %q", text)
+	}
+
+	user.Queries("", answer.NextOffset)
+	answer = conversation.ExpectInlineAnswer()
+	if len(answer.Results) != 5 || answer.NextOffset != "" {
+		t.Errorf("got %d results, next offset %q on the last page", len(answer.Results), answer.NextOffset)
+	}
+
+	conversation.Script(`user 7 queries rust; expect no results`)
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ModerationCallbackPrefix is the callback data prefix of the moderation buttons.
+const ModerationCallbackPrefix = notifier.ModerationCallbackPrefix
+
+type Moderator interface {
+	Approve(ctx context.Context, articleID int64) error
+	Reject(ctx context.Context, articleID int64) error
+	Postpone(ctx context.Context, articleID int64) (time.Time, error)
+	EditSummary(ctx context.Context, articleID int64, language, summary string) error
+}
+
+// summaryEdit is an article whose new summary the admin is asked to send.
+type summaryEdit struct {
+	preview   *tgbotapi.Message
+	articleID int64
+	language  string
+}
+
+// ViewModeration returns the views handling the moderation buttons and the summaries sent
+// after pressing "Edit summary". Only the admin who pressed the button can send the summary.
+func ViewModeration(moderator Moderator) (onButton, onText botkit.ViewFunc) {
+	var (
+		mu    sync.Mutex
+		edits = make(map[int64]summaryEdit) // by user ID
+	)
+
+	onButton = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := update.CallbackQuery
+
+		parts := strings.Split(query.Data, ":")
+		if len(parts) < 3 {
+			return fmt.Errorf("invalid moderation callback data %q", query.Data)
+		}
+		action := parts[1]
+		articleID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid article ID in callback data %q: %w", query.Data, err)
+		}
+
+		var reply string
+		switch action {
+		case notifier.ActionApprove:
+			if err := moderator.Approve(ctx, articleID); err != nil {
+				return err
+			}
+			reply = fmt.Sprintf("Article %d is approved.", articleID)
+		case notifier.ActionReject:
+			if err := moderator.Reject(ctx, articleID); err != nil {
+				return err
+			}
+			reply = fmt.Sprintf("Article %d is rejected.", articleID)
+		case notifier.ActionPostpone:
+			until, err := moderator.Postpone(ctx, articleID)
+			if err != nil {
+				return err
+			}
+			reply = fmt.Sprintf("Article %d is postponed until %s.", articleID, until.UTC().Format("2 Jan 15:04 MST"))
+		case notifier.ActionEdit:
+			if query.Message == nil {
+				return fmt.Errorf("preview of article %d is no longer available", articleID)
+			}
+
+			var language string
+			if len(parts) > 3 {
+				language = parts[3]
+			}
+
+			mu.Lock()
+			edits[query.From.ID] = summaryEdit{preview: query.Message, articleID: articleID, language: language}
+			mu.Unlock()
+
+			// The buttons are kept until the new summary is sent.
+			msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf("Send the new summary of article %d.", articleID))
+			msg.ReplyToMessageID = query.Message.MessageID
+			_, err := bot.Send(msg)
+			return err
+		default:
+			return fmt.Errorf("unknown moderation action %q", action)
+		}
+
+		mu.Lock()
+		delete(edits, query.From.ID)
+		mu.Unlock()
+
+		return resolve(bot, query.Message, reply)
+	}
+
+	onText = func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		user := update.SentFrom()
+		if user == nil || update.Message.Text == "" {
+			return nil
+		}
+
+		mu.Lock()
+		edit, ok := edits[user.ID]
+		ok = ok && edit.preview.Chat.ID == update.Message.Chat.ID
+		if ok {
+			delete(edits, user.ID)
+		}
+		mu.Unlock()
+
+		if !ok {
+			return nil
+		}
+
+		if err := moderator.EditSummary(ctx, edit.articleID, edit.language, update.Message.Text); err != nil {
+			return err
+		}
+
+		return resolve(bot, edit.preview, fmt.Sprintf("Summary of article %d is updated, the article is approved.", edit.articleID))
+	}
+
+	return onButton, onText
+}
+
+// resolve removes the moderation buttons from the preview and replies to it with the decision.
+func resolve(bot *tgbotapi.BotAPI, preview *tgbotapi.Message, reply string) error {
+	if preview == nil {
+		return nil
+	}
+
+	removeButtons := tgbotapi.NewEditMessageReplyMarkup(preview.Chat.ID, preview.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	})
+	if _, err := bot.Request(removeButtons); err != nil {
+		return fmt.Errorf("failed to remove moderation buttons: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(preview.Chat.ID, reply)
+	msg.ReplyToMessageID = preview.MessageID
+	_, err := bot.Send(msg)
+	return err
+}
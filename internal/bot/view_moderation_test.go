@@ -0,0 +1,119 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot"
+	"github.com/amir-amirov/go-news-feed-bot/internal/bot/middleware"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+)
+
+const (
+	adminID          = 42
+	moderationChatID = -100500
+)
+
+type fakeModerator struct {
+	mu        sync.Mutex
+	decisions []string
+}
+
+func (m *fakeModerator) decide(decision string, articleID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if articleID == 9 {
+		return errors.New("database is down")
+	}
+	m.decisions = append(m.decisions, decision)
+	return nil
+}
+
+func (m *fakeModerator) Approve(_ context.Context, articleID int64) error {
+	return m.decide("approve", articleID)
+}
+
+func (m *fakeModerator) Reject(_ context.Context, articleID int64) error {
+	return m.decide("reject", articleID)
+}
+
+func (m *fakeModerator) Postpone(_ context.Context, articleID int64) (time.Time, error) {
+	return time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC), m.decide("postpone", articleID)
+}
+
+func (m *fakeModerator) EditSummary(_ context.Context, articleID int64, language, summary string) error {
+	return m.decide("edit "+language+" "+summary, articleID)
+}
+
+func TestViewModeration(t *testing.T) {
+	server := bottest.NewServer(t)
+	api := server.BotAPI(t)
+
+	var moderator fakeModerator
+	onButton, onText := bot.ViewModeration(&moderator)
+
+	newsBot := botkit.New(api)
+	newsBot.RegisterCallback(bot.ModerationCallbackPrefix, middleware.AdminsOnly([]int64{adminID}, onButton))
+	newsBot.RegisterText(middleware.AdminsOnly([]int64{adminID}, onText))
+	bottest.Run(t, newsBot)
+
+	conversation := bottest.NewConversation(t, server)
+
+	// The notifier sends the previews through the same API.
+	preview := func(text string) {
+		if _, err := botkit.Send(api, botkit.NewTextRequest(moderationChatID, text, "")); err != nil {
+			t.Fatalf("failed to send preview: %v", err)
+		}
+		conversation.ExpectMessage(moderationChatID, text)
+	}
+
+	preview("Article 7")
+	conversation.Script(`
+		# Other users can't moderate.
+		user 13 in -100500 presses mod:approve:7
+		expect answer; expect no reply
+
+		user 42 in -100500 presses mod:postpone:7
+		expect edit
+		expect reply containing "Article 7 is postponed until 19 Oct 18:00 UTC."
+		expect answer
+	`)
+
+	preview("Article 8")
+	conversation.Script(`
+		user 42 in -100500 presses mod:edit:8:en
+		expect reply containing "Send the new summary of article 8."
+		expect answer
+
+		# Only the admin who pressed the button edits the summary, in the moderation chat.
+		user 42 sends "Not here"
+		expect no reply
+		user 42 in -100500 sends "A shorter summary; it fits."
+		expect edit
+		expect reply containing "Summary of article 8 is updated, the article is approved."
+	`)
+
+	preview("Article 9")
+	conversation.Script(`
+		user 42 in -100500 presses mod:reject:9
+		expect alert containing "internal error."
+		expect no reply
+	`)
+
+	want := []string{"postpone", "edit en A shorter summary; it fits."}
+	moderator.mu.Lock()
+	defer moderator.mu.Unlock()
+	if len(moderator.decisions) != len(want) {
+		t.Fatalf("got decisions %q, want %q", moderator.decisions, want)
+	}
+	for i := range want {
+		if moderator.decisions[i] != want[i] {
+			t.Errorf("got decisions %q, want %q", moderator.decisions, want)
+		}
+	}
+}
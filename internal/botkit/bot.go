@@ -3,6 +3,7 @@ package botkit
 import (
 	"context"
 	"log"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -11,14 +12,18 @@ import (
 type ViewFunc func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error
 
 type Bot struct {
-	api      *tgbotapi.BotAPI
-	cmdViews map[string]ViewFunc
+	api           *tgbotapi.BotAPI
+	cmdViews      map[string]ViewFunc
+	callbackViews map[string]ViewFunc
+	textView      ViewFunc
+	inlineView    ViewFunc
 }
 
 func New(api *tgbotapi.BotAPI) *Bot {
 	return &Bot{
-		api:      api,
-		cmdViews: make(map[string]ViewFunc),
+		api:           api,
+		cmdViews:      make(map[string]ViewFunc),
+		callbackViews: make(map[string]ViewFunc),
 	}
 }
 
@@ -26,6 +31,22 @@ func (b *Bot) RegisterCommand(cmd string, view ViewFunc) {
 	b.cmdViews[cmd] = view
 }
 
+// RegisterCallback handles presses of inline buttons whose callback data is "prefix" or starts with "prefix:".
+func (b *Bot) RegisterCallback(prefix string, view ViewFunc) {
+	b.callbackViews[prefix] = view
+}
+
+// RegisterText handles messages that are not commands.
+func (b *Bot) RegisterText(view ViewFunc) {
+	b.textView = view
+}
+
+// RegisterInline handles inline queries, "@bot <query>" typed in any chat. The view must answer
+// them, Telegram shows nothing otherwise.
+func (b *Bot) RegisterInline(view ViewFunc) {
+	b.inlineView = view
+}
+
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 	defer func() {
 		if r := recover(); r != nil {
@@ -34,14 +55,44 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 		}
 	}()
 
-	if update.Message == nil || !update.Message.IsCommand() {
+	if update.CallbackQuery != nil {
+		return b.handleCallback(ctx, update)
+	}
+
+	if update.InlineQuery != nil {
+		if b.inlineView == nil {
+			return nil
+		}
+
+		if err := b.inlineView(ctx, b.api, update); err != nil {
+			log.Printf("[ERROR] handling inline query %q: %v", update.InlineQuery.Query, err)
+		}
+		return nil
+	}
+
+	if update.Message == nil {
 		return nil
 	}
 
+	// Documents and photos sent with a command in the caption are handled as commands,
+	// e.g. an uploaded file with the caption "/import".
+	if msg := update.Message; msg.Text == "" && len(msg.CaptionEntities) > 0 &&
+		msg.CaptionEntities[0].Offset == 0 && msg.CaptionEntities[0].IsCommand() {
+		update.Message.Text = update.Message.Caption
+		update.Message.Entities = update.Message.CaptionEntities
+	}
+
 	var view ViewFunc
 
 	if !update.Message.IsCommand() {
-		return nil // Ignore non-command messages
+		if b.textView == nil {
+			return nil // Ignore non-command messages
+		}
+
+		if err := b.textView(ctx, b.api, update); err != nil {
+			log.Printf("[ERROR] handling message: %v", err)
+		}
+		return nil
 	}
 
 	cmd := update.Message.Command()
@@ -66,11 +117,35 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 	return nil
 }
 
+func (b *Bot) handleCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	prefix, _, _ := strings.Cut(query.Data, ":")
+
+	view, exists := b.callbackViews[prefix]
+	if !exists {
+		return nil
+	}
+
+	// Telegram shows the button as loading until the query is answered.
+	answer := tgbotapi.NewCallback(query.ID, "")
+	if err := view(ctx, b.api, update); err != nil {
+		log.Printf("[ERROR] handling callback %s: %v", query.Data, err)
+		answer = tgbotapi.NewCallbackWithAlert(query.ID, "internal error.")
+	}
+
+	if _, err := b.api.Request(answer); err != nil {
+		log.Printf("[ERROR] answering callback query: %v", err)
+	}
+
+	return nil
+}
+
 func (b *Bot) Run(ctx context.Context) error {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.api.GetUpdatesChan(u)
+	defer b.api.StopReceivingUpdates()
 
 	for {
 		select {
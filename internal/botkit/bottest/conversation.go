@@ -0,0 +1,425 @@
+package bottest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+)
+
+// Timeout is how long expectations wait for the bot.
+var Timeout = 2 * time.Second
+
+// quietPeriod is how long the bot must stay silent for ExpectNoReply.
+const quietPeriod = 100 * time.Millisecond
+
+// Run runs the bot until the test finishes. The bot must use the API returned by Server.BotAPI.
+func Run(t *testing.T, bot *botkit.Bot) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = bot.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// Conversation sends messages and button presses to the bot and checks what it sends back.
+// Expectations consume the bot's messages in order, so every message has to be expected.
+type Conversation struct {
+	t      *testing.T
+	server *Server
+
+	chatID  int64 // chat of the last message or button press
+	seen    int   // number of messages checked
+	answers int   // number of callback answers checked
+	inline  int   // number of inline answers checked
+}
+
+func NewConversation(t *testing.T, server *Server) *Conversation {
+	return &Conversation{t: t, server: server}
+}
+
+// Actor is a user of the conversation writing in a chat.
+type Actor struct {
+	c      *Conversation
+	userID int64
+	chatID int64
+}
+
+// User returns the user writing to the bot in a private chat.
+func (c *Conversation) User(id int64) *Actor {
+	return &Actor{c: c, userID: id, chatID: id}
+}
+
+// In returns the user writing in the given chat, e.g. a group.
+func (a *Actor) In(chatID int64) *Actor {
+	return &Actor{c: a.c, userID: a.userID, chatID: chatID}
+}
+
+// Sends sends the text to the bot.
+func (a *Actor) Sends(text string) *Conversation {
+	a.c.chatID = a.chatID
+	a.c.server.SendMessage(a.userID, a.chatID, text)
+	return a.c
+}
+
+// Uploads sends the document to the bot, a caption starting with "/" is a command.
+func (a *Actor) Uploads(name string, content []byte, caption string) *Conversation {
+	a.c.chatID = a.chatID
+	a.c.server.SendDocument(a.userID, a.chatID, name, content, caption)
+	return a.c
+}
+
+// Presses presses a button with the callback data on the last message the bot sent to the chat.
+func (a *Actor) Presses(data string) *Conversation {
+	a.c.t.Helper()
+
+	var last *Message
+	for _, msg := range a.c.server.Messages() {
+		if msg.ChatID == a.chatID && strings.HasPrefix(msg.Method, "send") {
+			last = &msg
+		}
+	}
+	if last == nil {
+		a.c.t.Fatalf("user %d presses %q: the bot sent no message to chat %d", a.userID, data, a.chatID)
+	}
+
+	a.c.chatID = a.chatID
+	a.c.server.PressButton(a.userID, *last, data)
+	return a.c
+}
+
+// Queries types "@bot <query>" in a chat, the offset is the next offset of the previous answer
+// when the results are scrolled.
+func (a *Actor) Queries(query, offset string) *Conversation {
+	a.c.server.SendInlineQuery(a.userID, query, offset)
+	return a.c
+}
+
+// ExpectReply checks that the next message of the bot is sent to the chat of the last action
+// and contains the text.
+func (c *Conversation) ExpectReply(text string) Message {
+	c.t.Helper()
+	return c.ExpectMessage(c.chatID, text)
+}
+
+// ExpectMessage checks that the next message of the bot is sent to the chat and contains the text.
+func (c *Conversation) ExpectMessage(chatID int64, text string) Message {
+	c.t.Helper()
+
+	msg := c.next()
+	if !strings.HasPrefix(msg.Method, "send") || msg.ChatID != chatID {
+		c.t.Fatalf("expected a message to %d, got %s to %d: %q", chatID, msg.Method, msg.ChatID, msg.Text)
+	}
+	if !strings.Contains(msg.Text, text) {
+		c.t.Fatalf("expected a message to %d containing %q, got %q", chatID, text, msg.Text)
+	}
+	return msg
+}
+
+// ExpectEdit checks that the bot next edits a message in the chat of the last action and
+// that the edited text contains the given one. Edits of buttons have no text.
+func (c *Conversation) ExpectEdit(text string) Message {
+	c.t.Helper()
+
+	msg := c.next()
+	if !strings.HasPrefix(msg.Method, "edit") || msg.ChatID != c.chatID {
+		c.t.Fatalf("expected an edit in %d, got %s to %d: %q", c.chatID, msg.Method, msg.ChatID, msg.Text)
+	}
+	if !strings.Contains(msg.Text, text) {
+		c.t.Fatalf("expected an edit containing %q, got %q", text, msg.Text)
+	}
+	return msg
+}
+
+// ExpectAnswer checks that the bot answers the next button press with a text containing the
+// given one, as an alert if alert is set.
+func (c *Conversation) ExpectAnswer(text string, alert bool) Answer {
+	c.t.Helper()
+
+	deadline := time.After(Timeout)
+	for {
+		changed := c.server.wait()
+		if answers := c.server.Answers(); len(answers) > c.answers {
+			answer := answers[c.answers]
+			c.answers++
+
+			if !strings.Contains(answer.Text, text) || answer.ShowAlert != alert {
+				c.t.Fatalf("expected an answer containing %q (alert %t), got %q (alert %t)", text, alert, answer.Text, answer.ShowAlert)
+			}
+			return answer
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			c.t.Fatalf("the bot didn't answer the button press in %v", Timeout)
+		}
+	}
+}
+
+// ExpectInlineAnswer waits for the bot to answer the next inline query and returns the answer.
+func (c *Conversation) ExpectInlineAnswer() InlineAnswer {
+	c.t.Helper()
+
+	deadline := time.After(Timeout)
+	for {
+		changed := c.server.wait()
+		if answers := c.server.InlineAnswers(); len(answers) > c.inline {
+			c.inline++
+			return answers[c.inline-1]
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			c.t.Fatalf("the bot didn't answer the inline query in %v", Timeout)
+		}
+	}
+}
+
+// ExpectInlineResult checks that the bot answers the next inline query with a result whose
+// title, description or message contains the text, and returns it.
+func (c *Conversation) ExpectInlineResult(text string) InlineResult {
+	c.t.Helper()
+
+	answer := c.ExpectInlineAnswer()
+	for _, result := range answer.Results {
+		if strings.Contains(result.Title, text) || strings.Contains(result.Description, text) ||
+			strings.Contains(result.Message.Text, text) {
+			return result
+		}
+	}
+
+	c.t.Fatalf("expected an inline result containing %q, got %+v", text, answer.Results)
+	return InlineResult{}
+}
+
+// ExpectNoReply checks that the bot doesn't send anything for a while.
+func (c *Conversation) ExpectNoReply() {
+	c.t.Helper()
+
+	time.Sleep(quietPeriod)
+	if messages := c.server.Messages(); len(messages) > c.seen {
+		msg := messages[c.seen]
+		c.t.Fatalf("expected no reply, got %s to %d: %q", msg.Method, msg.ChatID, msg.Text)
+	}
+}
+
+// next waits for the next message of the bot.
+func (c *Conversation) next() Message {
+	c.t.Helper()
+
+	deadline := time.After(Timeout)
+	for {
+		changed := c.server.wait()
+		if messages := c.server.Messages(); len(messages) > c.seen {
+			c.seen++
+			return messages[c.seen-1]
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			c.t.Fatalf("the bot sent nothing in %v", Timeout)
+		}
+	}
+}
+
+// Script runs a conversation written as statements, one per line or separated by semicolons:
+//
+//	user 42 sends /skip 7
+//	expect reply containing "Article 7 is skipped"
+//	user 42 in -100123 sends hello; expect no reply
+//	user 42 presses mod:approve:7
+//	expect answer
+//	expect edit
+//	expect message to -100123 containing "Approved"
+//	expect alert containing "internal error"
+//	user 42 queries golang generics
+//	expect results containing "Type parameters"
+//
+// Texts may be quoted with double quotes, e.g. to keep a semicolon. Lines starting with # are comments.
+func (c *Conversation) Script(script string) {
+	c.t.Helper()
+
+	for _, statement := range splitStatements(script) {
+		if err := c.run(statement); err != nil {
+			c.t.Fatalf("%q: %v", statement, err)
+		}
+	}
+}
+
+func (c *Conversation) run(statement string) error {
+	c.t.Helper()
+
+	word, rest := cutWord(statement)
+	switch word {
+	case "user":
+		idWord, rest := cutWord(rest)
+		id, err := strconv.ParseInt(idWord, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid user ID %q", idWord)
+		}
+		actor := c.User(id)
+
+		action, rest := cutWord(rest)
+		if action == "in" {
+			chatWord, chatRest := cutWord(rest)
+			chatID, err := strconv.ParseInt(chatWord, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid chat ID %q", chatWord)
+			}
+			actor = actor.In(chatID)
+			action, rest = cutWord(chatRest)
+		}
+
+		text, err := unquote(rest)
+		if err != nil {
+			return err
+		}
+
+		switch action {
+		case "sends":
+			actor.Sends(text)
+		case "presses":
+			actor.Presses(text)
+		case "queries":
+			actor.Queries(text, "")
+		default:
+			return fmt.Errorf("unknown action %q, want sends, presses or queries", action)
+		}
+		return nil
+	case "expect":
+		return c.expect(rest)
+	default:
+		return fmt.Errorf("unknown statement, want user or expect")
+	}
+}
+
+func (c *Conversation) expect(rest string) error {
+	c.t.Helper()
+
+	switch rest {
+	case "no reply":
+		c.ExpectNoReply()
+		return nil
+	case "no results":
+		if answer := c.ExpectInlineAnswer(); len(answer.Results) > 0 {
+			return fmt.Errorf("expected no results, got %d", len(answer.Results))
+		}
+		return nil
+	}
+
+	kind, rest := cutWord(rest)
+
+	chatID := c.chatID
+	if kind == "message" {
+		to, chatRest := cutWord(rest)
+		chatWord, chatRest := cutWord(chatRest)
+		id, err := strconv.ParseInt(chatWord, 10, 64)
+		if to != "to" || err != nil {
+			return fmt.Errorf("want message to <chat ID>")
+		}
+		chatID, rest = id, chatRest
+	}
+
+	var text string
+	if rest != "" {
+		containing, textRest := cutWord(rest)
+		if containing != "containing" {
+			return fmt.Errorf("want containing <text>, got %q", rest)
+		}
+
+		var err error
+		if text, err = unquote(textRest); err != nil {
+			return err
+		}
+	}
+
+	switch kind {
+	case "reply":
+		c.ExpectReply(text)
+	case "message":
+		c.ExpectMessage(chatID, text)
+	case "edit":
+		c.ExpectEdit(text)
+	case "answer":
+		c.ExpectAnswer(text, false)
+	case "alert":
+		c.ExpectAnswer(text, true)
+	case "results":
+		c.ExpectInlineResult(text)
+	default:
+		return fmt.Errorf("unknown expectation %q, want reply, message, edit, answer, alert, results, no reply or no results", kind)
+	}
+	return nil
+}
+
+// splitStatements splits the script by lines and semicolons outside quotes, dropping comments
+// and empty statements.
+func splitStatements(script string) []string {
+	var statements []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var (
+			current  strings.Builder
+			quoted   bool
+			previous rune
+		)
+		for _, r := range line {
+			switch {
+			case r == '"' && previous != '\\':
+				quoted = !quoted
+			case r == ';' && !quoted:
+				statements = appendStatement(statements, current.String())
+				current.Reset()
+				previous = r
+				continue
+			}
+			current.WriteRune(r)
+			previous = r
+		}
+		statements = appendStatement(statements, current.String())
+	}
+	return statements
+}
+
+func appendStatement(statements []string, statement string) []string {
+	if statement = strings.TrimSpace(statement); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func cutWord(s string) (string, string) {
+	word, rest, _ := strings.Cut(strings.TrimSpace(s), " ")
+	return word, strings.TrimSpace(rest)
+}
+
+func unquote(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return s, nil
+	}
+
+	text, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted text %s", s)
+	}
+	return text, nil
+}
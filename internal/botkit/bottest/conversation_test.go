@@ -0,0 +1,83 @@
+package bottest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/bottest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestConversation(t *testing.T) {
+	server := bottest.NewServer(t)
+	server.SetChatMember(-100123, 42, "administrator")
+
+	b := botkit.New(server.BotAPI(t))
+	b.RegisterCommand("echo", func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		_, err := bot.Send(tgbotapi.NewMessage(update.FromChat().ID, "echo: "+update.Message.CommandArguments()))
+		return err
+	})
+	b.RegisterCommand("role", func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		member, err := bot.GetChatMember(tgbotapi.GetChatMemberConfig{ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: update.FromChat().ID,
+			UserID: update.SentFrom().ID,
+		}})
+		if err != nil {
+			return err
+		}
+		_, err = bot.Send(tgbotapi.NewMessage(update.FromChat().ID, "role: "+member.Status))
+		return err
+	})
+	b.RegisterCommand("photo", func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		req := botkit.NewPhotoRequest(update.FromChat().ID, "https://go.dev/x.png", "a gopher", "")
+		_, err := botkit.Send(bot, req)
+		return err
+	})
+	b.RegisterCallback("like", func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		query := update.CallbackQuery
+		if query.Data == "like:fail" {
+			return errors.New("failed")
+		}
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, "liked by "+query.From.FirstName)
+		_, err := bot.Send(edit)
+		return err
+	})
+	b.RegisterInline(func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		answer := botkit.NewInlineAnswer(update.InlineQuery)
+		if query := update.InlineQuery.Query; query != "" {
+			answer.Article(botkit.InlineArticle{ID: "1", Title: "Echo", Text: "echo: " + query})
+		}
+		_, err := botkit.Send(bot, answer.Request())
+		return err
+	})
+	bottest.Run(t, b)
+
+	bottest.NewConversation(t, server).Script(`
+		user 42 sends /echo hello; expect reply containing "echo: hello"
+		user 42 sends /unknown; expect no reply
+		user 42 sends not a command; expect no reply
+
+		user 42 in -100123 sends /role
+		expect message to -100123 containing "role: administrator"
+		# getChatMember fails for unknown members, the bot replies with an error.
+		user 7 in -100123 sends /role
+		expect reply containing "internal error."
+
+		user 42 sends /photo; expect reply containing "a gopher"
+		user 42 presses like:1
+		expect edit containing "liked by User 42"
+		expect answer
+		user 42 presses like:fail
+		expect alert containing "internal error."
+
+		user 42 queries hello; expect results containing "echo: hello"
+		user 42 queries ""; expect no results
+	`)
+
+	messages := server.Messages()
+	if photo := messages[3]; photo.Method != "sendPhoto" || photo.Params["photo"] != "https://go.dev/x.png" {
+		t.Errorf("unexpected photo message %+v", photo)
+	}
+}
@@ -0,0 +1,544 @@
+// Package bottest provides a fake Telegram Bot API server for tests of bots built with botkit,
+// and a scripted-conversation DSL driving it, see Script.
+package bottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Token is the bot token accepted by the server.
+const Token = "123456:test-token"
+
+// BotUser is the bot the server pretends to be.
+var BotUser = tgbotapi.User{ID: 123456, IsBot: true, FirstName: "News Feed Bot", UserName: "news_feed_test_bot"}
+
+// Message is a message the bot sent or edited, or another request it made, e.g. deleteMessage.
+type Message struct {
+	Method    string
+	ChatID    int64
+	MessageID int
+	Text      string // the text, or the caption of photos and documents
+	Params    map[string]string
+	File      []byte // the uploaded document of sendDocument, its name is Params["document"]
+}
+
+// Answer is the bot's answer to a callback query.
+type Answer struct {
+	CallbackQueryID string
+	Text            string
+	ShowAlert       bool
+}
+
+// InlineAnswer is the bot's answer to an inline query.
+type InlineAnswer struct {
+	InlineQueryID string
+	Results       []InlineResult
+	CacheTime     int
+	IsPersonal    bool
+	NextOffset    string
+}
+
+// InlineResult is a result of an inline answer.
+type InlineResult struct {
+	Type        string        `json:"type"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	Thumbnail   string        `json:"thumbnail_url"`
+	Message     InlineMessage `json:"input_message_content"`
+}
+
+// InlineMessage is the message sent to the chat when the user chooses an inline result.
+type InlineMessage struct {
+	Text      string `json:"message_text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+// Server is a stand-in for the Bot API. It supports getMe, getUpdates, sendMessage, sendPhoto,
+// sendDocument, editMessageText, editMessageCaption, editMessageReplyMarkup, deleteMessage,
+// answerCallbackQuery, answerInlineQuery, getChatMember, getFile and downloads of the files
+// users sent, see FileEndpoint; other methods fail with 404 like unknown methods do.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	changed      chan struct{} // closed and replaced when an update is queued or the bot makes a request
+	closed       chan struct{}
+	updates      []tgbotapi.Update
+	messages     []Message
+	answers      []Answer
+	inline       []InlineAnswer
+	members      map[[2]int64]string
+	files        map[string][]byte // documents users sent by file ID
+	lastUpdateID int
+	lastMessage  int
+	lastQueryID  int
+}
+
+// NewServer starts a server that is closed when the test finishes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := &Server{
+		changed: make(chan struct{}),
+		closed:  make(chan struct{}),
+		members: make(map[[2]int64]string),
+		files:   make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	t.Cleanup(func() {
+		// Pending getUpdates long polls must return before the server can close.
+		close(s.closed)
+		s.Server.Close()
+	})
+
+	return s
+}
+
+// Endpoint returns the API endpoint to pass to tgbotapi.NewBotAPIWithAPIEndpoint.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+// BotAPI returns a client of the server.
+func (s *Server) BotAPI(t *testing.T) *tgbotapi.BotAPI {
+	t.Helper()
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(Token, s.Endpoint())
+	if err != nil {
+		t.Fatalf("failed to create bot API: %v", err)
+	}
+	return api
+}
+
+// FileEndpoint returns the endpoint files are downloaded from, tests downloading files set
+// botkit.FileEndpoint to it.
+func (s *Server) FileEndpoint() string {
+	return s.URL + "/file/bot%s/%s"
+}
+
+// SetChatMember sets the status of the user in the chat returned by getChatMember,
+// e.g. "administrator". Users not set are not found.
+func (s *Server) SetChatMember(chatID, userID int64, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.members[[2]int64{chatID, userID}] = status
+}
+
+// Messages returns the messages the bot sent so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// Answers returns the answers to callback queries so far.
+func (s *Server) Answers() []Answer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Answer(nil), s.answers...)
+}
+
+// InlineAnswers returns the answers to inline queries so far.
+func (s *Server) InlineAnswers() []InlineAnswer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]InlineAnswer(nil), s.inline...)
+}
+
+// SendMessage queues a message of the user to the chat for the bot to receive.
+// Text starting with "/" is a command.
+func (s *Server) SendMessage(userID, chatID int64, text string) tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastMessage++
+	msg := tgbotapi.Message{
+		MessageID: s.lastMessage,
+		From:      user(userID),
+		Chat:      chat(chatID),
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	s.queue(tgbotapi.Update{Message: &msg})
+	return msg
+}
+
+// SendDocument queues a document of the user to the chat for the bot to receive. A caption
+// starting with "/" is a command.
+func (s *Server) SendDocument(userID, chatID int64, name string, content []byte, caption string) tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastMessage++
+	fileID := fmt.Sprintf("document-%d", s.lastMessage)
+	s.files[fileID] = content
+
+	msg := tgbotapi.Message{
+		MessageID: s.lastMessage,
+		From:      user(userID),
+		Chat:      chat(chatID),
+		Date:      int(time.Now().Unix()),
+		Document:  &tgbotapi.Document{FileID: fileID, FileUniqueID: fileID, FileName: name, FileSize: len(content)},
+		Caption:   caption,
+	}
+	if strings.HasPrefix(caption, "/") {
+		command, _, _ := strings.Cut(caption, " ")
+		msg.CaptionEntities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	s.queue(tgbotapi.Update{Message: &msg})
+	return msg
+}
+
+// PressButton queues a press of an inline button of the bot's message with the given callback data.
+// It returns the ID of the callback query.
+func (s *Server) PressButton(userID int64, message Message, data string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastQueryID++
+	id := strconv.Itoa(s.lastQueryID)
+
+	s.queue(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   id,
+		From: user(userID),
+		Message: &tgbotapi.Message{
+			MessageID: message.MessageID,
+			From:      &BotUser,
+			Chat:      chat(message.ChatID),
+			Text:      message.Text,
+		},
+		Data: data,
+	}})
+	return id
+}
+
+// SendInlineQuery queues an inline query of the user, "@bot <query>" typed in any chat. The
+// offset is the next offset of the previous answer when the user scrolls the results. It
+// returns the ID of the query.
+func (s *Server) SendInlineQuery(userID int64, query, offset string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastQueryID++
+	id := strconv.Itoa(s.lastQueryID)
+
+	s.queue(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{
+		ID:     id,
+		From:   user(userID),
+		Query:  query,
+		Offset: offset,
+	}})
+	return id
+}
+
+func user(id int64) *tgbotapi.User {
+	return &tgbotapi.User{ID: id, FirstName: fmt.Sprintf("User %d", id)}
+}
+
+// chat returns the chat of the ID: positive IDs are private chats, negative ones groups and channels.
+func chat(id int64) *tgbotapi.Chat {
+	if id > 0 {
+		return &tgbotapi.Chat{ID: id, Type: "private"}
+	}
+	return &tgbotapi.Chat{ID: id, Type: "supergroup", Title: fmt.Sprintf("Chat %d", id)}
+}
+
+// queue adds the update, s.mu must be held.
+func (s *Server) queue(update tgbotapi.Update) {
+	s.lastUpdateID++
+	update.UpdateID = s.lastUpdateID
+	s.updates = append(s.updates, update)
+	s.notify()
+}
+
+// notify wakes up the waiters, s.mu must be held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// wait returns a channel closed on the next change, see notify.
+func (s *Server) wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.changed
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/file/bot") {
+		s.download(w, r)
+		return
+	}
+
+	// Paths are /bot<token>/<method>.
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !ok || token != Token {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		_ = r.ParseMultipartForm(10 << 20)
+	} else {
+		_ = r.ParseForm()
+	}
+	params := make(map[string]string, len(r.Form))
+	for key := range r.Form {
+		params[key] = r.Form.Get(key)
+	}
+	var file []byte
+	if r.MultipartForm != nil {
+		for key, headers := range r.MultipartForm.File {
+			params[key] = headers[0].Filename
+			file = readFile(headers[0])
+		}
+	}
+
+	switch method {
+	case "getMe":
+		respond(w, BotUser)
+	case "getUpdates":
+		s.getUpdates(w, params)
+	case "sendMessage", "sendPhoto":
+		s.send(w, method, params, nil)
+	case "sendDocument":
+		s.send(w, method, params, file)
+	case "editMessageText", "editMessageCaption", "editMessageReplyMarkup":
+		s.edit(w, method, params)
+	case "deleteMessage":
+		s.record(Message{Method: method, ChatID: int64Param(params, "chat_id"), MessageID: int(int64Param(params, "message_id")), Params: params})
+		respond(w, true)
+	case "answerCallbackQuery":
+		s.answerCallbackQuery(w, params)
+	case "answerInlineQuery":
+		s.answerInlineQuery(w, params)
+	case "getChatMember":
+		s.getChatMember(w, params)
+	case "getFile":
+		s.getFile(w, params)
+	default:
+		respondError(w, http.StatusNotFound, "Not Found: method not found")
+	}
+}
+
+func (s *Server) getUpdates(w http.ResponseWriter, params map[string]string) {
+	offset := int(int64Param(params, "offset"))
+	timeout := time.Duration(int64Param(params, "timeout")) * time.Second
+	deadline := time.After(timeout)
+
+	for {
+		s.mu.Lock()
+		updates := []tgbotapi.Update{}
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				updates = append(updates, update)
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(updates) > 0 || timeout == 0 {
+			respond(w, updates)
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			respond(w, updates)
+			return
+		case <-s.closed:
+			respond(w, updates)
+			return
+		}
+	}
+}
+
+func (s *Server) send(w http.ResponseWriter, method string, params map[string]string, file []byte) {
+	chatID := int64Param(params, "chat_id")
+	if chatID == 0 {
+		respondError(w, http.StatusBadRequest, "Bad Request: chat_id is empty")
+		return
+	}
+
+	s.mu.Lock()
+	s.lastMessage++
+	id := s.lastMessage
+	s.mu.Unlock()
+
+	text := params["text"]
+	if method == "sendPhoto" || method == "sendDocument" {
+		text = params["caption"]
+	}
+	s.record(Message{Method: method, ChatID: chatID, MessageID: id, Text: text, Params: params, File: file})
+
+	respond(w, sentMessage(chatID, id, method, text))
+}
+
+func (s *Server) edit(w http.ResponseWriter, method string, params map[string]string) {
+	chatID, id := int64Param(params, "chat_id"), int(int64Param(params, "message_id"))
+	text := params["text"]
+	if method == "editMessageCaption" {
+		text = params["caption"]
+	}
+	s.record(Message{Method: method, ChatID: chatID, MessageID: id, Text: text, Params: params})
+
+	respond(w, sentMessage(chatID, id, method, text))
+}
+
+func sentMessage(chatID int64, id int, method, text string) tgbotapi.Message {
+	msg := tgbotapi.Message{MessageID: id, From: &BotUser, Chat: chat(chatID), Date: int(time.Now().Unix())}
+	if method == "sendPhoto" || method == "sendDocument" || method == "editMessageCaption" {
+		msg.Caption = text
+	} else {
+		msg.Text = text
+	}
+	return msg
+}
+
+func (s *Server) answerCallbackQuery(w http.ResponseWriter, params map[string]string) {
+	s.mu.Lock()
+	s.answers = append(s.answers, Answer{
+		CallbackQueryID: params["callback_query_id"],
+		Text:            params["text"],
+		ShowAlert:       params["show_alert"] == "true",
+	})
+	s.notify()
+	s.mu.Unlock()
+
+	respond(w, true)
+}
+
+func (s *Server) answerInlineQuery(w http.ResponseWriter, params map[string]string) {
+	answer := InlineAnswer{
+		InlineQueryID: params["inline_query_id"],
+		CacheTime:     int(int64Param(params, "cache_time")),
+		IsPersonal:    params["is_personal"] == "true",
+		NextOffset:    params["next_offset"],
+	}
+	if err := json.Unmarshal([]byte(params["results"]), &answer.Results); err != nil {
+		respondError(w, http.StatusBadRequest, "Bad Request: can't parse inline query results")
+		return
+	}
+
+	s.mu.Lock()
+	s.inline = append(s.inline, answer)
+	s.notify()
+	s.mu.Unlock()
+
+	respond(w, true)
+}
+
+func (s *Server) getChatMember(w http.ResponseWriter, params map[string]string) {
+	chatID, userID := int64Param(params, "chat_id"), int64Param(params, "user_id")
+
+	s.mu.Lock()
+	status, ok := s.members[[2]int64{chatID, userID}]
+	s.mu.Unlock()
+
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Bad Request: user not found")
+		return
+	}
+	respond(w, tgbotapi.ChatMember{User: user(userID), Status: status})
+}
+
+func (s *Server) getFile(w http.ResponseWriter, params map[string]string) {
+	fileID := params["file_id"]
+
+	s.mu.Lock()
+	content, ok := s.files[fileID]
+	s.mu.Unlock()
+
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Bad Request: invalid file_id")
+		return
+	}
+	respond(w, tgbotapi.File{FileID: fileID, FileUniqueID: fileID, FileSize: len(content), FilePath: "documents/" + fileID})
+}
+
+// download serves the files of getFile, paths are /file/bot<token>/documents/<file ID>.
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	token, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/file/bot"), "/")
+	if token != Token {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	content, ok := s.files[strings.TrimPrefix(path, "documents/")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func readFile(header *multipart.FileHeader) []byte {
+	file, err := header.Open()
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	content, _ := io.ReadAll(file)
+	return content
+}
+
+func (s *Server) record(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, msg)
+	s.notify()
+}
+
+func int64Param(params map[string]string, key string) int64 {
+	n, _ := strconv.ParseInt(params[key], 10, 64)
+	return n
+}
+
+func respond(w http.ResponseWriter, result any) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+func respondError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: code, Description: description})
+}
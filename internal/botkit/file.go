@@ -0,0 +1,46 @@
+package botkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MaxDownloadSize is the size of the largest file bots can download from the Bot API.
+const MaxDownloadSize = 20 << 20
+
+// FileEndpoint is the URL format of downloads with the bot token and the file path,
+// tests point it to a fake server.
+var FileEndpoint = tgbotapi.FileEndpoint
+
+// DownloadFile returns the content of a file sent to the bot, e.g. a document.
+func DownloadFile(ctx context.Context, api *tgbotapi.BotAPI, fileID string) ([]byte, error) {
+	file, err := api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", fileID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(FileEndpoint, api.Token, file.FilePath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %s: %w", fileID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file %s: %s", fileID, resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, MaxDownloadSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %s: %w", fileID, err)
+	}
+	return content, nil
+}
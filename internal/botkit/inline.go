@@ -0,0 +1,134 @@
+package botkit
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MaxInlineResults is the number of results Telegram accepts in one answer to an inline query.
+const MaxInlineResults = 50
+
+// InlineArticle is a result of an inline query that sends a text message to the chat when
+// the user chooses it.
+type InlineArticle struct {
+	ID           string // unique among the results of the query, at most 64 bytes
+	Title        string
+	Description  string
+	URL          string
+	ThumbnailURL string
+
+	Text        string
+	ParseMode   string
+	LinkPreview LinkPreview
+	ReplyMarkup tgbotapi.InlineKeyboardMarkup
+}
+
+type inputTextMessage struct {
+	Text        string       `json:"message_text"`
+	ParseMode   string       `json:"parse_mode,omitempty"`
+	LinkPreview *LinkPreview `json:"link_preview_options,omitempty"`
+}
+
+func (a InlineArticle) MarshalJSON() ([]byte, error) {
+	result := struct {
+		Type         string                         `json:"type"`
+		ID           string                         `json:"id"`
+		Title        string                         `json:"title"`
+		Description  string                         `json:"description,omitempty"`
+		URL          string                         `json:"url,omitempty"`
+		ThumbnailURL string                         `json:"thumbnail_url,omitempty"`
+		Message      inputTextMessage               `json:"input_message_content"`
+		ReplyMarkup  *tgbotapi.InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	}{
+		Type:         "article",
+		ID:           a.ID,
+		Title:        a.Title,
+		Description:  a.Description,
+		URL:          a.URL,
+		ThumbnailURL: a.ThumbnailURL,
+		Message:      inputTextMessage{Text: a.Text, ParseMode: a.ParseMode},
+	}
+	if a.LinkPreview != (LinkPreview{}) {
+		result.Message.LinkPreview = &a.LinkPreview
+	}
+	if len(a.ReplyMarkup.InlineKeyboard) > 0 {
+		result.ReplyMarkup = &a.ReplyMarkup
+	}
+	return json.Marshal(result)
+}
+
+// InlineCache tells Telegram how long it may show the same results for the same query
+// without asking the bot again, and whether they only apply to the user who sent it.
+type InlineCache struct {
+	Time       time.Duration
+	IsPersonal bool
+}
+
+// InlineAnswer is the answer to an inline query, build it with NewInlineAnswer.
+type InlineAnswer struct {
+	query   *tgbotapi.InlineQuery
+	results []InlineArticle
+	cache   InlineCache
+	next    string
+}
+
+// NewInlineAnswer starts the answer to the inline query. Telegram caches answers for five
+// minutes unless Cache says otherwise.
+func NewInlineAnswer(query *tgbotapi.InlineQuery) *InlineAnswer {
+	return &InlineAnswer{query: query, cache: InlineCache{Time: 5 * time.Minute}}
+}
+
+// Article adds a result, the ones beyond MaxInlineResults are dropped.
+func (a *InlineAnswer) Article(article InlineArticle) *InlineAnswer {
+	if len(a.results) < MaxInlineResults {
+		a.results = append(a.results, article)
+	}
+	return a
+}
+
+// Cache sets the caching hints of the answer.
+func (a *InlineAnswer) Cache(cache InlineCache) *InlineAnswer {
+	a.cache = cache
+	return a
+}
+
+// NextPage makes Telegram ask for more results, with the offset as the query's offset, when
+// the user scrolls to the end of these ones.
+func (a *InlineAnswer) NextPage(offset uint64) *InlineAnswer {
+	a.next = strconv.FormatUint(offset, 10)
+	return a
+}
+
+// Len returns the number of results.
+func (a *InlineAnswer) Len() int {
+	return len(a.results)
+}
+
+// Request returns the answerInlineQuery call, send it with Send.
+func (a *InlineAnswer) Request() Request {
+	results := a.results
+	if results == nil {
+		results = []InlineArticle{}
+	}
+
+	params := tgbotapi.Params{
+		"inline_query_id": a.query.ID,
+		"cache_time":      strconv.Itoa(int(a.cache.Time / time.Second)),
+	}
+	params.AddBool("is_personal", a.cache.IsPersonal)
+	params.AddNonEmpty("next_offset", a.next)
+	// Articles only contain basic types and keyboards, marshalling can't fail.
+	_ = params.AddInterface("results", results)
+
+	return Request{Method: "answerInlineQuery", Params: params}
+}
+
+// InlineOffset returns the offset of the page of results the inline query asks for, the one
+// given to NextPage of the previous answer, or 0 for the first page.
+func InlineOffset(query *tgbotapi.InlineQuery) uint64 {
+	offset, _ := strconv.ParseUint(query.Offset, 10, 64)
+	return offset
+}
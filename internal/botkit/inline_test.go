@@ -0,0 +1,57 @@
+package botkit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestInlineAnswer(t *testing.T) {
+	query := &tgbotapi.InlineQuery{ID: "q1", Query: "go", Offset: "20"}
+
+	answer := botkit.NewInlineAnswer(query).
+		Article(botkit.InlineArticle{
+			ID:          "7",
+			Title:       "Go 1.24",
+			URL:         "https://go.dev/blog/go1.24",
+			Text:        "*Go 1.24*",
+			ParseMode:   "MarkdownV2",
+			LinkPreview: botkit.LinkPreview{IsDisabled: true},
+		}).
+		Cache(botkit.InlineCache{Time: time.Minute, IsPersonal: true}).
+		NextPage(botkit.InlineOffset(query) + 1)
+	req := answer.Request()
+
+	if req.Method != "answerInlineQuery" {
+		t.Errorf("unexpected method %q", req.Method)
+	}
+
+	want := map[string]string{
+		"inline_query_id": "q1",
+		"cache_time":      "60",
+		"is_personal":     "true",
+		"next_offset":     "21",
+		"results": `[{"type":"article","id":"7","title":"Go 1.24","url":"https://go.dev/blog/go1.24",` +
+			`"input_message_content":{"message_text":"*Go 1.24*","parse_mode":"MarkdownV2","link_preview_options":{"is_disabled":true}}}]`,
+	}
+	for key, value := range want {
+		if got := req.Params[key]; got != value {
+			t.Errorf("%s: got %s, want %s", key, got, value)
+		}
+	}
+}
+
+func TestInlineAnswerDefaults(t *testing.T) {
+	req := botkit.NewInlineAnswer(&tgbotapi.InlineQuery{ID: "q1"}).Request()
+
+	if req.Params["results"] != "[]" || req.Params["cache_time"] != "300" {
+		t.Errorf("unexpected parameters %v", req.Params)
+	}
+	for _, key := range []string{"is_personal", "next_offset"} {
+		if _, ok := req.Params[key]; ok {
+			t.Errorf("unexpected parameter %s", key)
+		}
+	}
+}
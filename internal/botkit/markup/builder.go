@@ -0,0 +1,251 @@
+package markup
+
+import "strings"
+
+// Node is a piece of formatted text. Nodes escape their contents according
+// to the parse mode and the context they are rendered in.
+type Node interface {
+	render(mode string, sb *strings.Builder)
+}
+
+type textNode string
+
+func (n textNode) render(mode string, sb *strings.Builder) {
+	sb.WriteString(Escape(mode, string(n)))
+}
+
+// Text is plain text.
+func Text(s string) Node {
+	return textNode(s)
+}
+
+type styleNode struct {
+	markdown string // MarkdownV2 marker, e.g. "*"
+	html     string // HTML tag, e.g. "b"
+	children []Node
+}
+
+func (n styleNode) render(mode string, sb *strings.Builder) {
+	inner := renderNodes(mode, n.children)
+
+	if mode == ModeHTML {
+		sb.WriteString("<" + n.html + ">" + inner + "</" + n.html + ">")
+		return
+	}
+
+	// "___" is ambiguous between italic and underline, they are separated by "\r" as Telegram
+	// documents, the character is ignored.
+	if strings.HasPrefix(n.markdown, "_") {
+		if strings.HasPrefix(inner, "_") {
+			inner = "\r" + inner
+		}
+		if strings.HasSuffix(inner, "_") && !strings.HasSuffix(inner, "\\_") {
+			inner += "\r"
+		}
+	}
+
+	sb.WriteString(n.markdown + inner + n.markdown)
+}
+
+// Bold makes the text bold.
+func Bold(children ...Node) Node {
+	return styleNode{markdown: "*", html: "b", children: children}
+}
+
+// Italic makes the text italic.
+func Italic(children ...Node) Node {
+	return styleNode{markdown: "_", html: "i", children: children}
+}
+
+// Underline underlines the text.
+func Underline(children ...Node) Node {
+	return styleNode{markdown: "__", html: "u", children: children}
+}
+
+// Strikethrough strikes the text through.
+func Strikethrough(children ...Node) Node {
+	return styleNode{markdown: "~", html: "s", children: children}
+}
+
+// Spoiler hides the text until it is tapped.
+func Spoiler(children ...Node) Node {
+	return styleNode{markdown: "||", html: "tg-spoiler", children: children}
+}
+
+type linkNode struct {
+	url      string
+	children []Node
+}
+
+func (n linkNode) render(mode string, sb *strings.Builder) {
+	inner := renderNodes(mode, n.children)
+
+	if mode == ModeHTML {
+		sb.WriteString(`<a href="` + EscapeForHTML(n.url) + `">` + inner + "</a>")
+		return
+	}
+
+	sb.WriteString("[" + inner + "](" + EscapeLinkURLForMarkdown(n.url) + ")")
+}
+
+// Link is an inline link to url, the children are the visible text.
+func Link(url string, children ...Node) Node {
+	return linkNode{url: url, children: children}
+}
+
+type codeNode struct {
+	code     string
+	language string
+	block    bool
+}
+
+func (n codeNode) render(mode string, sb *strings.Builder) {
+	if mode == ModeHTML {
+		code := "<code>" + EscapeForHTML(n.code) + "</code>"
+		if n.language != "" {
+			code = `<code class="language-` + EscapeForHTML(n.language) + `">` + EscapeForHTML(n.code) + "</code>"
+		}
+		if n.block {
+			code = "<pre>" + code + "</pre>"
+		}
+		sb.WriteString(code)
+		return
+	}
+
+	if n.block {
+		sb.WriteString("```" + n.language + "\n" + EscapeCodeForMarkdown(n.code) + "\n```")
+		return
+	}
+	sb.WriteString("`" + EscapeCodeForMarkdown(n.code) + "`")
+}
+
+// Code is an inline monospace fragment.
+func Code(code string) Node {
+	return codeNode{code: code}
+}
+
+// Pre is a preformatted code block, language may be empty.
+func Pre(code, language string) Node {
+	return codeNode{code: code, language: language, block: true}
+}
+
+type quoteNode struct {
+	children []Node
+}
+
+func (n quoteNode) render(mode string, sb *strings.Builder) {
+	inner := renderNodes(mode, n.children)
+
+	if mode == ModeHTML {
+		sb.WriteString("<blockquote>" + inner + "</blockquote>")
+		return
+	}
+
+	lines := strings.Split(inner, "\n")
+	for i, line := range lines {
+		lines[i] = ">" + line
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+}
+
+// Quote is a block quotation. In MarkdownV2 it must start at the beginning of a line.
+func Quote(children ...Node) Node {
+	return quoteNode{children: children}
+}
+
+type nodes []Node
+
+func (n nodes) render(mode string, sb *strings.Builder) {
+	for _, child := range n {
+		child.render(mode, sb)
+	}
+}
+
+// Group joins several nodes into one.
+func Group(children ...Node) Node {
+	return nodes(children)
+}
+
+func renderNodes(mode string, children []Node) string {
+	var sb strings.Builder
+	nodes(children).render(mode, &sb)
+	return sb.String()
+}
+
+// Render renders the nodes in the given parse mode.
+func Render(mode string, children ...Node) string {
+	return renderNodes(mode, children)
+}
+
+// Builder builds a formatted message piece by piece.
+//
+//	b := markup.NewBuilder(markup.ModeMarkdownV2)
+//	b.Bold("Go 1.22 is released!").Line().Text("Read more: ").Link("go.dev", url)
+//	msg.Text, msg.ParseMode = b.String(), b.Mode()
+type Builder struct {
+	mode  string
+	nodes []Node
+}
+
+func NewBuilder(mode string) *Builder {
+	return &Builder{mode: mode}
+}
+
+// Mode returns the parse mode the message must be sent with.
+func (b *Builder) Mode() string {
+	return b.mode
+}
+
+func (b *Builder) Append(children ...Node) *Builder {
+	b.nodes = append(b.nodes, children...)
+	return b
+}
+
+func (b *Builder) Text(s string) *Builder {
+	return b.Append(Text(s))
+}
+
+// Line adds a line break.
+func (b *Builder) Line() *Builder {
+	return b.Append(Text("\n"))
+}
+
+func (b *Builder) Bold(s string) *Builder {
+	return b.Append(Bold(Text(s)))
+}
+
+func (b *Builder) Italic(s string) *Builder {
+	return b.Append(Italic(Text(s)))
+}
+
+func (b *Builder) Underline(s string) *Builder {
+	return b.Append(Underline(Text(s)))
+}
+
+func (b *Builder) Strikethrough(s string) *Builder {
+	return b.Append(Strikethrough(Text(s)))
+}
+
+func (b *Builder) Spoiler(s string) *Builder {
+	return b.Append(Spoiler(Text(s)))
+}
+
+func (b *Builder) Link(text, url string) *Builder {
+	return b.Append(Link(url, Text(text)))
+}
+
+func (b *Builder) Code(code string) *Builder {
+	return b.Append(Code(code))
+}
+
+func (b *Builder) Pre(code, language string) *Builder {
+	return b.Append(Pre(code, language))
+}
+
+func (b *Builder) Quote(s string) *Builder {
+	return b.Append(Quote(Text(s)))
+}
+
+func (b *Builder) String() string {
+	return Render(b.mode, b.nodes...)
+}
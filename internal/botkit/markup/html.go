@@ -0,0 +1,28 @@
+package markup
+
+import "strings"
+
+// Telegram supports only &lt;, &gt;, &amp; and &quot; named entities.
+var htmlReplacer = strings.NewReplacer(
+	"&",
+	"&amp;",
+	"<",
+	"&lt;",
+	">",
+	"&gt;",
+	`"`,
+	"&quot;",
+)
+
+// EscapeForHTML escapes text and attribute values for the HTML parse mode.
+func EscapeForHTML(src string) string {
+	return htmlReplacer.Replace(src)
+}
+
+// Escape escapes plain text for the given parse mode.
+func Escape(mode, src string) string {
+	if mode == ModeHTML {
+		return EscapeForHTML(src)
+	}
+	return EscapeForMarkdown(src)
+}
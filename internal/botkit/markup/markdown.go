@@ -3,7 +3,10 @@ package markup
 import "strings"
 
 var (
+	// The replacer makes a single pass, the backslashes it inserts are not escaped again.
 	replacer = strings.NewReplacer(
+		"\\",
+		"\\\\",
 		"-",
 		"\\-",
 		"_",
@@ -41,8 +44,35 @@ var (
 		"!",
 		"\\!",
 	)
+
+	// Inside pre and code entities only "`" and "\" have to be escaped.
+	codeReplacer = strings.NewReplacer(
+		"\\",
+		"\\\\",
+		"`",
+		"\\`",
+	)
+
+	// Inside the (...) part of an inline link only ")" and "\" have to be escaped.
+	linkURLReplacer = strings.NewReplacer(
+		"\\",
+		"\\\\",
+		")",
+		"\\)",
+	)
 )
 
+// EscapeForMarkdown escapes text outside of code, pre and link URLs for MarkdownV2.
 func EscapeForMarkdown(src string) string {
 	return replacer.Replace(src)
 }
+
+// EscapeCodeForMarkdown escapes the contents of code and pre entities for MarkdownV2.
+func EscapeCodeForMarkdown(src string) string {
+	return codeReplacer.Replace(src)
+}
+
+// EscapeLinkURLForMarkdown escapes the URL of an inline link for MarkdownV2.
+func EscapeLinkURLForMarkdown(src string) string {
+	return linkURLReplacer.Replace(src)
+}
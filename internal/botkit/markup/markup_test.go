@@ -0,0 +1,240 @@
+package markup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"markdown", markup.EscapeForMarkdown, "Go 1.22 (beta)!", `Go 1\.22 \(beta\)\!`},
+		{"markdown backslash", markup.EscapeForMarkdown, `C:\go\bin`, `C:\\go\\bin`},
+		{"markdown all specials", markup.EscapeForMarkdown, "_*[]()~`>#+-=|{}.!", "\\_\\*\\[\\]\\(\\)\\~\\`\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!"},
+		{"code", markup.EscapeCodeForMarkdown, "fmt.Println(`a\\b`)", "fmt.Println(\\`a\\\\b\\`)"},
+		{"link url", markup.EscapeLinkURLForMarkdown, `https://x.dev/a_(b)\c`, `https://x.dev/a_(b\)\\c`},
+		{"html", markup.EscapeForHTML, `<a href="x">&</a>`, "&lt;a href=&quot;x&quot;&gt;&amp;&lt;/a&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     markup.Node
+		markdown string
+		html     string
+	}{
+		{
+			"text",
+			markup.Text("1 < 2 & 3.5 > 2!"),
+			`1 < 2 & 3\.5 \> 2\!`,
+			"1 &lt; 2 &amp; 3.5 &gt; 2!",
+		},
+		{
+			"bold",
+			markup.Bold(markup.Text("Go 1.22")),
+			`*Go 1\.22*`,
+			"<b>Go 1.22</b>",
+		},
+		{
+			"nested styles",
+			markup.Bold(markup.Text("a "), markup.Italic(markup.Text("b")), markup.Strikethrough(markup.Text("c"))),
+			"*a _b_~c~*",
+			"<b>a <i>b</i><s>c</s></b>",
+		},
+		{
+			"italic underline",
+			markup.Italic(markup.Underline(markup.Text("x"))),
+			"_\r__x__\r_",
+			"<i><u>x</u></i>",
+		},
+		{
+			"underline italic",
+			markup.Underline(markup.Italic(markup.Text("x"))),
+			"__\r_x_\r__",
+			"<u><i>x</i></u>",
+		},
+		{
+			"spoiler",
+			markup.Spoiler(markup.Text("secret")),
+			"||secret||",
+			"<tg-spoiler>secret</tg-spoiler>",
+		},
+		{
+			"link",
+			markup.Link("https://go.dev/doc/go1.22?a=(1)&b=\\", markup.Text("Go [1.22]")),
+			`[Go \[1\.22\]](https://go.dev/doc/go1.22?a=(1\)&b=\\)`,
+			`<a href="https://go.dev/doc/go1.22?a=(1)&amp;b=\">Go [1.22]</a>`,
+		},
+		{
+			"code",
+			markup.Code("x := `a\\b` * 2"),
+			"`x := \\`a\\\\b\\` * 2`",
+			"<code>x := `a\\b` * 2</code>",
+		},
+		{
+			"pre",
+			markup.Pre("if a < b {\n\treturn\n}", "go"),
+			"```go\nif a < b {\n\treturn\n}\n```",
+			`<pre><code class="language-go">if a &lt; b {` + "\n\treturn\n}</code></pre>",
+		},
+		{
+			"quote",
+			markup.Quote(markup.Text("first.\nsecond!")),
+			">first\\.\n>second\\!",
+			"<blockquote>first.\nsecond!</blockquote>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markup.Render(markup.ModeMarkdownV2, tt.node); got != tt.markdown {
+				t.Errorf("MarkdownV2: got %q, want %q", got, tt.markdown)
+			}
+			if err := markup.Validate(tt.markdown, markup.ModeMarkdownV2); err != nil {
+				t.Errorf("MarkdownV2 output is invalid: %v", err)
+			}
+
+			if got := markup.Render(markup.ModeHTML, tt.node); got != tt.html {
+				t.Errorf("HTML: got %q, want %q", got, tt.html)
+			}
+			if err := markup.Validate(tt.html, markup.ModeHTML); err != nil {
+				t.Errorf("HTML output is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := markup.NewBuilder(markup.ModeMarkdownV2)
+	b.Bold("Go 1.22 is released!").Line().Text("Read more: ").Link("go.dev", "https://go.dev")
+
+	want := "*Go 1\\.22 is released\\!*\nRead more: [go\\.dev](https://go.dev)"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if b.Mode() != markup.ModeMarkdownV2 {
+		t.Errorf("unexpected mode %q", b.Mode())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		text  string
+		valid bool
+	}{
+		{"markdown plain", markup.ModeMarkdownV2, "Hello world", true},
+		{"markdown escaped", markup.ModeMarkdownV2, `Go 1\.22\!`, true},
+		{"markdown unescaped dot", markup.ModeMarkdownV2, "Go 1.22", false},
+		{"markdown unescaped dash", markup.ModeMarkdownV2, "range-over-int", false},
+		{"markdown trailing backslash", markup.ModeMarkdownV2, `oops\`, false},
+		{"markdown bold", markup.ModeMarkdownV2, "*bold*", true},
+		{"markdown unclosed bold", markup.ModeMarkdownV2, "*bold", false},
+		{"markdown bad nesting", markup.ModeMarkdownV2, "*a _b* c_", false},
+		{"markdown code with specials", markup.ModeMarkdownV2, "`a.b-c!`", true},
+		{"markdown unclosed code", markup.ModeMarkdownV2, "`a", false},
+		{"markdown pre", markup.ModeMarkdownV2, "```go\nfmt.Println(1)\n```", true},
+		{"markdown link", markup.ModeMarkdownV2, "[go](https://go.dev/a_b.c)", true},
+		{"markdown link with escaped paren", markup.ModeMarkdownV2, `[go](https://go.dev/a\))`, true},
+		{"markdown link without url", markup.ModeMarkdownV2, "[go]", false},
+		{"markdown unclosed link url", markup.ModeMarkdownV2, "[go](https://go.dev", false},
+		{"markdown quote", markup.ModeMarkdownV2, ">quoted\nnot quoted", true},
+		{"markdown gt inside line", markup.ModeMarkdownV2, "a > b", false},
+		{"markdown spoiler", markup.ModeMarkdownV2, "||x||", true},
+		{"markdown underline italic", markup.ModeMarkdownV2, "__\r_x_\r__", true},
+
+		{"html plain", markup.ModeHTML, "Hello world.", true},
+		{"html entities", markup.ModeHTML, "1 &lt; 2 &amp;&amp; 3 &gt; 2 &#128512; &#x1F600;", true},
+		{"html raw ampersand", markup.ModeHTML, "R&D", false},
+		{"html unknown entity", markup.ModeHTML, "&nbsp;", false},
+		{"html raw gt", markup.ModeHTML, "a > b", false},
+		{"html tags", markup.ModeHTML, `<b>a <i>b</i></b> <a href="https://go.dev">go</a>`, true},
+		{"html bad nesting", markup.ModeHTML, "<b><i>x</b></i>", false},
+		{"html unclosed", markup.ModeHTML, "<b>x", false},
+		{"html unsupported tag", markup.ModeHTML, "<div>x</div>", false},
+		{"html span without class", markup.ModeHTML, "<span>x</span>", false},
+		{"html spoiler span", markup.ModeHTML, `<span class="tg-spoiler">x</span>`, true},
+
+		{"unknown mode", "Markdown", "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := markup.Validate(tt.text, tt.mode)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	paragraph := markup.Render(markup.ModeMarkdownV2,
+		markup.Bold(markup.Text(strings.Repeat("Go 1.22 is fast. ", 20))),
+		markup.Text("\n\n"),
+		markup.Link("https://go.dev/(x)", markup.Text("link.")),
+	)
+
+	for _, limit := range []int{40, 100, 500} {
+		messages := markup.Split(paragraph, markup.ModeMarkdownV2, limit)
+		if len(messages) < 2 && limit < markup.Length(paragraph) {
+			t.Fatalf("limit %d: expected several messages, got %d", limit, len(messages))
+		}
+
+		for i, msg := range messages {
+			if l := markup.Length(msg); l > limit {
+				t.Errorf("limit %d: message %d is %d long", limit, i, l)
+			}
+			if err := markup.Validate(msg, markup.ModeMarkdownV2); err != nil {
+				t.Errorf("limit %d: message %d %q is invalid: %v", limit, i, msg, err)
+			}
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		mode  string
+		text  string
+		limit int
+		want  string
+	}{
+		{markup.ModeMarkdownV2, "short", 10, "short"},
+		{markup.ModeMarkdownV2, `*Go 1\.22 is out*`, 12, `*Go 1\.22…*`},
+		{markup.ModeMarkdownV2, `a\.b\.c\.d\.e`, 6, `a\.b…`},
+		{markup.ModeHTML, "<b>Go &amp; Rust are fast</b>", 24, "<b>Go &amp; Rust…</b>"},
+		{markup.ModeHTML, "Tom &amp; Jerry", 9, "Tom…"},
+	}
+
+	for _, tt := range tests {
+		got := markup.Truncate(tt.text, tt.mode, tt.limit)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+		if markup.Length(got) > tt.limit {
+			t.Errorf("Truncate(%q, %d) = %q is too long", tt.text, tt.limit, got)
+		}
+		if err := markup.Validate(got, tt.mode); err != nil {
+			t.Errorf("Truncate(%q, %d) = %q is invalid: %v", tt.text, tt.limit, got, err)
+		}
+	}
+}
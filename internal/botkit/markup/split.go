@@ -0,0 +1,450 @@
+package markup
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Parse modes, the values match the parse_mode parameter of the Bot API.
+const (
+	ModeMarkdownV2 = "MarkdownV2"
+	ModeHTML       = "HTML"
+)
+
+// Telegram limits, measured in UTF-16 code units.
+const (
+	MaxMessageLength = 4096
+	MaxCaptionLength = 1024
+)
+
+const ellipsis = "…"
+
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenSpace
+	tokenNewline
+	tokenOpen  // opens an entity, e.g. "*" or "<b>"
+	tokenClose // closes an entity, e.g. "*" or "</b>"
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// name identifies the entity of open and close tokens: the marker in
+	// MarkdownV2 ("*", "__", "```") and the tag name in HTML ("b", "a").
+	name string
+}
+
+// entity is an entity that is open at some point of a rendered text.
+type entity struct {
+	name  string
+	open  string // text that reopens the entity
+	close string // text that closes the entity
+}
+
+// Length returns the length of s as Telegram counts it.
+func Length(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// Split splits text rendered in the given parse mode into messages of at most limit characters.
+// Texts are split at paragraph, line or word boundaries when possible, never inside escape
+// sequences, HTML entities or tags. Entities open at a split point are closed at the end
+// of a message and reopened at the beginning of the next one.
+func Split(text, mode string, limit int) []string {
+	if Length(text) <= limit {
+		return []string{text}
+	}
+
+	var (
+		tokens   = tokenize(text, mode)
+		messages []string
+	)
+
+	for len(tokens) > 0 {
+		tokens = trimLeadingSpace(tokens)
+
+		var (
+			stack  []entity
+			length int
+			// The best split point for every break weight.
+			cuts [4]cutPoint
+			// At least one token besides reopened entities goes to every message.
+			minTokens = leadingOpens(tokens) + 1
+		)
+
+		i := 0
+		for ; i < len(tokens); i++ {
+			t := tokens[i]
+			next := apply(stack, t)
+
+			if length+Length(t.text)+closingLength(next) > limit && i >= minTokens {
+				break
+			}
+
+			length += Length(t.text)
+			stack = next
+
+			if w := breakWeight(tokens, i); w > 0 && !inLink(stack) {
+				cuts[w] = cutPoint{index: i + 1, stack: stack, length: length}
+			}
+		}
+
+		if i == len(tokens) {
+			messages = append(messages, join(tokens)+closing(stack))
+			break
+		}
+
+		cut := cutPoint{index: i, stack: stack, length: length}
+		if best, ok := bestCut(cuts, limit); ok {
+			cut = best
+		}
+
+		messages = append(messages, join(trimTrailingSpace(tokens[:cut.index]))+closing(cut.stack))
+		tokens = append(opening(cut.stack), tokens[cut.index:]...)
+	}
+
+	return messages
+}
+
+type cutPoint struct {
+	index  int // tokens before index go to the current message
+	stack  []entity
+	length int
+}
+
+// bestCut prefers stronger breaks unless they leave the message less than half full.
+func bestCut(cuts [4]cutPoint, limit int) (cutPoint, bool) {
+	for w := len(cuts) - 1; w > 0; w-- {
+		if cuts[w].index > 0 && cuts[w].length >= limit/2 {
+			return cuts[w], true
+		}
+	}
+
+	var latest cutPoint
+	for _, c := range cuts {
+		if c.index > latest.index {
+			latest = c
+		}
+	}
+	return latest, latest.index > 0
+}
+
+// Truncate cuts text rendered in the given parse mode to at most limit characters,
+// preferring a word boundary, and appends an ellipsis. Open entities are closed.
+func Truncate(text, mode string, limit int) string {
+	if Length(text) <= limit {
+		return text
+	}
+
+	var (
+		tokens   = tokenize(text, mode)
+		stack    []entity
+		length   int
+		reserved = Length(ellipsis)
+		// Split points after any text and before a space.
+		anyCut, wordCut cutPoint
+	)
+
+	for i, t := range tokens {
+		if t.kind == tokenSpace && !inLink(stack) {
+			wordCut = cutPoint{index: i, stack: stack, length: length}
+		}
+
+		next := apply(stack, t)
+		if length+Length(t.text)+closingLength(next)+reserved > limit {
+			break
+		}
+
+		length += Length(t.text)
+		stack = next
+
+		if t.kind == tokenText && !inLink(stack) {
+			anyCut = cutPoint{index: i + 1, stack: stack, length: length}
+		}
+	}
+
+	cut := anyCut
+	if wordCut.index > 0 && wordCut.length >= limit/2 {
+		cut = wordCut
+	}
+
+	return join(trimTrailingSpace(tokens[:cut.index])) + ellipsis + closing(cut.stack)
+}
+
+func tokenize(text, mode string) []token {
+	if mode == ModeHTML {
+		return tokenizeHTML(text)
+	}
+	return tokenizeMarkdownV2(text)
+}
+
+// tokenizeMarkdownV2 splits text into tokens, escape sequences stay within a single token.
+func tokenizeMarkdownV2(text string) []token {
+	var (
+		tokens []token
+		runes  = []rune(text)
+		code   string // "`" or "```" while inside code or pre
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			tokens = append(tokens, token{kind: tokenText, text: string(runes[i : i+2])})
+			i++
+		case r == '`':
+			marker := "`"
+			if strings.HasPrefix(string(runes[i:]), "```") {
+				marker = "```"
+			}
+
+			switch {
+			case code == "":
+				code = marker
+				tokens = append(tokens, token{kind: tokenOpen, text: marker, name: marker})
+			case code == marker:
+				code = ""
+				tokens = append(tokens, token{kind: tokenClose, text: marker, name: marker})
+			default:
+				tokens = append(tokens, token{kind: tokenText, text: marker})
+			}
+			i += len(marker) - 1
+		case code != "":
+			tokens = append(tokens, plainToken(r))
+		case r == '*' || r == '~':
+			tokens = append(tokens, token{kind: tokenOpen, text: string(r), name: string(r)})
+		case r == '_' || r == '|':
+			marker := string(r)
+			if i+1 < len(runes) && runes[i+1] == r {
+				marker += string(r)
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenOpen, text: marker, name: marker})
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenOpen, text: "[", name: "["})
+		case r == ']' && i+1 < len(runes) && runes[i+1] == '(':
+			// "](url)" closes a link, the url is never split.
+			end := i + 2
+			for end < len(runes) && runes[end] != ')' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			tokens = append(tokens, token{kind: tokenClose, text: string(runes[i:end]), name: "["})
+			i = end - 1
+		default:
+			tokens = append(tokens, plainToken(r))
+		}
+	}
+
+	// Markers are toggles in MarkdownV2, the second occurrence closes the entity.
+	var open []string
+	for i, t := range tokens {
+		if t.kind != tokenOpen && t.kind != tokenClose {
+			continue
+		}
+		if t.name == "[" {
+			continue
+		}
+
+		if idx := lastIndex(open, t.name); idx >= 0 {
+			tokens[i].kind = tokenClose
+			open = append(open[:idx], open[idx+1:]...)
+		} else {
+			tokens[i].kind = tokenOpen
+			open = append(open, t.name)
+		}
+	}
+
+	return tokens
+}
+
+func tokenizeHTML(text string) []token {
+	var tokens []token
+
+	for len(text) > 0 {
+		switch text[0] {
+		case '<':
+			end := strings.IndexByte(text, '>')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			tag := text[:end+1]
+			name := tagName(tag)
+
+			kind := tokenOpen
+			if strings.HasPrefix(tag, "</") {
+				kind = tokenClose
+			}
+			tokens = append(tokens, token{kind: kind, text: tag, name: name})
+			text = text[end+1:]
+		case '&':
+			end := strings.IndexByte(text, ';')
+			if end < 0 || strings.ContainsAny(text[1:end], " \n<&") {
+				end = 0
+			}
+			tokens = append(tokens, token{kind: tokenText, text: text[:end+1]})
+			text = text[end+1:]
+		default:
+			r, size := utf8.DecodeRuneInString(text)
+			tokens = append(tokens, plainToken(r))
+			text = text[size:]
+		}
+	}
+
+	return tokens
+}
+
+func tagName(tag string) string {
+	name := strings.TrimLeft(tag, "</")
+	if end := strings.IndexAny(name, " >/"); end >= 0 {
+		name = name[:end]
+	}
+	return strings.ToLower(name)
+}
+
+func plainToken(r rune) token {
+	switch r {
+	case ' ', '\t':
+		return token{kind: tokenSpace, text: string(r)}
+	case '\n':
+		return token{kind: tokenNewline, text: string(r)}
+	default:
+		return token{kind: tokenText, text: string(r)}
+	}
+}
+
+// apply returns the stack of open entities after the token.
+func apply(stack []entity, t token) []entity {
+	switch t.kind {
+	case tokenOpen:
+		next := make([]entity, len(stack), len(stack)+1)
+		copy(next, stack)
+		return append(next, entity{name: t.name, open: t.text, close: closerFor(t)})
+	case tokenClose:
+		idx := -1
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].name == t.name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return stack
+		}
+		next := make([]entity, 0, len(stack)-1)
+		next = append(next, stack[:idx]...)
+		return append(next, stack[idx+1:]...)
+	default:
+		return stack
+	}
+}
+
+func closerFor(t token) string {
+	if strings.HasPrefix(t.text, "<") {
+		return "</" + t.name + ">"
+	}
+	return t.text
+}
+
+func inLink(stack []entity) bool {
+	for _, e := range stack {
+		if e.name == "[" {
+			return true
+		}
+	}
+	return false
+}
+
+// breakWeight rates the position after tokens[i] as a split point:
+// paragraph breaks are preferred to line breaks and line breaks to spaces.
+func breakWeight(tokens []token, i int) int {
+	switch tokens[i].kind {
+	case tokenNewline:
+		if i > 0 && tokens[i-1].kind == tokenNewline {
+			return 3
+		}
+		return 2
+	case tokenSpace:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func closing(stack []entity) string {
+	var sb strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		sb.WriteString(stack[i].close)
+	}
+	return sb.String()
+}
+
+func closingLength(stack []entity) int {
+	return Length(closing(stack))
+}
+
+func opening(stack []entity) []token {
+	tokens := make([]token, 0, len(stack))
+	for _, e := range stack {
+		tokens = append(tokens, token{kind: tokenOpen, text: e.open, name: e.name})
+	}
+	return tokens
+}
+
+func leadingOpens(tokens []token) int {
+	n := 0
+	for n < len(tokens) && tokens[n].kind == tokenOpen {
+		n++
+	}
+	return n
+}
+
+func trimTrailingSpace(tokens []token) []token {
+	for len(tokens) > 0 && (tokens[len(tokens)-1].kind == tokenSpace || tokens[len(tokens)-1].kind == tokenNewline) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// trimLeadingSpace drops whitespace at the start of a message, keeping reopened entities.
+func trimLeadingSpace(tokens []token) []token {
+	var (
+		opens []token
+		i     int
+	)
+	for ; i < len(tokens); i++ {
+		switch tokens[i].kind {
+		case tokenOpen:
+			opens = append(opens, tokens[i])
+		case tokenSpace, tokenNewline:
+		default:
+			return append(opens, tokens[i:]...)
+		}
+	}
+	return opens
+}
+
+func join(tokens []token) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+func lastIndex(s []string, v string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,257 @@
+package markup
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// markdownSpecial are the characters that must be escaped in MarkdownV2 unless they are markup.
+const markdownSpecial = "_*[]()~`>#+-=|{}.!"
+
+// Validate checks that text rendered in the given parse mode would be accepted by Telegram:
+// special characters are escaped, entities are well formed, properly nested and closed.
+// It does not check message length limits.
+func Validate(text, mode string) error {
+	switch mode {
+	case ModeMarkdownV2:
+		return validateMarkdownV2(text)
+	case ModeHTML:
+		return validateHTML(text)
+	default:
+		return fmt.Errorf("unsupported parse mode %q", mode)
+	}
+}
+
+// SyntaxError describes a problem in formatted text, Offset is a byte offset.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("can't parse entities at byte offset %d: %s", e.Offset, e.Msg)
+}
+
+func syntaxError(offset int, format string, args ...any) error {
+	return &SyntaxError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+type openEntity struct {
+	name   string
+	offset int
+}
+
+func validateMarkdownV2(text string) error {
+	var (
+		stack       []openEntity
+		lineStart   = true
+		inLinkText  = false
+		linkTextPos int
+	)
+
+	top := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].name
+	}
+
+	// toggle opens the entity or closes it if it is the innermost open one.
+	toggle := func(name string, offset int) error {
+		if top() == name {
+			stack = stack[:len(stack)-1]
+			return nil
+		}
+		for _, e := range stack {
+			if e.name == name {
+				return syntaxError(offset, "entity %q closed before the inner entity %q", name, top())
+			}
+		}
+		stack = append(stack, openEntity{name: name, offset: offset})
+		return nil
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		wasLineStart := lineStart
+		lineStart = r == '\n'
+
+		code := top() == "`" || top() == "```"
+
+		switch {
+		case r == '\\':
+			if i+1 >= len(text) {
+				return syntaxError(i, "backslash at the end of the text")
+			}
+			_, next := utf8.DecodeRuneInString(text[i+1:])
+			i += 1 + next
+			continue
+		case code && strings.HasPrefix(text[i:], top()):
+			stack = stack[:len(stack)-1]
+			if strings.HasPrefix(text[i:], "```") {
+				i += 3
+			} else {
+				i++
+			}
+			continue
+		case code:
+			i += size
+			continue
+		case strings.HasPrefix(text[i:], "```"):
+			stack = append(stack, openEntity{name: "```", offset: i})
+			i += 3
+			// The language of the pre block follows the opening marker on the same line.
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end + 1
+			}
+			continue
+		case r == '`':
+			stack = append(stack, openEntity{name: "`", offset: i})
+		case strings.HasPrefix(text[i:], "__"), strings.HasPrefix(text[i:], "||"):
+			if err := toggle(text[i:i+2], i); err != nil {
+				return err
+			}
+			i += 2
+			continue
+		case r == '*' || r == '_' || r == '~':
+			if err := toggle(string(r), i); err != nil {
+				return err
+			}
+		case r == '[':
+			if inLinkText {
+				return syntaxError(i, "nested links are not allowed")
+			}
+			inLinkText, linkTextPos = true, i
+			stack = append(stack, openEntity{name: "[", offset: i})
+		case r == ']':
+			if !inLinkText || top() != "[" {
+				return syntaxError(i, "character ']' is reserved and must be escaped")
+			}
+			inLinkText = false
+			stack = stack[:len(stack)-1]
+
+			if !strings.HasPrefix(text[i+1:], "(") {
+				// "[text]" without an URL is plain text in Telegram, but only if escaped.
+				return syntaxError(linkTextPos, "link text is not followed by an URL")
+			}
+
+			end, err := linkURLEnd(text, i+2)
+			if err != nil {
+				return err
+			}
+			i = end + 1
+			continue
+		case r == '>' && wasLineStart:
+			// Block quotation.
+		case strings.ContainsRune(markdownSpecial, r):
+			return syntaxError(i, "character %q is reserved and must be escaped with the preceding '\\'", r)
+		}
+
+		i += size
+	}
+
+	if len(stack) > 0 {
+		e := stack[len(stack)-1]
+		return syntaxError(e.offset, "entity %q is not closed", e.name)
+	}
+
+	return nil
+}
+
+// linkURLEnd returns the offset of the ")" closing the link URL that starts at offset start.
+func linkURLEnd(text string, start int) (int, error) {
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case ')':
+			if i == start {
+				return 0, syntaxError(i, "empty link URL")
+			}
+			return i, nil
+		}
+	}
+	return 0, syntaxError(start, "link URL is not closed")
+}
+
+// Tags supported by Telegram and their aliases.
+var htmlTags = map[string]bool{
+	"b": true, "strong": true,
+	"i": true, "em": true,
+	"u": true, "ins": true,
+	"s": true, "strike": true, "del": true,
+	"span": true, "tg-spoiler": true,
+	"a": true, "code": true, "pre": true,
+	"blockquote": true, "tg-emoji": true,
+}
+
+func validateHTML(text string) error {
+	var stack []openEntity
+
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			end := strings.IndexByte(text[i:], '>')
+			if end < 0 {
+				return syntaxError(i, "unclosed start tag")
+			}
+			tag := text[i : i+end+1]
+
+			if strings.HasPrefix(tag, "</") {
+				name := tagName(tag)
+				if len(stack) == 0 || stack[len(stack)-1].name != name {
+					return syntaxError(i, "unmatched end tag %q", name)
+				}
+				stack = stack[:len(stack)-1]
+			} else {
+				name := tagName(tag)
+				if !htmlTags[name] {
+					return syntaxError(i, "unsupported start tag %q", name)
+				}
+				if name == "span" && !strings.Contains(tag, `class="tg-spoiler"`) {
+					return syntaxError(i, `tag "span" must have class "tg-spoiler"`)
+				}
+				if name == "a" && !strings.Contains(tag, "href=") {
+					return syntaxError(i, `tag "a" must have the href attribute`)
+				}
+				stack = append(stack, openEntity{name: name, offset: i})
+			}
+			i += end + 1
+		case '>':
+			return syntaxError(i, "character '>' must be replaced with &gt;")
+		case '&':
+			end := strings.IndexByte(text[i:], ';')
+			if end < 0 || !validHTMLEntity(text[i+1:i+end]) {
+				return syntaxError(i, "character '&' must start an HTML entity, use &amp;")
+			}
+			i += end + 1
+		default:
+			i++
+		}
+	}
+
+	if len(stack) > 0 {
+		e := stack[len(stack)-1]
+		return syntaxError(e.offset, "tag %q is not closed", e.name)
+	}
+
+	return nil
+}
+
+func validHTMLEntity(name string) bool {
+	switch name {
+	case "lt", "gt", "amp", "quot":
+		return true
+	}
+
+	digits := strings.TrimPrefix(name, "#")
+	if digits == name || digits == "" {
+		return false
+	}
+
+	if hex := strings.TrimPrefix(strings.ToLower(digits), "x"); hex != strings.ToLower(digits) {
+		return hex != "" && strings.Trim(hex, "0123456789abcdef") == ""
+	}
+	return strings.Trim(digits, "0123456789") == ""
+}
@@ -0,0 +1,134 @@
+package botkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Request is a Bot API call. Unlike tgbotapi.Chattable it can carry parameters
+// the library doesn't support yet, e.g. link_preview_options.
+type Request struct {
+	Method string
+	Params tgbotapi.Params
+
+	// HasFallback is set when the sender sends other requests instead if this one fails,
+	// so it isn't kept as undeliverable.
+	HasFallback bool
+}
+
+// LinkPreview controls the link preview of a text message.
+type LinkPreview struct {
+	IsDisabled       bool   `json:"is_disabled,omitempty"`
+	URL              string `json:"url,omitempty"`
+	PreferSmallMedia bool   `json:"prefer_small_media,omitempty"`
+	PreferLargeMedia bool   `json:"prefer_large_media,omitempty"`
+	ShowAboveText    bool   `json:"show_above_text,omitempty"`
+}
+
+func NewTextRequest(chatID int64, text, parseMode string) Request {
+	params := tgbotapi.Params{
+		"chat_id": strconv.FormatInt(chatID, 10),
+		"text":    text,
+	}
+	params.AddNonEmpty("parse_mode", parseMode)
+
+	return Request{Method: "sendMessage", Params: params}
+}
+
+// NewPhotoRequest sends the photo at photoURL, Telegram downloads it itself.
+func NewPhotoRequest(chatID int64, photoURL, caption, parseMode string) Request {
+	params := tgbotapi.Params{
+		"chat_id": strconv.FormatInt(chatID, 10),
+		"photo":   photoURL,
+	}
+	params.AddNonEmpty("caption", caption)
+	params.AddNonEmpty("parse_mode", parseMode)
+
+	return Request{Method: "sendPhoto", Params: params}
+}
+
+// NewEditTextRequest replaces the text of a sent message.
+func NewEditTextRequest(chatID int64, messageID int, text, parseMode string) Request {
+	params := tgbotapi.Params{
+		"chat_id":    strconv.FormatInt(chatID, 10),
+		"message_id": strconv.Itoa(messageID),
+		"text":       text,
+	}
+	params.AddNonEmpty("parse_mode", parseMode)
+
+	return Request{Method: "editMessageText", Params: params}
+}
+
+// NewEditCaptionRequest replaces the caption of a sent photo.
+func NewEditCaptionRequest(chatID int64, messageID int, caption, parseMode string) Request {
+	params := tgbotapi.Params{
+		"chat_id":    strconv.FormatInt(chatID, 10),
+		"message_id": strconv.Itoa(messageID),
+		"caption":    caption,
+	}
+	params.AddNonEmpty("parse_mode", parseMode)
+
+	return Request{Method: "editMessageCaption", Params: params}
+}
+
+func NewDeleteRequest(chatID int64, messageID int) Request {
+	return Request{Method: "deleteMessage", Params: tgbotapi.Params{
+		"chat_id":    strconv.FormatInt(chatID, 10),
+		"message_id": strconv.Itoa(messageID),
+	}}
+}
+
+func (r Request) WithLinkPreview(preview LinkPreview) Request {
+	if preview == (LinkPreview{}) {
+		return r
+	}
+
+	// The options only contain basic types, marshalling can't fail.
+	_ = r.Params.AddInterface("link_preview_options", preview)
+	return r
+}
+
+func (r Request) WithReplyMarkup(markup tgbotapi.InlineKeyboardMarkup) Request {
+	if len(markup.InlineKeyboard) == 0 {
+		return r
+	}
+
+	_ = r.Params.AddInterface("reply_markup", markup)
+	return r
+}
+
+// WithFallback marks the request as one the sender replaces by others if it fails.
+func (r Request) WithFallback() Request {
+	r.HasFallback = true
+	return r
+}
+
+// ChatID returns the chat the request is sent to.
+func (r Request) ChatID() int64 {
+	id, _ := strconv.ParseInt(r.Params["chat_id"], 10, 64)
+	return id
+}
+
+// Send makes the request and decodes the sent message. Methods that return true instead of
+// a message, e.g. deleteMessage, return an empty message.
+func Send(api *tgbotapi.BotAPI, r Request) (tgbotapi.Message, error) {
+	resp, err := api.MakeRequest(r.Method, r.Params)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	if !strings.HasPrefix(string(resp.Result), "{") {
+		return tgbotapi.Message{}, nil
+	}
+
+	var msg tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &msg); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("failed to decode %s response: %w", r.Method, err)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,88 @@
+package botkit_test
+
+import (
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestTextRequest(t *testing.T) {
+	req := botkit.NewTextRequest(-100123, "hello", "HTML").
+		WithLinkPreview(botkit.LinkPreview{URL: "https://go.dev/x.png", PreferLargeMedia: true}).
+		WithReplyMarkup(tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("Read more", "https://go.dev")),
+		))
+
+	if req.Method != "sendMessage" {
+		t.Errorf("unexpected method %q", req.Method)
+	}
+	if req.ChatID() != -100123 {
+		t.Errorf("unexpected chat id %d", req.ChatID())
+	}
+
+	want := map[string]string{
+		"text":                 "hello",
+		"parse_mode":           "HTML",
+		"link_preview_options": `{"url":"https://go.dev/x.png","prefer_large_media":true}`,
+		"reply_markup":         `{"inline_keyboard":[[{"text":"Read more","url":"https://go.dev"}]]}`,
+	}
+	for key, value := range want {
+		if got := req.Params[key]; got != value {
+			t.Errorf("%s: got %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestPhotoRequestWithoutOptions(t *testing.T) {
+	req := botkit.NewPhotoRequest(1, "https://go.dev/x.png", "", "").
+		WithLinkPreview(botkit.LinkPreview{}).
+		WithReplyMarkup(tgbotapi.InlineKeyboardMarkup{})
+
+	if req.Method != "sendPhoto" {
+		t.Errorf("unexpected method %q", req.Method)
+	}
+	for _, key := range []string{"caption", "parse_mode", "link_preview_options", "reply_markup"} {
+		if _, ok := req.Params[key]; ok {
+			t.Errorf("unexpected parameter %s", key)
+		}
+	}
+}
+
+func TestEditAndDeleteRequests(t *testing.T) {
+	tests := []struct {
+		req    botkit.Request
+		method string
+		want   map[string]string
+	}{
+		{
+			req:    botkit.NewEditTextRequest(-100123, 42, "edited", "MarkdownV2"),
+			method: "editMessageText",
+			want:   map[string]string{"chat_id": "-100123", "message_id": "42", "text": "edited", "parse_mode": "MarkdownV2"},
+		},
+		{
+			req:    botkit.NewEditCaptionRequest(-100123, 42, "caption", ""),
+			method: "editMessageCaption",
+			want:   map[string]string{"chat_id": "-100123", "message_id": "42", "caption": "caption"},
+		},
+		{
+			req:    botkit.NewDeleteRequest(-100123, 42),
+			method: "deleteMessage",
+			want:   map[string]string{"chat_id": "-100123", "message_id": "42"},
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.req.Method != tt.method {
+			t.Errorf("got method %q, want %q", tt.req.Method, tt.method)
+		}
+		if len(tt.req.Params) != len(tt.want) {
+			t.Errorf("%s: got params %v, want %v", tt.method, tt.req.Params, tt.want)
+		}
+		for key, value := range tt.want {
+			if got := tt.req.Params[key]; got != value {
+				t.Errorf("%s %s: got %q, want %q", tt.method, key, got, value)
+			}
+		}
+	}
+}
@@ -1,67 +1,202 @@
+// Package config loads the configuration of the bot. Every setting has a default and can be
+// set in an HCL config file, by an environment variable and by a command line flag, each
+// overriding the previous ones.
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
-	"strconv"
-	"sync"
+	"slices"
 	"time"
 )
 
 type Config struct {
 	TelegramBotToken     string
 	TelegramChannelID    int64
+	TelegramChannelLang  string
+	ModerationChatID     int64
+	ModerateChannel      bool
+	TelegramChannelTags  string
 	DatabaseDSN          string
 	FetchInterval        time.Duration
 	NotificationInterval time.Duration
 	LookupTimeWindow     time.Duration
+	ClaimLease           time.Duration
 	OpenAIKey            string
 	OpenAIPrompt         string
 	OpenAIModel          string
+	SummaryMaxSentences  int
+	RankKeywords         map[string]float64
+	RankHalfLife         time.Duration
+	FilterKeywords       []string
+	AdminIDs             []int64
+	DeleteRetractedPosts bool
+	ShutdownTimeout      time.Duration
+
+	// values are the raw values of the settings by name.
+	values map[string]Value
 }
 
-var (
-	cfg  *Config
-	once sync.Once
-)
+// Value is the raw value of a setting and where it comes from.
+type Value struct {
+	Setting string
+	Value   string
+	Source  string // "default", the config file path, "env" or "flag"
+	Secret  bool
+}
+
+// ConfigFileEnv is the environment variable with the path of the config file, which can also
+// be set with the -config flag. The config file is optional.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load loads the configuration, args are the command line flags. The error lists every
+// invalid or missing setting.
+func Load(args []string) (*Config, error) {
+	flags := NewFlagSet("news-feed-bot")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return flags.Load()
+}
+
+// FlagSet has the -config flag and a flag for every setting. Subcommands add their own
+// flags to it.
+type FlagSet struct {
+	*flag.FlagSet
+	configFile *string
+	values     map[string]*string
+}
+
+func NewFlagSet(name string) *FlagSet {
+	flags := &FlagSet{
+		FlagSet: flag.NewFlagSet(name, flag.ContinueOnError),
+		values:  make(map[string]*string, len(settings)),
+	}
+
+	flags.configFile = flags.String("config", "", "path of the HCL config file, or $"+ConfigFileEnv)
+	for _, s := range settings {
+		flags.values[s.name] = flags.String(s.flag(), "", s.usage+", or $"+s.env)
+	}
+	return flags
+}
+
+// Load loads the configuration after the flags are parsed. Only the named settings are
+// checked, e.g. the ones a subcommand uses, or all of them if none are given.
+func (f *FlagSet) Load(names ...string) (*Config, error) {
+	checked := func(name string) bool {
+		return len(names) == 0 || slices.Contains(names, name)
+	}
+
+	setFlags := make(map[string]bool)
+	f.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	path := *f.configFile
+	if path == "" {
+		path, _ = os.LookupEnv(ConfigFileEnv)
+	}
+
+	var errs []error
+	fileValues := make(map[string]string)
+	if path != "" {
+		var err error
+		if fileValues, err = readFile(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-func Load() *Config {
-	once.Do(func() {
-
-		// Load from .env file
-		// err := godotenv.Load("../.env")
-		// if err != nil {
-		// 	log.Println("[WARN] .env file not found, falling back to system env vars")
-		// }
-
-		// Or load from docker
-
-		// env is always string
-		channelID, _ := strconv.ParseInt(os.Getenv("TELEGRAM_CHANNEL_ID"), 10, 64)
-		fetchInterval, _ := time.ParseDuration(os.Getenv("FETCH_INTERVAL"))
-		notifyInterval, _ := time.ParseDuration(os.Getenv("NOTIFICATION_INTERVAL"))
-		lookupTimeWindow, _ := time.ParseDuration(os.Getenv("LOOK_UP_TIME_WINDOW"))
-
-		cfg = &Config{
-			TelegramBotToken:     mustGet("TELEGRAM_BOT_TOKEN"),
-			TelegramChannelID:    channelID,
-			DatabaseDSN:          os.Getenv("DATABASE_DSN"),
-			FetchInterval:        fetchInterval,
-			NotificationInterval: notifyInterval,
-			LookupTimeWindow:     lookupTimeWindow,
-			OpenAIKey:            mustGet("OPENAI_KEY"),
-			OpenAIPrompt:         os.Getenv("OPENAI_PROMPT"),
-			OpenAIModel:          os.Getenv("OPENAI_MODEL"),
+	c := &Config{values: make(map[string]Value, len(settings))}
+	invalid := make(map[string]bool)
+	for _, s := range settings {
+		value := Value{Setting: s.name, Value: s.value, Source: "default", Secret: s.secret}
+		if v, ok := fileValues[s.name]; ok {
+			value.Value, value.Source = v, path
+		}
+		// Compose sets unset variables to empty strings, so they don't override the file.
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			value.Value, value.Source = v, "env"
+		}
+		if setFlags[s.flag()] {
+			value.Value, value.Source = *f.values[s.name], "flag"
 		}
-	})
+		c.values[s.name] = value
 
-	return cfg
+		if err := s.parse(c, value.Value); err != nil && checked(s.name) {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", s.name, value.source(s), err))
+			invalid[s.name] = true
+		}
+	}
+
+	for _, s := range settings {
+		if s.validate == nil || invalid[s.name] || !checked(s.name) {
+			continue
+		}
+		if err := s.validate(c); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", s.name, c.values[s.name].source(s), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// source describes where the value comes from for error messages.
+func (v Value) source(s setting) string {
+	switch v.Source {
+	case "default":
+		if v.Value == "" {
+			return "not set"
+		}
+		return "default"
+	case "env":
+		return "$" + s.env
+	case "flag":
+		return "-" + s.flag()
+	default:
+		return v.Source
+	}
 }
 
-func mustGet(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("Environment variable %s is required but not set", key)
+// Values returns the raw values of the settings in the order they are documented.
+func (c *Config) Values() []Value {
+	values := make([]Value, 0, len(settings))
+	for _, s := range settings {
+		values = append(values, c.values[s.name])
+	}
+	return values
+}
+
+// Restart returns the names of the settings that differ in next and can't be changed without
+// a restart, see Reloadable.
+func (c *Config) Restart(next *Config) []string {
+	var names []string
+	for _, s := range settings {
+		if !s.reloadable && c.values[s.name].Value != next.values[s.name].Value {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// Names returns the names of all settings.
+func Names() []string {
+	names := make([]string, 0, len(settings))
+	for _, s := range settings {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// Reloadable returns the names of the settings applied on reload.
+func Reloadable() []string {
+	var names []string
+	for _, s := range settings {
+		if s.reloadable {
+			names = append(names, s.name)
+		}
 	}
-	return val
+	return names
 }
@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/config"
+)
+
+func setRequired(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_CHANNEL_ID", "-100123")
+	t.Setenv("DATABASE_DSN", "sqlite:news.db")
+	t.Setenv("OPENAI_KEY", "key")
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Layers(t *testing.T) {
+	setRequired(t)
+	path := writeFile(t, `
+fetch_interval = "5m"
+notification_interval = "2h"
+summary_max_sentences = 4
+admin_ids = [1, 2]
+rank_keywords {
+  generics = 1.5
+}
+`)
+	t.Setenv("NOTIFICATION_INTERVAL", "3h")
+	t.Setenv("SUMMARY_MAX_SENTENCES", "") // empty variables don't override the file
+
+	cfg, err := config.Load([]string{"-config", path, "-summary-max-sentences", "5", "-filter-keywords", "leetcode, crypto"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.FetchInterval != 5*time.Minute {
+		t.Errorf("FetchInterval = %v, want 5m from the file", cfg.FetchInterval)
+	}
+	if cfg.NotificationInterval != 3*time.Hour {
+		t.Errorf("NotificationInterval = %v, want 3h from the environment", cfg.NotificationInterval)
+	}
+	if cfg.SummaryMaxSentences != 5 {
+		t.Errorf("SummaryMaxSentences = %d, want 5 from the flag", cfg.SummaryMaxSentences)
+	}
+	if cfg.LookupTimeWindow != 24*time.Hour {
+		t.Errorf("LookupTimeWindow = %v, want the default 24h", cfg.LookupTimeWindow)
+	}
+	if !slices.Equal(cfg.AdminIDs, []int64{1, 2}) {
+		t.Errorf("AdminIDs = %v, want [1 2]", cfg.AdminIDs)
+	}
+	if cfg.RankKeywords["generics"] != 1.5 {
+		t.Errorf("RankKeywords = %v, want generics:1.5", cfg.RankKeywords)
+	}
+	if !slices.Equal(cfg.FilterKeywords, []string{"leetcode", "crypto"}) {
+		t.Errorf("FilterKeywords = %q, want leetcode and crypto", cfg.FilterKeywords)
+	}
+}
+
+func TestLoad_ListsEveryError(t *testing.T) {
+	t.Setenv("TELEGRAM_BOT_TOKEN", "")
+	t.Setenv("TELEGRAM_CHANNEL_ID", "-100123")
+	t.Setenv("DATABASE_DSN", "sqlite:news.db")
+	t.Setenv("OPENAI_KEY", "key")
+	t.Setenv("FETCH_INTERVAL", "10")
+	t.Setenv("RANK_HALF_LIFE", "-1h")
+	path := writeFile(t, `fetch_intreval = "5m"`)
+
+	_, err := config.Load([]string{"-config", path, "-admin-ids", "1,x"})
+	if err == nil {
+		t.Fatal("Load() error = nil")
+	}
+
+	for _, want := range []string{
+		"fetch_intreval (" + path + "): unknown setting",
+		"telegram_bot_token (not set): is required",
+		`fetch_interval ($FETCH_INTERVAL): invalid duration "10"`,
+		`admin_ids (-admin-ids): invalid ID "x"`,
+		"rank_half_life ($RANK_HALF_LIFE): must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestConfig_Restart(t *testing.T) {
+	setRequired(t)
+
+	started, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	next, err := config.Load([]string{"-fetch-interval", "1m", "-database-dsn", "sqlite:other.db"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if names := started.Restart(next); !slices.Equal(names, []string{"database_dsn"}) {
+		t.Errorf("Restart() = %v, want only database_dsn", names)
+	}
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/hcl"
+)
+
+// setting is a configuration setting, its name is the key in the config file.
+//
+// Settings are a table rather than struct tags read by a library such as aconfig: besides
+// the sources of a value, config check and SIGHUP reloads need to know which settings are
+// secret, which ones can be reloaded and where every value came from, and validation can
+// depend on other settings.
+type setting struct {
+	name       string
+	env        string
+	usage      string
+	value      string // the default
+	secret     bool   // masked by config check
+	reloadable bool   // applied on SIGHUP, others need a restart
+	parse      func(c *Config, value string) error
+	validate   func(c *Config) error
+}
+
+// flag returns the name of the command line flag of the setting, e.g. -fetch-interval.
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.name, "_", "-")
+}
+
+var settings = []setting{
+	{
+		name: "telegram_bot_token", env: "TELEGRAM_BOT_TOKEN", usage: "token of the Telegram bot", secret: true,
+		parse:    str(func(c *Config) *string { return &c.TelegramBotToken }),
+		validate: func(c *Config) error { return required(c.TelegramBotToken != "") },
+	},
+	{
+		name: "telegram_channel_id", env: "TELEGRAM_CHANNEL_ID", usage: "ID of the Telegram channel to post to",
+		parse:    integer(func(c *Config) *int64 { return &c.TelegramChannelID }),
+		validate: func(c *Config) error { return required(c.TelegramChannelID != 0) },
+	},
+	{
+		name: "telegram_channel_language", env: "TELEGRAM_CHANNEL_LANGUAGE", usage: "language of the channel, empty to keep the language of articles",
+		parse: str(func(c *Config) *string { return &c.TelegramChannelLang }),
+	},
+	{
+		name: "telegram_channel_moderated", env: "TELEGRAM_CHANNEL_MODERATED", usage: "post to the channel only articles approved by admins", value: "false",
+		parse: boolean(func(c *Config) *bool { return &c.ModerateChannel }),
+	},
+	{
+		name: "telegram_channel_tags", env: "TELEGRAM_CHANNEL_TAGS", usage: `comma separated source tags routed to the channel, e.g. "go,-jobs", empty for all sources`,
+		parse: str(func(c *Config) *string { return &c.TelegramChannelTags }),
+	},
+	{
+		name: "moderation_chat_id", env: "MODERATION_CHAT_ID", usage: "ID of the chat articles are sent to for moderation", value: "0",
+		parse: integer(func(c *Config) *int64 { return &c.ModerationChatID }),
+		validate: func(c *Config) error {
+			if c.ModerateChannel && c.ModerationChatID == 0 {
+				return errors.New("is required to moderate the channel")
+			}
+			return nil
+		},
+	},
+	{
+		name: "admin_ids", env: "ADMIN_IDS", usage: "comma separated IDs of the Telegram users allowed to use admin commands",
+		parse: func(c *Config, value string) (err error) {
+			c.AdminIDs, err = parseIDs(value)
+			return err
+		},
+	},
+	{
+		name: "database_dsn", env: "DATABASE_DSN", usage: "PostgreSQL URL or sqlite:<path>", secret: true,
+		parse:    str(func(c *Config) *string { return &c.DatabaseDSN }),
+		validate: func(c *Config) error { return required(c.DatabaseDSN != "") },
+	},
+	{
+		name: "fetch_interval", env: "FETCH_INTERVAL", usage: "how often sources are fetched", value: "10m", reloadable: true,
+		parse:    duration(func(c *Config) *time.Duration { return &c.FetchInterval }),
+		validate: func(c *Config) error { return positive(c.FetchInterval) },
+	},
+	{
+		name: "filter_keywords", env: "FILTER_KEYWORDS", usage: "comma separated keywords of articles that are skipped", value: "leetcode", reloadable: true,
+		parse: func(c *Config, value string) error {
+			c.FilterKeywords = parseList(value)
+			return nil
+		},
+	},
+	{
+		name: "notification_interval", env: "NOTIFICATION_INTERVAL", usage: "minimum interval between posts to a chat", value: "1h", reloadable: true,
+		parse:    duration(func(c *Config) *time.Duration { return &c.NotificationInterval }),
+		validate: func(c *Config) error { return positive(c.NotificationInterval) },
+	},
+	{
+		name: "look_up_time_window", env: "LOOK_UP_TIME_WINDOW", usage: "how old articles can be to be posted", value: "24h", reloadable: true,
+		parse:    duration(func(c *Config) *time.Duration { return &c.LookupTimeWindow }),
+		validate: func(c *Config) error { return positive(c.LookupTimeWindow) },
+	},
+	{
+		name: "notification_claim_lease", env: "NOTIFICATION_CLAIM_LEASE", usage: "how long other instances wait for a crashed one's posts", value: "10m",
+		parse:    duration(func(c *Config) *time.Duration { return &c.ClaimLease }),
+		validate: func(c *Config) error { return positive(c.ClaimLease) },
+	},
+	{
+		name: "delete_retracted_posts", env: "DELETE_RETRACTED_POSTS", usage: "delete posts of articles removed from their feed", value: "false", reloadable: true,
+		parse: boolean(func(c *Config) *bool { return &c.DeleteRetractedPosts }),
+	},
+	{
+		name: "openai_key", env: "OPENAI_KEY", usage: "OpenAI API key", secret: true,
+		parse:    str(func(c *Config) *string { return &c.OpenAIKey }),
+		validate: func(c *Config) error { return required(c.OpenAIKey != "") },
+	},
+	{
+		name: "openai_model", env: "OPENAI_MODEL", usage: "OpenAI model summarizing articles", value: "gpt-4o-mini", reloadable: true,
+		parse:    str(func(c *Config) *string { return &c.OpenAIModel }),
+		validate: func(c *Config) error { return required(c.OpenAIModel != "") },
+	},
+	{
+		name: "openai_prompt", env: "OPENAI_PROMPT", usage: "text/template of the summary prompt, empty for the default one", reloadable: true,
+		parse: func(c *Config, value string) error {
+			if _, err := template.New("prompt").Parse(value); err != nil {
+				return fmt.Errorf("invalid template: %w", err)
+			}
+			c.OpenAIPrompt = value
+			return nil
+		},
+	},
+	{
+		name: "summary_max_sentences", env: "SUMMARY_MAX_SENTENCES", usage: "maximum number of sentences of summaries", value: "3", reloadable: true,
+		parse: func(c *Config, value string) (err error) {
+			c.SummaryMaxSentences, err = strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", value)
+			}
+			return nil
+		},
+		validate: func(c *Config) error {
+			if c.SummaryMaxSentences <= 0 {
+				return errors.New("must be positive")
+			}
+			return nil
+		},
+	},
+	{
+		name: "rank_keywords", env: "RANK_KEYWORDS", usage: "keyword multipliers of the ranking, e.g. generics:1.5,crypto:0.5", reloadable: true,
+		parse: func(c *Config, value string) (err error) {
+			c.RankKeywords, err = parseKeywords(value)
+			return err
+		},
+	},
+	{
+		name: "rank_half_life", env: "RANK_HALF_LIFE", usage: "how fast the freshness score of articles halves", value: "12h", reloadable: true,
+		parse:    duration(func(c *Config) *time.Duration { return &c.RankHalfLife }),
+		validate: func(c *Config) error { return positive(c.RankHalfLife) },
+	},
+	{
+		// Docker kills containers 10 seconds after SIGTERM unless stop_grace_period is raised.
+		name: "shutdown_timeout", env: "SHUTDOWN_TIMEOUT", usage: "how long workers are waited for on shutdown", value: "25s",
+		parse:    duration(func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+		validate: func(c *Config) error { return positive(c.ShutdownTimeout) },
+	},
+}
+
+func str(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func integer(field func(c *Config) *int64) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		if value == "" {
+			*field(c) = 0
+			return nil
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolean(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, want true or false", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func duration(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, e.g. 30s, 10m or 1h30m", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func required(set bool) error {
+	if !set {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+func positive(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+// parseList parses a comma separated list, empty entries are skipped.
+func parseList(s string) []string {
+	var list []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// parseKeywords parses "generics:1.5,security:2" into keyword weights.
+func parseKeywords(s string) (map[string]float64, error) {
+	keywords := make(map[string]float64)
+	for _, entry := range parseList(s) {
+		keyword, weight, ok := strings.Cut(entry, ":")
+		w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if !ok || err != nil || strings.TrimSpace(keyword) == "" {
+			return nil, fmt.Errorf("invalid keyword %q, want keyword:weight", entry)
+		}
+		keywords[strings.TrimSpace(keyword)] = w
+	}
+	return keywords, nil
+}
+
+// parseIDs parses a comma separated list of Telegram user IDs.
+func parseIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, entry := range parseList(s) {
+		id, err := strconv.ParseInt(entry, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", entry)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// readFile reads the settings of an HCL config file into their raw values. Lists are joined
+// with commas and blocks, e.g. rank_keywords { generics = 1.5 }, become key:value lists, so
+// the values are parsed the same way as environment variables.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := hcl.Decode(&raw, string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.name] = true
+	}
+
+	var errs []error
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s (%s): unknown setting", key, path))
+			continue
+		}
+
+		v, err := fileValue(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", key, path, err))
+			continue
+		}
+		values[key] = v
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return values, errors.Join(errs...)
+}
+
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int, int64, float64, bool:
+		return fmt.Sprint(v), nil
+	case []any:
+		entries := make([]string, 0, len(v))
+		for _, entry := range v {
+			s, err := fileValue(entry)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, s)
+		}
+		return strings.Join(entries, ","), nil
+	case []map[string]any:
+		var entries []string
+		for _, block := range v {
+			for key, entry := range block {
+				s, err := fileValue(entry)
+				if err != nil {
+					return "", err
+				}
+				entries = append(entries, key+":"+s)
+			}
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
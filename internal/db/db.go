@@ -1,103 +1,136 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
+	"log"
+	"strings"
 
+	"github.com/amir-amirov/go-news-feed-bot/internal/migrate"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage/migrations"
 	_ "github.com/lib/pq"
 )
 
-var DB *sql.DB
-
-func InitDB(connStr string) {
+// Database backends.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
 
-	// connStr = "host=localhost port=5430 user=postgres password=postgress dbname=news_feed_bot sslmode=disable"
-	var err error
+// Database is a connection pool and the backend it is connected to.
+type Database struct {
+	*sql.DB
+	Backend string
+}
 
-	DB, err = sql.Open("postgres", connStr)
+// Backend returns the backend of the DSN and the DSN its driver expects. DSNs starting with
+// "sqlite:" or "file:" are SQLite databases, e.g. "sqlite:news.db"; others are PostgreSQL
+// URLs or key=value strings.
+func Backend(dsn string) (string, string) {
+	switch {
+	case strings.HasPrefix(dsn, "sqlite://"):
+		return SQLite, sqliteDSN(strings.TrimPrefix(dsn, "sqlite://"))
+	case strings.HasPrefix(dsn, "sqlite:"):
+		return SQLite, sqliteDSN(strings.TrimPrefix(dsn, "sqlite:"))
+	case strings.HasPrefix(dsn, "file:"):
+		return SQLite, sqliteDSN(dsn)
+	default:
+		return Postgres, dsn
+	}
+}
 
-	if err != nil {
-		panic("Failed to connect to the database: " + err.Error())
+// sqliteDSN enables foreign keys, which SQLite turns off by default, and waits for locks
+// held by other connections instead of failing.
+func sqliteDSN(dsn string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
 	}
+	return dsn + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
 
-	err = DB.Ping()
+// Open opens a connection pool to the database of the DSN, see Backend, and checks that it
+// is reachable.
+func Open(dsn string) (*Database, error) {
+	backend, driverDSN := Backend(dsn)
+
+	db, err := sql.Open(backend, driverDSN)
 	if err != nil {
-		panic("Failed to ping the database: " + err.Error())
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	DB.SetMaxOpenConns(10)
-	DB.SetMaxIdleConns(5)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
 
-	createTables()
+	if backend == SQLite {
+		// SQLite allows a single writer, one connection avoids "database is locked" errors.
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(5)
+	}
 
-	fmt.Println("Successfully connected to PostgreSQL!")
+	return &Database{DB: db, Backend: backend}, nil
 }
 
-func createTables() {
-
-	createSourcesTable := `
-	CREATE TABLE IF NOT EXISTS sources(
-    	id         SERIAL PRIMARY KEY,
-    	name       VARCHAR(255) NOT NULL,
-    	feed_url   VARCHAR(255) NOT NULL,
-    	created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+// Migrator returns a migrator of the embedded migrations of the backend, see internal/storage/migrations.
+func (d *Database) Migrator() (*migrate.Migrator, error) {
+	var (
+		fsys    fs.FS
+		dialect migrate.Dialect
 	)
-	`
+	switch d.Backend {
+	case Postgres:
+		fsys, dialect = migrations.FS, migrate.Postgres
+	case SQLite:
+		fsys, dialect = migrations.SQLite, migrate.SQLite
+	default:
+		return nil, fmt.Errorf("unsupported database backend %q", d.Backend)
+	}
+
+	loaded, err := migrate.Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(d.DB, dialect, loaded), nil
+}
 
-	_, err := DB.Exec(createSourcesTable)
+// CheckMigrated returns an error if there are pending migrations, without applying them.
+func (d *Database) CheckMigrated(ctx context.Context) error {
+	migrator, err := d.Migrator()
 	if err != nil {
-		panic("Failed to create sources table: " + err.Error())
+		return fmt.Errorf("failed to load migrations: %w", err)
 	}
 
-	createArticlesTable := `
-	CREATE TABLE IF NOT EXISTS articles
-	(
-		id           BIGSERIAL PRIMARY KEY,
-		source_id    BIGINT       NOT NULL,
-		title        VARCHAR(255) NOT NULL,
-		summary      TEXT         NOT NULL,
-		link         TEXT         NOT NULL UNIQUE,
-		published_at TIMESTAMP    NOT NULL,
-		created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
-		posted_at    TIMESTAMP,
-		CONSTRAINT fk_articles_source_id
-			FOREIGN KEY (source_id)
-				REFERENCES sources (id)
-				ON DELETE CASCADE
-	);
-	`
-
-	_, err = DB.Exec(createArticlesTable)
+	pending, err := migrator.Pending(ctx)
 	if err != nil {
-		panic("Failed to create articles table: " + err.Error())
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("the database has %d pending migrations, apply them with \"migrate up\"", len(pending))
 	}
+
+	return nil
 }
 
-/*
-CREATE TABLE IF NOT EXISTS sources(
-	id SERIAL PRIMARY KEY,
-	name VARCHAR(255) NOT NULL,
-	feed_url VARCHAR(255) NOT NULL,
-	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-)
+// Migrate applies the pending migrations.
+func (d *Database) Migrate(ctx context.Context) error {
+	migrator, err := d.Migrator()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
 
-DROP TABLE IF EXISTS sources
-
-// ----------------------------------------------------------
-
-CREATE TABLE IF NOT EXISTS articles(
-	id SERIAL PRIMARY KEY,
-	source_id INT NOT NULL,
-	title VARCHAR(255) NOT NULL,
-	link VARCHAR(255) NOT NULL,
-	summary TEXT NOT NULL,
-	published_at TIMESTAMP NOT NULL,
-	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-	posted_at TIMESTAMP NOT NULL DEFAULT NOW(),
-	CONSTRAINT fk_articles_sources_id
-		FOREIGN KEY (source_id) REFERENCES sources(id)
-)
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate the database: %w", err)
+	}
 
-DROP TABLE IF EXISTS articles
-*/
+	return nil
+}
@@ -0,0 +1,50 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+)
+
+func TestDatabase_CheckMigrated(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.Open("sqlite:" + filepath.Join(t.TempDir(), "news.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.CheckMigrated(ctx); err == nil {
+		t.Fatal("CheckMigrated of an empty database succeeded")
+	}
+
+	// Checking doesn't touch the database, not even to create schema_migrations.
+	var tables int
+	if err := database.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatalf("failed to count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("CheckMigrated created %d tables", tables)
+	}
+
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if err := database.CheckMigrated(ctx); err != nil {
+		t.Errorf("CheckMigrated of a migrated database failed: %v", err)
+	}
+
+	migrator, err := database.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator failed: %v", err)
+	}
+	if _, err := migrator.Down(ctx, 1); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if err := database.CheckMigrated(ctx); err == nil {
+		t.Error("CheckMigrated succeeded with the last migration reverted")
+	}
+}
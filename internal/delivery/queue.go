@@ -0,0 +1,280 @@
+// Package delivery sends Bot API requests respecting Telegram rate limits,
+// retrying failed requests and moving the ones that can't be delivered to a dead-letter table.
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendFunc makes a single Bot API request.
+type SendFunc func(req botkit.Request) (tgbotapi.Message, error)
+
+// BotSender returns a SendFunc making requests with the bot API.
+func BotSender(api *tgbotapi.BotAPI) SendFunc {
+	return func(req botkit.Request) (tgbotapi.Message, error) {
+		return botkit.Send(api, req)
+	}
+}
+
+// SleepFunc waits for d or until ctx is done.
+type SleepFunc func(ctx context.Context, d time.Duration) error
+
+type DeadLetterRepository interface {
+	Store(ctx context.Context, deadLetter model.DeadLetter) error
+}
+
+// Limits configures the queue, see DefaultLimits.
+type Limits struct {
+	GlobalInterval time.Duration // minimum interval between any two messages
+	ChatInterval   time.Duration // minimum interval between messages to the same chat
+	GroupLimit     int           // maximum number of messages to a group or channel per GroupWindow
+	GroupWindow    time.Duration
+	MaxAttempts    int
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultLimits returns the limits recommended by Telegram: 30 messages per second overall,
+// a message per second to a chat and 20 messages per minute to a group.
+func DefaultLimits() Limits {
+	return Limits{
+		GlobalInterval: time.Second / 30,
+		ChatInterval:   time.Second,
+		GroupLimit:     20,
+		GroupWindow:    time.Minute,
+		MaxAttempts:    5,
+		MinBackoff:     time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+type Queue struct {
+	send        SendFunc
+	deadLetters DeadLetterRepository
+	limits      Limits
+	sleep       SleepFunc
+
+	mu         sync.Mutex
+	nextGlobal time.Time
+	chats      map[int64]*chatState
+}
+
+type chatState struct {
+	next time.Time   // earliest time of the next message
+	sent []time.Time // times of messages sent within the group window
+}
+
+func New(send SendFunc, deadLetters DeadLetterRepository, limits Limits) *Queue {
+	return &Queue{
+		send:        send,
+		deadLetters: deadLetters,
+		limits:      limits,
+		sleep:       sleep,
+		chats:       make(map[int64]*chatState),
+	}
+}
+
+// WithSleep makes the queue wait with sleep, e.g. to test it without waiting.
+func (q *Queue) WithSleep(sleep SleepFunc) *Queue {
+	q.sleep = sleep
+	return q
+}
+
+// Send waits for its turn and makes the request, retrying transient failures.
+// Requests that fail permanently or run out of attempts are stored as dead letters, unless
+// the caller has a fallback for them.
+func (q *Queue) Send(ctx context.Context, req botkit.Request) (tgbotapi.Message, error) {
+	chatID := req.ChatID()
+
+	var lastErr error
+	for attempt := 1; attempt <= q.limits.MaxAttempts; attempt++ {
+		if err := q.sleepUntil(ctx, q.reserve(chatID)); err != nil {
+			return tgbotapi.Message{}, err
+		}
+
+		msg, err := q.send(req)
+		if err == nil || unchanged(err) {
+			return msg, nil
+		}
+		lastErr = err
+
+		retryAfter, retry := classify(err)
+		if !retry {
+			break
+		}
+
+		if retryAfter > 0 {
+			log.Printf("[WARN] rate limited sending %s to %d, retrying after %s", req.Method, chatID, retryAfter)
+			q.pause(chatID, retryAfter)
+			continue
+		}
+
+		if attempt < q.limits.MaxAttempts {
+			log.Printf("[WARN] failed to send %s to %d (attempt %d): %v", req.Method, chatID, attempt, err)
+			if err := q.sleep(ctx, q.backoff(attempt)); err != nil {
+				return tgbotapi.Message{}, err
+			}
+		}
+	}
+
+	if !req.HasFallback {
+		q.storeDeadLetter(ctx, req, lastErr)
+	}
+	return tgbotapi.Message{}, fmt.Errorf("failed to send %s to %d: %w", req.Method, chatID, lastErr)
+}
+
+// reserve returns the time the next message to the chat may be sent at and books it.
+func (q *Queue) reserve(chatID int64) time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	chat := q.chat(chatID, now)
+
+	at := latest(now, q.nextGlobal, chat.next)
+
+	// Negative IDs are groups and channels, they have a per minute limit.
+	if chatID < 0 && q.limits.GroupLimit > 0 {
+		sent := chat.sent[:0]
+		for _, t := range chat.sent {
+			if t.After(at.Add(-q.limits.GroupWindow)) {
+				sent = append(sent, t)
+			}
+		}
+		chat.sent = sent
+
+		if len(sent) >= q.limits.GroupLimit {
+			at = latest(at, sent[len(sent)-q.limits.GroupLimit].Add(q.limits.GroupWindow))
+		}
+		chat.sent = append(chat.sent, at)
+	}
+
+	q.nextGlobal = at.Add(q.limits.GlobalInterval)
+	chat.next = at.Add(q.limits.ChatInterval)
+
+	return at
+}
+
+// pause delays messages to the chat, e.g. after Telegram asked to retry later.
+func (q *Queue) pause(chatID int64, d time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	chat := q.chat(chatID, now)
+	chat.next = latest(chat.next, now.Add(d))
+}
+
+// chat returns the chat state, forgetting idle chats so the map doesn't grow forever.
+func (q *Queue) chat(chatID int64, now time.Time) *chatState {
+	if len(q.chats) > 1024 {
+		for id, chat := range q.chats {
+			if chat.next.Add(q.limits.GroupWindow).Before(now) {
+				delete(q.chats, id)
+			}
+		}
+	}
+
+	chat, ok := q.chats[chatID]
+	if !ok {
+		chat = &chatState{}
+		q.chats[chatID] = chat
+	}
+	return chat
+}
+
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.limits.MinBackoff << (attempt - 1)
+	if d <= 0 || d > q.limits.MaxBackoff {
+		return q.limits.MaxBackoff
+	}
+	return d
+}
+
+func (q *Queue) storeDeadLetter(ctx context.Context, req botkit.Request, sendErr error) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		log.Printf("[ERROR] failed to encode dead letter params: %v", err)
+		return
+	}
+
+	if err := q.deadLetters.Store(ctx, model.DeadLetter{
+		ChatID: req.ChatID(),
+		Method: req.Method,
+		Params: string(params),
+		Error:  sendErr.Error(),
+	}); err != nil {
+		log.Printf("[ERROR] failed to store dead letter for %s to %d: %v", req.Method, req.ChatID(), err)
+	}
+}
+
+// unchanged reports whether an edit failed because the message already has the new content
+// or a delete failed because the message is already deleted.
+func unchanged(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 400 {
+		return false
+	}
+
+	return strings.Contains(apiErr.Message, "message is not modified") ||
+		strings.Contains(apiErr.Message, "message to delete not found")
+}
+
+// classify reports whether the request should be retried and how long Telegram asked to wait.
+func classify(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		// Network errors and malformed responses.
+		return 0, true
+	}
+
+	switch {
+	case apiErr.Code == 429:
+		return time.Duration(apiErr.RetryAfter) * time.Second, true
+	case apiErr.Code >= 500:
+		return 0, true
+	default:
+		// Bad requests, blocked bots, missing chats: retrying won't help.
+		return 0, false
+	}
+}
+
+func (q *Queue) sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	return q.sleep(ctx, d)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func latest(t time.Time, others ...time.Time) time.Time {
+	for _, o := range others {
+		if o.After(t) {
+			t = o
+		}
+	}
+	return t
+}
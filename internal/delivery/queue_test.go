@@ -0,0 +1,224 @@
+package delivery_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/delivery"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type deadLetters struct {
+	mu      sync.Mutex
+	letters []model.DeadLetter
+}
+
+func (d *deadLetters) Store(_ context.Context, deadLetter model.DeadLetter) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.letters = append(d.letters, deadLetter)
+	return nil
+}
+
+// sender fails with the given errors and then succeeds, recording the send times.
+type sender struct {
+	mu     sync.Mutex
+	errs   []error
+	times  []time.Time
+	chatID []int64
+}
+
+func (s *sender) send(req botkit.Request) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.times = append(s.times, time.Now())
+	s.chatID = append(s.chatID, req.ChatID())
+
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return tgbotapi.Message{}, err
+	}
+	return tgbotapi.Message{MessageID: len(s.times)}, nil
+}
+
+func testLimits() delivery.Limits {
+	return delivery.Limits{
+		GlobalInterval: time.Millisecond,
+		ChatInterval:   20 * time.Millisecond,
+		GroupLimit:     3,
+		GroupWindow:    100 * time.Millisecond,
+		MaxAttempts:    3,
+		MinBackoff:     time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+func TestQueue_RetriesTransientErrors(t *testing.T) {
+	s := &sender{errs: []error{
+		errors.New("connection reset"),
+		&tgbotapi.Error{Code: 502, Message: "Bad Gateway"},
+	}}
+	dead := &deadLetters{}
+	q := delivery.New(s.send, dead, testLimits())
+
+	msg, err := q.Send(context.Background(), botkit.NewTextRequest(1, "hi", ""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.MessageID != 3 {
+		t.Errorf("expected the third attempt to succeed, got message %d", msg.MessageID)
+	}
+	if len(dead.letters) != 0 {
+		t.Errorf("unexpected dead letters: %v", dead.letters)
+	}
+}
+
+func TestQueue_HonorsRetryAfter(t *testing.T) {
+	s := &sender{errs: []error{
+		&tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 30}},
+	}}
+
+	var waits []time.Duration
+	q := delivery.New(s.send, &deadLetters{}, testLimits()).WithSleep(func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	})
+
+	if _, err := q.Send(context.Background(), botkit.NewTextRequest(1, "hi", "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.times) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(s.times))
+	}
+	if !slices.ContainsFunc(waits, func(d time.Duration) bool { return d > 29*time.Second && d <= 30*time.Second }) {
+		t.Errorf("waited %v, expected 30s before retrying", waits)
+	}
+}
+
+func TestQueue_DeadLetters(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+	}{
+		{"permanent", []error{&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}}, 1},
+		{"out of attempts", []error{errors.New("timeout"), errors.New("timeout"), errors.New("timeout")}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &sender{errs: tt.errs}
+			dead := &deadLetters{}
+			q := delivery.New(s.send, dead, testLimits())
+
+			_, err := q.Send(context.Background(), botkit.NewTextRequest(-100, "hi", "HTML"))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(s.times) != tt.attempts {
+				t.Errorf("got %d attempts, want %d", len(s.times), tt.attempts)
+			}
+			if len(dead.letters) != 1 {
+				t.Fatalf("got %d dead letters, want 1", len(dead.letters))
+			}
+
+			letter := dead.letters[0]
+			if letter.ChatID != -100 || letter.Method != "sendMessage" || letter.Params == "" || letter.Error == "" {
+				t.Errorf("unexpected dead letter %+v", letter)
+			}
+		})
+	}
+}
+
+func TestQueue_Fallback(t *testing.T) {
+	s := &sender{errs: []error{&tgbotapi.Error{Code: 400, Message: "Bad Request: wrong type of the web page content"}}}
+	dead := &deadLetters{}
+	q := delivery.New(s.send, dead, testLimits())
+
+	// The caller sends a text message instead of the photo.
+	req := botkit.NewPhotoRequest(-100, "https://go.dev/gopher.png", "hi", "HTML").WithFallback()
+	if _, err := q.Send(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(dead.letters) != 0 {
+		t.Errorf("a request with a fallback was dead-lettered: %+v", dead.letters)
+	}
+}
+
+func TestQueue_UnchangedEdit(t *testing.T) {
+	s := &sender{errs: []error{&tgbotapi.Error{Code: 400, Message: "Bad Request: message is not modified"}}}
+	dead := &deadLetters{}
+	q := delivery.New(s.send, dead, testLimits())
+
+	if _, err := q.Send(context.Background(), botkit.NewEditTextRequest(-100, 1, "hi", "HTML")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.times) != 1 || len(dead.letters) != 0 {
+		t.Errorf("got %d attempts and %d dead letters, want 1 and 0", len(s.times), len(dead.letters))
+	}
+}
+
+func TestQueue_RateLimits(t *testing.T) {
+	s := &sender{}
+	limits := testLimits()
+	q := delivery.New(s.send, &deadLetters{}, limits)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, chatID := range []int64{-1, 2} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := q.Send(context.Background(), botkit.NewTextRequest(chatID, "hi", "")); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	byChat := map[int64][]time.Time{}
+	for i, chatID := range s.chatID {
+		byChat[chatID] = append(byChat[chatID], s.times[i])
+	}
+
+	// Allow for timer imprecision.
+	const slack = 2 * time.Millisecond
+
+	for chatID, times := range byChat {
+		for i := 1; i < len(times); i++ {
+			if d := times[i].Sub(times[i-1]); d < limits.ChatInterval-slack {
+				t.Errorf("chat %d: messages %s apart", chatID, d)
+			}
+		}
+	}
+
+	group := byChat[-1]
+	if d := group[limits.GroupLimit].Sub(group[0]); d < limits.GroupWindow-slack {
+		t.Errorf("group got %d messages within %s", limits.GroupLimit+1, d)
+	}
+}
+
+func TestQueue_Canceled(t *testing.T) {
+	s := &sender{errs: []error{&tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 60}}}}
+	dead := &deadLetters{}
+	q := delivery.New(s.send, dead, testLimits())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.Send(ctx, botkit.NewTextRequest(1, "hi", "")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+	if len(dead.letters) != 0 {
+		t.Error("canceled requests must not be dead-lettered")
+	}
+}
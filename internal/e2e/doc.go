@@ -0,0 +1,3 @@
+// Package e2e holds end-to-end tests running the fetcher and the notifier together on
+// fixture feeds, in-memory storage and a fake Telegram sender.
+package e2e
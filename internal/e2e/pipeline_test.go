@@ -0,0 +1,374 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/fetcher"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const channelID = -100123
+
+const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+<channel>
+	<title>Go Blog</title>
+	<link>{{server}}</link>
+	<description>News about Go</description>
+	<item>
+		<title>Generic type aliases</title>
+		<link>{{server}}/posts/aliases</link>
+		<description>Type aliases may now have type parameters, so a generic type can be renamed without copying its methods.</description>
+		<pubDate>{{published 1}}</pubDate>
+	</item>
+	<item>
+		<title>Faster maps with Swiss tables</title>
+		<link>{{server}}/posts/maps</link>
+		<pubDate>{{published 2}}</pubDate>
+	</item>
+	<item>
+		<title>Solving LeetCode problems in Go</title>
+		<link>{{server}}/posts/leetcode</link>
+		<description>Filtered out by keyword.</description>
+		<pubDate>{{published 3}}</pubDate>
+	</item>
+</channel>
+</rss>`
+
+// page is the article without a summary in the feed, the notifier downloads it.
+const page = `<!DOCTYPE html>
+<html><head><title>Faster maps with Swiss tables</title></head>
+<body><article>
+	<h1>Faster maps with Swiss tables</h1>
+	<p>The built-in map type now uses Swiss tables, an open addressing hash table design that stores
+	a group of slots together with a small control word describing them, so that most lookups
+	touch a single cache line.</p>
+	<p>Benchmarks of real programs show map operations getting up to sixty percent faster, while
+	memory use of large maps drops because fewer overflow buckets are allocated.</p>
+</article></body></html>`
+
+// aliasesItem matches the newest item of the feed.
+var aliasesItem = regexp.MustCompile(`(?s)\s*<item>\s*<title>Generic type aliases</title>.*?</item>`)
+
+// fixtureServer serves the feed and the article pages. The feed drops its newest item once
+// retracted is set.
+func fixtureServer(t *testing.T) (server *httptest.Server, retracted *atomic.Bool) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	now := time.Now()
+	body := strings.ReplaceAll(feed, "{{server}}", server.URL)
+	for hours := 1; hours <= 3; hours++ {
+		published := now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC1123Z)
+		body = strings.ReplaceAll(body, fmt.Sprintf("{{published %d}}", hours), published)
+	}
+
+	retracted = new(atomic.Bool)
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		if retracted.Load() {
+			fmt.Fprint(w, aliasesItem.ReplaceAllString(body, ""))
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/posts/maps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	})
+
+	return server, retracted
+}
+
+func newNotifier(store *storage.Storage, summarizer notifier.Summarizer, sender notifier.Sender, deleteRetracted bool) *notifier.Notifier {
+	return notifier.New(
+		store.Articles,
+		store.Sources,
+		store.Channels,
+		store.Deliveries,
+		summarizer,
+		rank.New(nil, 24*time.Hour),
+		sender,
+		0,
+		0, // post on every call
+		48*time.Hour,
+		time.Minute,
+		3,
+		deleteRetracted,
+	)
+}
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	server, _ := fixtureServer(t)
+	store := storage.NewMemory()
+
+	if _, err := store.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: server.URL + "/feed.xml"}); err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+		t.Fatalf("failed to add channel: %v", err)
+	}
+
+	f := fetcher.New(store.Articles, store.Sources, time.Minute, []string{"leetcode"})
+	if err := f.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var (
+		summarizer notifiertest.Summarizer
+		sender     notifiertest.Sender
+	)
+	n := newNotifier(store, &summarizer, &sender, false)
+
+	// Every call posts the best queued article, the filtered one is never posted.
+	for range 3 {
+		if err := n.SendDuePosts(ctx); err != nil {
+			t.Fatalf("SendDuePosts failed: %v", err)
+		}
+	}
+
+	requests := sender.Sent()
+	if len(requests) != 2 {
+		t.Fatalf("got %d messages, want 2: %+v", len(requests), requests)
+	}
+
+	wantPosts := []struct {
+		title string
+		link  string
+	}{
+		{"Generic type aliases", server.URL + "/posts/aliases"},
+		{"Faster maps with Swiss tables", server.URL + "/posts/maps"},
+	}
+	for i, want := range wantPosts {
+		req := requests[i]
+		if req.Method != "sendMessage" || req.ChatID() != channelID {
+			t.Errorf("post %d: unexpected request %s to %d", i, req.Method, req.ChatID())
+		}
+
+		// The default MarkdownV2 template escapes the dots of the link.
+		text := strings.ReplaceAll(req.Params["text"], `\`, "")
+		for _, part := range []string{want.title, "Summary of " + want.title, want.link} {
+			if !strings.Contains(text, part) {
+				t.Errorf("post %d: text %q doesn't contain %q", i, text, part)
+			}
+		}
+
+		article, err := store.Articles.ArticleByLink(ctx, want.link)
+		if err != nil || article == nil {
+			t.Fatalf("article %s not stored: %v", want.link, err)
+		}
+		if article.PostedAt.IsZero() {
+			t.Errorf("article %s not marked as posted", want.link)
+		}
+
+		deliveries, err := store.Deliveries.Sent(ctx, article.ID)
+		if err != nil {
+			t.Fatalf("Sent failed: %v", err)
+		}
+		if len(deliveries) != 1 || deliveries[0].ChatID != channelID || deliveries[0].MessageID != i+1 {
+			t.Errorf("unexpected deliveries of %s: %+v", want.link, deliveries)
+		}
+	}
+
+	if text, _ := summarizer.Text("Faster maps with Swiss tables"); !strings.Contains(text, "Swiss tables") {
+		t.Errorf("article page was not downloaded for summarizing, got text %q", text)
+	}
+	if _, ok := summarizer.Text("Solving LeetCode problems in Go"); ok {
+		t.Error("filtered article was summarized")
+	}
+}
+
+func TestPipeline_Tags(t *testing.T) {
+	ctx := context.Background()
+	server, _ := fixtureServer(t)
+	store := storage.NewMemory()
+
+	var pausedFetched atomic.Bool
+	paused := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pausedFetched.Store(true)
+	}))
+	defer paused.Close()
+
+	sourceID, err := store.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: server.URL + "/feed.xml", Tags: []string{"Go", "Releases"}})
+	if err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	pausedID, err := store.Sources.Add(ctx, model.Source{Name: "Paused", FeedURL: paused.URL})
+	if err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	if err := store.Sources.Update(ctx, model.Source{ID: pausedID, Name: "Paused", FeedURL: paused.URL, Priority: 1, Paused: true}); err != nil {
+		t.Fatalf("failed to pause source: %v", err)
+	}
+
+	const rustChannelID = -100456
+	for _, channel := range []model.Channel{{ID: channelID, Tags: "go,-jobs"}, {ID: rustChannelID, Tags: "rust"}} {
+		if err := store.Channels.Upsert(ctx, channel); err != nil {
+			t.Fatalf("failed to add channel: %v", err)
+		}
+	}
+
+	f := fetcher.New(store.Articles, store.Sources, time.Minute, []string{"leetcode"})
+	if err := f.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if pausedFetched.Load() {
+		t.Error("paused source was fetched")
+	}
+
+	// The feed's homepage and description are saved on the source.
+	source, err := store.Sources.SourceByID(ctx, sourceID)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+	if source.HomepageURL != server.URL || source.Description != "News about Go" {
+		t.Errorf("feed info not saved: %+v", source)
+	}
+
+	var sender notifiertest.Sender
+	n := newNotifier(store, &notifiertest.Summarizer{}, &sender, false)
+	if err := n.SendDuePosts(ctx); err != nil {
+		t.Fatalf("SendDuePosts failed: %v", err)
+	}
+
+	// Only the channel routed the "go" tag gets the post, with the tags as hashtags.
+	requests := sender.Sent()
+	if len(requests) != 1 || requests[0].ChatID() != channelID {
+		t.Fatalf("got requests %+v, want one post to %d", requests, channelID)
+	}
+	if text := requests[0].Params["text"]; !strings.HasSuffix(text, `\#go \#releases`) {
+		t.Errorf("post %q doesn't end with the hashtags of the source", text)
+	}
+}
+
+func TestPipeline_DryRun(t *testing.T) {
+	ctx := context.Background()
+	server, _ := fixtureServer(t)
+	store := storage.NewMemory()
+
+	sourceID, err := store.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: server.URL + "/feed.xml"})
+	if err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	source, err := store.Sources.SourceByID(ctx, sourceID)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+
+	f := fetcher.New(store.Articles, store.Sources, time.Minute, []string{"leetcode"})
+	articles, err := f.Articles(ctx, *source)
+	if err != nil {
+		t.Fatalf("Articles failed: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Errorf("got %d articles, want 2 without the filtered one: %+v", len(articles), articles)
+	}
+	if stored, _ := store.Articles.AllNotPosted(ctx, time.Time{}, 10); len(stored) != 0 {
+		t.Fatalf("Articles stored %d articles", len(stored))
+	}
+
+	f.FetchSources(ctx, []model.Source{*source})
+
+	var (
+		summarizer notifiertest.Summarizer
+		sender     notifiertest.Sender
+	)
+	n := newNotifier(store, &summarizer, &sender, false)
+
+	item, ok, err := n.Preview(ctx, channelID, &sender)
+	if err != nil || !ok {
+		t.Fatalf("Preview() = %v, %v", ok, err)
+	}
+	if requests := sender.Sent(); len(requests) != 1 || !strings.Contains(requests[0].Params["text"], "Summary of "+item.Article.Title) {
+		t.Errorf("unexpected preview: %+v", requests)
+	}
+
+	if deliveries, _ := store.Deliveries.Sent(ctx, item.Article.ID); len(deliveries) != 0 {
+		t.Errorf("Preview recorded deliveries: %+v", deliveries)
+	}
+	if summary, _ := store.Articles.Summary(ctx, item.Article.ID, "en"); summary != "" {
+		t.Errorf("Preview stored the summary %q", summary)
+	}
+}
+
+func TestPipeline_Retraction(t *testing.T) {
+	ctx := context.Background()
+	server, retracted := fixtureServer(t)
+	store := storage.NewMemory()
+
+	if _, err := store.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: server.URL + "/feed.xml"}); err != nil {
+		t.Fatalf("failed to add source: %v", err)
+	}
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+		t.Fatalf("failed to add channel: %v", err)
+	}
+
+	f := fetcher.New(store.Articles, store.Sources, time.Minute, []string{"leetcode"})
+	if err := f.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var sender notifiertest.Sender
+	n := newNotifier(store, &notifiertest.Summarizer{}, &sender, true)
+	for range 2 {
+		if err := n.SendDuePosts(ctx); err != nil {
+			t.Fatalf("SendDuePosts failed: %v", err)
+		}
+	}
+
+	// An admin adds a link of the same site, it is not in the feed but must not be retracted.
+	manual := model.Article{Title: "Go at Google", Link: server.URL + "/talks/go-at-google", PublishedAt: time.Now().UTC()}
+	if err := store.Articles.Store(ctx, manual); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	retracted.Store(true)
+	if err := f.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err := n.SyncPosts(ctx); err != nil {
+		t.Fatalf("SyncPosts failed: %v", err)
+	}
+
+	requests := sender.Sent()
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 2 posts and a deletion: %+v", len(requests), requests)
+	}
+	aliases := requests[0]
+	if !strings.Contains(aliases.Params["text"], "Generic type aliases") {
+		t.Fatalf("first post %q isn't the newest article", aliases.Params["text"])
+	}
+	if deletion := requests[2]; deletion.Method != "deleteMessage" || deletion.Params["message_id"] != "1" {
+		t.Errorf("got %s of message %s, want the first post deleted", deletion.Method, deletion.Params["message_id"])
+	}
+
+	for link, want := range map[string]bool{
+		server.URL + "/posts/aliases": true,
+		server.URL + "/posts/maps":    false,
+		manual.Link:                   false,
+	} {
+		article, err := store.Articles.ArticleByLink(ctx, link)
+		if err != nil || article == nil {
+			t.Fatalf("article %s not stored: %v", link, err)
+		}
+		if got := !article.RetractedAt.IsZero(); got != want {
+			t.Errorf("article %s retracted = %v, want %v", link, got, want)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package fetcher
 import (
 	"context"
 	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
 	"github.com/amir-amirov/go-news-feed-bot/internal/model"
 	"github.com/amir-amirov/go-news-feed-bot/internal/source"
 	"go.tomakado.io/containers/set"
@@ -14,12 +16,15 @@ import (
 
 type articlesRepository interface {
 	Store(ctx context.Context, article model.Article) error
+	PublishedSince(ctx context.Context, sourceID int64, since time.Time) ([]model.Article, error)
+	MarkRetracted(ctx context.Context, articleID int64) error
 }
 
 // Although source storage might have methods like these,
-// they are not used by Fetcher, that's why interface has only Sources method.
+// they are not used by Fetcher, that's why interface has only the methods below.
 type sourcesRepository interface {
 	Sources(ctx context.Context) ([]model.Source, error)
+	SetFeedInfo(ctx context.Context, id int64, homepageURL, description, iconURL string) error
 	// SourceByID(ctx context.Context, id int64) (*model.Source, error)
 	// Add(ctx context.Context, source model.Source) (int64, error)
 	// Delete(ctx context.Context, id int64) error
@@ -28,15 +33,18 @@ type sourcesRepository interface {
 type Source interface {
 	ID() int64
 	Name() string
-	Fetch(ctx context.Context) ([]model.Item, error)
+	Language() string
+	FetchFeed(ctx context.Context) (source.FeedInfo, []model.Item, error)
 }
 
 type Fetcher struct {
 	articlesRepository articlesRepository
 	sourcesRepository  sourcesRepository
 
+	mu             sync.RWMutex
 	fetchInterval  time.Duration
 	filterKeywords []string
+	reconfigured   chan struct{}
 }
 
 func New(articleRepo articlesRepository, sourceRepo sourcesRepository, fetchInterval time.Duration, filterKeywords []string) *Fetcher {
@@ -45,14 +53,36 @@ func New(articleRepo articlesRepository, sourceRepo sourcesRepository, fetchInte
 		sourcesRepository:  sourceRepo,
 		fetchInterval:      fetchInterval,
 		filterKeywords:     filterKeywords,
+		reconfigured:       make(chan struct{}, 1),
 	}
 }
 
+// Reconfigure changes the fetch interval and filter keywords of a running fetcher.
+func (f *Fetcher) Reconfigure(fetchInterval time.Duration, filterKeywords []string) {
+	f.mu.Lock()
+	f.fetchInterval, f.filterKeywords = fetchInterval, filterKeywords
+	f.mu.Unlock()
+
+	select {
+	case f.reconfigured <- struct{}{}:
+	default:
+	}
+}
+
+func (f *Fetcher) interval() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.fetchInterval
+}
+
+// Start fetches the sources every fetch interval until the context is done. A fetch in
+// progress when it is done is finished rather than cut off.
 func (f *Fetcher) Start(ctx context.Context) {
-	ticker := time.NewTicker(f.fetchInterval) // setInterval
+	ticker := time.NewTicker(f.interval()) // setInterval
 	defer ticker.Stop()
 
-	if err := f.Fetch(ctx); err != nil {
+	if err := f.Fetch(context.WithoutCancel(ctx)); err != nil {
 		log.Printf("initial fetch failed: %v", err)
 	}
 
@@ -61,20 +91,30 @@ func (f *Fetcher) Start(ctx context.Context) {
 		case <-ctx.Done():
 			log.Println("Fetcher stopped:", ctx.Err())
 			return
+		case <-f.reconfigured:
+			ticker.Reset(f.interval())
 		case <-ticker.C:
-			if err := f.Fetch(ctx); err != nil {
+			if err := f.Fetch(context.WithoutCancel(ctx)); err != nil {
 				log.Printf("fetch failed: %v", err)
 			}
 		}
 	}
 }
 
+// Fetch fetches the sources that aren't paused.
 func (f *Fetcher) Fetch(ctx context.Context) error {
 	sources, err := f.sourcesRepository.Sources(ctx)
 	if err != nil {
 		return err
 	}
 
+	f.FetchSources(ctx, slices.DeleteFunc(sources, func(src model.Source) bool { return src.Paused }))
+	return nil
+}
+
+// FetchSources fetches the sources concurrently and stores their new articles. Sources that
+// fail are logged and skipped.
+func (f *Fetcher) FetchSources(ctx context.Context, sources []model.Source) {
 	var wg sync.WaitGroup
 
 	for _, src := range sources {
@@ -85,38 +125,65 @@ func (f *Fetcher) Fetch(ctx context.Context) error {
 		go func(source Source) {
 			defer wg.Done()
 
-			items, err := source.Fetch(ctx)
+			info, items, err := source.FetchFeed(ctx)
 			if err != nil {
 				log.Printf("[ERROR] Failed to fetch items from source %s: %v", source.Name(), err)
 				return
 			}
 
+			if err := f.updateFeedInfo(ctx, src, info); err != nil {
+				log.Printf("[ERROR] Failed to save the feed info of source %s: %v", source.Name(), err)
+			}
+
 			if err := f.processItems(ctx, source, items); err != nil {
 				log.Printf("[ERROR] Failed to process items from source %s: %v", source.Name(), err)
 				return
 			}
+
+			if err := f.markRetracted(ctx, source, items); err != nil {
+				log.Printf("[ERROR] Failed to check retracted items of source %s: %v", source.Name(), err)
+			}
 		}(rssSource)
 
 	}
 	wg.Wait()
-	return nil
 }
 
-func (f *Fetcher) processItems(ctx context.Context, source Source, items []model.Item) error {
+// Articles fetches the source and returns the articles that would be stored, without storing them.
+func (f *Fetcher) Articles(ctx context.Context, src model.Source) ([]model.Article, error) {
+	rssSource := source.NewRSSSourceFromModel(src)
+
+	_, items, err := rssSource.FetchFeed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []model.Article
 	for _, item := range items {
-		item.Date = item.Date.UTC()
+		if article, ok := f.article(rssSource, item); ok {
+			articles = append(articles, article)
+		}
+	}
+	return articles, nil
+}
 
-		if f.itemShouldBeSkipped(item) {
+// updateFeedInfo saves the homepage, description and icon of the feed if they changed. A
+// homepage set by admins is kept.
+func (f *Fetcher) updateFeedInfo(ctx context.Context, src model.Source, info source.FeedInfo) error {
+	if (src.HomepageURL != "" || info.HomepageURL == "") && info.Description == src.Description && info.IconURL == src.IconURL {
+		return nil
+	}
+	return f.sourcesRepository.SetFeedInfo(ctx, src.ID, info.HomepageURL, info.Description, info.IconURL)
+}
+
+func (f *Fetcher) processItems(ctx context.Context, source Source, items []model.Item) error {
+	for _, item := range items {
+		article, ok := f.article(source, item)
+		if !ok {
 			continue
 		}
 
-		if err := f.articlesRepository.Store(ctx, model.Article{
-			SourceID:    source.ID(),
-			Title:       item.Title,
-			Link:        item.Link,
-			Summary:     item.Summary,
-			PublishedAt: item.Date,
-		}); err != nil {
+		if err := f.articlesRepository.Store(ctx, article); err != nil {
 			return err
 		}
 
@@ -125,9 +192,75 @@ func (f *Fetcher) processItems(ctx context.Context, source Source, items []model
 	return nil
 }
 
+// article returns the article of the item, false if the item is filtered out. The language
+// is detected unless the source has one.
+func (f *Fetcher) article(source Source, item model.Item) (model.Article, bool) {
+	if f.itemShouldBeSkipped(item) {
+		return model.Article{}, false
+	}
+
+	language := source.Language()
+	if language == "" {
+		language = lang.Detect(item.Title + "\n" + item.Summary)
+	}
+
+	return model.Article{
+		SourceID:    source.ID(),
+		Title:       item.Title,
+		Link:        item.Link,
+		Summary:     item.Summary,
+		Content:     item.Content,
+		Language:    language,
+		ImageURL:    item.ImageURL,
+		PublishedAt: item.Date.UTC(),
+	}, true
+}
+
+// markRetracted marks the stored articles of the source that were removed from the feed:
+// the ones published since the oldest item of the feed that the feed no longer has.
+// Feeds with undated items are not checked.
+func (f *Fetcher) markRetracted(ctx context.Context, source Source, items []model.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	links := make(map[string]bool, len(items))
+	var oldest time.Time
+	for _, item := range items {
+		if item.Date.IsZero() {
+			return nil
+		}
+		if oldest.IsZero() || item.Date.Before(oldest) {
+			oldest = item.Date
+		}
+		links[item.Link] = true
+	}
+
+	articles, err := f.articlesRepository.PublishedSince(ctx, source.ID(), oldest)
+	if err != nil {
+		return err
+	}
+
+	for _, article := range articles {
+		if links[article.Link] {
+			continue
+		}
+
+		log.Printf("Article %d was removed from %s: %s", article.ID, source.Name(), article.Title)
+		if err := f.articlesRepository.MarkRetracted(ctx, article.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (f *Fetcher) itemShouldBeSkipped(item model.Item) bool {
 	categoriesSet := set.New(item.Categories...)
 
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	for _, keyword := range f.filterKeywords {
 		titleContainsKeyword := strings.Contains(strings.ToLower(item.Title), strings.ToLower(keyword))
 		if categoriesSet.Contains(keyword) || titleContainsKeyword {
@@ -0,0 +1,162 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language codes are ISO 639-1.
+const (
+	English   = "en"
+	Russian   = "ru"
+	Ukrainian = "uk"
+	German    = "de"
+	French    = "fr"
+	Spanish   = "es"
+	Greek     = "el"
+	Arabic    = "ar"
+	Hebrew    = "he"
+	Chinese   = "zh"
+	Japanese  = "ja"
+	Korean    = "ko"
+)
+
+var names = map[string]string{
+	English:   "English",
+	Russian:   "Russian",
+	Ukrainian: "Ukrainian",
+	German:    "German",
+	French:    "French",
+	Spanish:   "Spanish",
+	Greek:     "Greek",
+	Arabic:    "Arabic",
+	Hebrew:    "Hebrew",
+	Chinese:   "Chinese",
+	Japanese:  "Japanese",
+	Korean:    "Korean",
+}
+
+// Name returns the English name of the language, suitable for LLM prompts.
+// Unknown codes are returned as is.
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// minLetters is the amount of letters below which detection is not attempted.
+const minLetters = 12
+
+// Latin-script languages are told apart by their most frequent words.
+var stopwords = map[string][]string{
+	English: {"the", "and", "of", "to", "is", "in", "that", "for", "with", "it", "on", "are", "this", "as", "be", "you", "was", "from", "by", "how"},
+	German:  {"der", "die", "und", "das", "ist", "nicht", "mit", "ein", "eine", "zu", "den", "von", "auf", "für", "sich", "auch", "dem", "wie", "es", "im"},
+	French:  {"le", "la", "les", "et", "des", "est", "un", "une", "du", "pour", "que", "dans", "pas", "sur", "qui", "au", "avec", "ce", "sont", "par"},
+	Spanish: {"el", "la", "los", "las", "y", "es", "un", "una", "que", "del", "en", "por", "con", "para", "se", "no", "al", "su", "como", "más"},
+}
+
+// Detect guesses the language of text and returns its ISO 639-1 code.
+// An empty string is returned when the text is too short or ambiguous.
+func Detect(text string) string {
+	var latin, cyrillic, total int
+	scripts := make(map[string]int)
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Greek, r):
+			scripts[Greek]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts[Arabic]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts[Hebrew]++
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			scripts[Japanese]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts[Korean]++
+		case unicode.Is(unicode.Han, r):
+			scripts[Chinese]++
+		}
+	}
+
+	if total < minLetters {
+		return ""
+	}
+
+	// Kanji are Han characters too, any kana means the text is Japanese.
+	if scripts[Japanese] > 0 {
+		scripts[Japanese] += scripts[Chinese]
+		delete(scripts, Chinese)
+	}
+
+	best, bestCount := "", 0
+	for code, count := range scripts {
+		if count > bestCount {
+			best, bestCount = code, count
+		}
+	}
+
+	switch {
+	case cyrillic >= latin && cyrillic > bestCount:
+		return detectCyrillic(text)
+	case latin > cyrillic && latin > bestCount:
+		return detectLatin(text)
+	default:
+		return best
+	}
+}
+
+func detectCyrillic(text string) string {
+	// These letters are used in Ukrainian but not in Russian.
+	if strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+		return Ukrainian
+	}
+	return Russian
+}
+
+func detectLatin(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	counts := make(map[string]int, len(stopwords))
+	for _, word := range words {
+		for code, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					counts[code]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestCount, tie := "", 0, false
+	for code, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tie = code, count, false
+		case count == bestCount:
+			tie = true
+		}
+	}
+
+	if bestCount == 0 || tie {
+		// Tech news is mostly English, prefer it over guessing.
+		if counts[English] == bestCount {
+			return English
+		}
+		return ""
+	}
+
+	return best
+}
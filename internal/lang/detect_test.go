@@ -0,0 +1,35 @@
+package lang_test
+
+import (
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"too short", "Go 1.22", ""},
+		{"english", "The Go team is happy to announce the release of Go 1.22 with range over integers.", lang.English},
+		{"russian", "Команда Go рада объявить о выходе новой версии языка с поддержкой итераторов.", lang.Russian},
+		{"ukrainian", "Команда Go рада повідомити про вихід нової версії мови з підтримкою ітераторів.", lang.Ukrainian},
+		{"german", "Die neue Version ist nicht nur schneller, sondern auch sicherer als die alte.", lang.German},
+		{"french", "La nouvelle version est disponible pour les développeurs et elle est plus rapide.", lang.French},
+		{"spanish", "La nueva versión es más rápida y los desarrolladores pueden usarla con facilidad.", lang.Spanish},
+		{"japanese", "新しいバージョンのGoがリリースされました。", lang.Japanese},
+		{"chinese", "新版本的编程语言已经发布了，性能有很大提升。", lang.Chinese},
+		{"mixed cyrillic with code", "Как использовать context.WithTimeout в своих сервисах на Go", lang.Russian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lang.Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+// Package migrate applies versioned SQL migrations recorded in the schema_migrations table.
+//
+// Migrations are files named <version>_<name>.sql with the statements to apply after
+// "-- +goose Up" and the ones to revert after "-- +goose Down".
+package migrate
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey identifies the advisory lock held while migrating, so that instances started at
+// the same time don't apply migrations concurrently.
+const lockKey = 7_310_211_905
+
+// Dialect holds the statements that differ between databases.
+type Dialect struct {
+	// Lock and Unlock guard the session while migrating, no lock is taken if they are empty.
+	Lock, Unlock string
+	// Insert and Delete record and remove an applied migration by version (and name).
+	Insert, Delete string
+	// Exists reports whether the schema_migrations table exists.
+	Exists string
+}
+
+var (
+	Postgres = Dialect{
+		Lock:   fmt.Sprintf(`SELECT pg_advisory_lock(%d)`, lockKey),
+		Unlock: fmt.Sprintf(`SELECT pg_advisory_unlock(%d)`, lockKey),
+		Insert: `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+		Delete: `DELETE FROM schema_migrations WHERE version = $1`,
+		Exists: `SELECT to_regclass('schema_migrations') IS NOT NULL`,
+	}
+	// SQLite needs no lock, writes to the database file are serialized.
+	SQLite = Dialect{
+		Insert: `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+		Delete: `DELETE FROM schema_migrations WHERE version = ?`,
+		Exists: `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`,
+	}
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied, zero if it is pending.
+type Status struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// Load reads the migrations in the root of fsys ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+
+		prefix, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s", file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		up, down, err := parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, Up: up, Down: down})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// parse splits a migration into its up and down statements.
+func parse(content string) (string, string, error) {
+	var (
+		up, down strings.Builder
+		section  *strings.Builder
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if directive, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose"); ok {
+			switch strings.TrimSpace(directive) {
+			case "Up":
+				section = &up
+			case "Down":
+				section = &down
+			case "StatementBegin", "StatementEnd":
+				// Sections are executed as a whole.
+			default:
+				return "", "", fmt.Errorf("unsupported directive %q", line)
+			}
+			continue
+		}
+
+		if section != nil {
+			section.WriteString(line)
+			section.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	if strings.TrimSpace(up.String()) == "" {
+		return "", "", fmt.Errorf("no up statements")
+	}
+
+	return strings.TrimSpace(up.String()), strings.TrimSpace(down.String()), nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func New(db *sql.DB, dialect Dialect, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}
+}
+
+// Up applies the pending migrations in order, each in its own transaction.
+// It returns the applied migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn, versions map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			if err := apply(ctx, conn, migration.Up, m.dialect.Insert, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first. It returns the reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn, versions map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s can't be reverted", migration.Version, migration.Name)
+			}
+
+			if err := apply(ctx, conn, migration.Down, m.dialect.Delete, migration.Version); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status returns all migrations with the time they were applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.locked(ctx, func(_ *sql.Conn, versions map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			statuses = append(statuses, Status{Migration: migration, AppliedAt: versions[migration.Version]})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending returns the migrations that are not applied. Unlike Status it doesn't lock or change
+// the database, so it can be used by runs that must leave the database as it is.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, m.dialect.Exists).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check for schema_migrations table: %w", err)
+	}
+	if !exists {
+		return slices.Clone(m.migrations), nil
+	}
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := versions[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// locked runs fn on a single connection holding the dialect's lock with the applied migration versions.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, versions map[int64]time.Time) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	// The lock belongs to the session, it is released even if unlocking fails when the connection is closed.
+	if m.dialect.Lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.Lock); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), m.dialect.Unlock)
+	}
+
+	createTable := `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    BIGINT PRIMARY KEY,
+			name       TEXT      NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, versions)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+
+	return versions, rows.Err()
+}
+
+// apply runs the statements of a migration and records it in one transaction.
+func apply(ctx context.Context, conn *sql.Conn, statements, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Without arguments the statements are sent at once, so a migration may contain several of them.
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
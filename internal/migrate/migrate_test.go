@@ -0,0 +1,320 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+	"github.com/amir-amirov/go-news-feed-bot/internal/migrate"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240102000000_add_column.sql": {Data: []byte(`-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE things ADD COLUMN IF NOT EXISTS color TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE things DROP COLUMN IF EXISTS color;
+-- +goose StatementEnd
+`)},
+		"20240101000000_create_things.sql": {Data: []byte(`-- +goose Up
+CREATE TABLE things (id BIGINT PRIMARY KEY);
+`)},
+		"README.md": {Data: []byte("not a migration")},
+	}
+
+	got, err := migrate.Load(fsys)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := []migrate.Migration{
+		{Version: 20240101000000, Name: "create_things", Up: "CREATE TABLE things (id BIGINT PRIMARY KEY);"},
+		{
+			Version: 20240102000000,
+			Name:    "add_column",
+			Up:      "ALTER TABLE things ADD COLUMN IF NOT EXISTS color TEXT;",
+			Down:    "ALTER TABLE things DROP COLUMN IF EXISTS color;",
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d:\ngot  %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"no version":    {"create_things.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}},
+		"no up section": {"1_empty.sql": {Data: []byte("-- +goose Down\nSELECT 1;")}},
+		"duplicate": {
+			"1_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;")},
+			"1_b.sql": {Data: []byte("-- +goose Up\nSELECT 2;")},
+		},
+		"unknown directive": {"1_a.sql": {Data: []byte("-- +goose NO TRANSACTION\n-- +goose Up\nSELECT 1;")}},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := migrate.Load(fsys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for _, migration := range loaded {
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s can't be reverted", migration.Version, migration.Name)
+		}
+	}
+
+	sqlite, err := migrate.Load(migrations.SQLite)
+	if err != nil {
+		t.Fatalf("Load of SQLite migrations failed: %v", err)
+	}
+	if len(sqlite) != len(loaded) {
+		t.Fatalf("got %d SQLite migrations, want %d", len(sqlite), len(loaded))
+	}
+	for i, migration := range sqlite {
+		if migration.Version != loaded[i].Version || migration.Name != loaded[i].Name {
+			t.Errorf("SQLite migration %d_%s doesn't match %d_%s", migration.Version, migration.Name, loaded[i].Version, loaded[i].Name)
+		}
+		if migration.Down == "" {
+			t.Errorf("SQLite migration %d_%s can't be reverted", migration.Version, migration.Name)
+		}
+	}
+}
+
+// things are the migrations the Migrator tests apply.
+var things = []migrate.Migration{
+	{Version: 1, Name: "create_things", Up: "CREATE TABLE things (id INTEGER PRIMARY KEY);", Down: "DROP TABLE things;"},
+	{
+		Version: 2,
+		Name:    "add_colors",
+		Up:      "ALTER TABLE things ADD COLUMN color TEXT;\nCREATE TABLE colors (name TEXT PRIMARY KEY);",
+		Down:    "DROP TABLE colors;\nALTER TABLE things DROP COLUMN color;",
+	},
+}
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	database, err := db.Open("sqlite:" + filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database.DB
+}
+
+// tables returns the names of the tables of the database other than schema_migrations.
+func tables(t *testing.T, database *sql.DB) []string {
+	t.Helper()
+
+	rows, err := database.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name <> 'schema_migrations' ORDER BY name`)
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("failed to list tables: %v", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	return names
+}
+
+func versions(migrations []migrate.Migration) []int64 {
+	var versions []int64
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	migrator := migrate.New(database, migrate.SQLite, things)
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil || !slices.Equal(versions(pending), []int64{1, 2}) {
+		t.Fatalf("Pending() = %v, %v before migrating", versions(pending), err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if !slices.Equal(versions(applied), []int64{1, 2}) {
+		t.Errorf("Up applied %v", versions(applied))
+	}
+	if got := tables(t, database); !slices.Equal(got, []string{"colors", "things"}) {
+		t.Errorf("got tables %q after Up", got)
+	}
+	if pending, err := migrator.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("Pending() = %v, %v after Up", versions(pending), err)
+	}
+
+	// Applied migrations are not applied again.
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("second Up() = %v, %v", versions(applied), err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].AppliedAt.IsZero() || statuses[1].AppliedAt.IsZero() {
+		t.Errorf("Status returned %+v, want both migrations applied", statuses)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if !slices.Equal(versions(reverted), []int64{2}) {
+		t.Errorf("Down reverted %v, want the newest migration", versions(reverted))
+	}
+	if got := tables(t, database); !slices.Equal(got, []string{"things"}) {
+		t.Errorf("got tables %q after Down", got)
+	}
+
+	statuses, err = migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if statuses[0].AppliedAt.IsZero() || !statuses[1].AppliedAt.IsZero() {
+		t.Errorf("Status returned %+v, want the second migration pending", statuses)
+	}
+
+	// More steps than applied migrations revert all of them.
+	if reverted, err := migrator.Down(ctx, 5); err != nil || !slices.Equal(versions(reverted), []int64{1}) {
+		t.Errorf("Down() = %v, %v", versions(reverted), err)
+	}
+	if got := tables(t, database); len(got) != 0 {
+		t.Errorf("got tables %q after reverting everything", got)
+	}
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+
+	// The second migration fails after its first statement.
+	broken := slices.Clone(things)
+	broken[1].Up = "CREATE TABLE colors (name TEXT PRIMARY KEY);\nALTER TABLE missing ADD COLUMN color TEXT;"
+	broken = append(broken, migrate.Migration{Version: 3, Name: "create_sizes", Up: "CREATE TABLE sizes (name TEXT);"})
+
+	applied, err := migrate.New(database, migrate.SQLite, broken).Up(ctx)
+	if err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+
+	// The migrations before it stay applied, it is rolled back as a whole and the ones after
+	// it are not applied.
+	if !slices.Equal(versions(applied), []int64{1}) {
+		t.Errorf("Up applied %v", versions(applied))
+	}
+	if got := tables(t, database); !slices.Equal(got, []string{"things"}) {
+		t.Errorf("got tables %q, want the broken migration rolled back", got)
+	}
+	pending, err := migrate.New(database, migrate.SQLite, things).Pending(ctx)
+	if err != nil || !slices.Equal(versions(pending), []int64{2}) {
+		t.Errorf("Pending() = %v, %v", versions(pending), err)
+	}
+}
+
+func TestMigrator_Down_Irreversible(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+
+	irreversible := slices.Clone(things)
+	irreversible[1].Down = ""
+	migrator := migrate.New(database, migrate.SQLite, irreversible)
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	if reverted, err := migrator.Down(ctx, 2); err == nil || len(reverted) != 0 {
+		t.Errorf("Down() = %v, %v for a migration without down statements", versions(reverted), err)
+	}
+	if got := tables(t, database); !slices.Equal(got, []string{"colors", "things"}) {
+		t.Errorf("got tables %q", got)
+	}
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+
+	if _, err := database.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("failed to create events table: %v", err)
+	}
+
+	// Locking and unlocking are recorded along with the migration in between.
+	dialect := migrate.SQLite
+	dialect.Lock = `INSERT INTO events (name) VALUES ('lock')`
+	dialect.Unlock = `INSERT INTO events (name) VALUES ('unlock')`
+	logged := []migrate.Migration{{Version: 1, Name: "log", Up: `INSERT INTO events (name) VALUES ('migrate')`}}
+
+	if _, err := migrate.New(database, dialect, logged).Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	rows, err := database.Query(`SELECT name FROM events ORDER BY id`)
+	if err != nil {
+		t.Fatalf("failed to fetch events: %v", err)
+	}
+	defer rows.Close()
+
+	var events []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("failed to fetch events: %v", err)
+		}
+		events = append(events, name)
+	}
+	if want := []string{"lock", "migrate", "unlock"}; !slices.Equal(events, want) {
+		t.Errorf("got events %q, want %q", events, want)
+	}
+
+	// Nothing is migrated without the lock.
+	dialect.Lock = `SELECT * FROM missing`
+	if applied, err := migrate.New(database, dialect, things).Up(ctx); err == nil || len(applied) != 0 {
+		t.Errorf("Up() = %v, %v when locking fails", versions(applied), err)
+	}
+	if got := tables(t, database); !slices.Equal(got, []string{"events"}) {
+		t.Errorf("got tables %q, want nothing migrated", got)
+	}
+}
@@ -9,23 +9,158 @@ type Item struct {
 	Link       string
 	Date       time.Time
 	Summary    string
+	Content    string
+	ImageURL   string
 	SourceName string
 }
 
 type Source struct {
-	ID        int64
-	Name      string
-	FeedURL   string
+	ID       int64
+	Name     string
+	FeedURL  string
+	Priority float64  // positive ranking weight, 1 by default
+	Tags     []string // lowercase categories, e.g. from the folders of an imported OPML file
+	Language string   // language of the articles, detected for every article if empty
+	Paused   bool     // paused sources are not fetched
+
+	// HomepageURL is the website of the feed, taken from the feed unless an admin set it.
+	HomepageURL string
+	Description string // from the feed
+	IconURL     string // from the feed
+
 	CreatedAt time.Time
+	UpdatedAt time.Time // when the source was last changed, zero if never
 }
 
 type Article struct {
-	ID          int64
-	SourceID    int64
-	Title       string
-	Link        string
-	Summary     string
+	ID       int64
+	SourceID int64 // zero for articles added by admins that don't come from a source
+	Title    string
+	Link     string
+	Summary  string
+	Content  string // full text from the feed, stored for search; the database repositories don't load it
+	Language string
+	ImageURL string
+
+	ModerationStatus string    // empty if the article wasn't sent for moderation
+	PostponedUntil   time.Time // when a postponed article is sent for moderation again
+	ScheduledAt      time.Time // when an admin scheduled the article to be posted, zero if it is queued as usual
+	Skipped          bool      // an admin skipped the article, it is never posted
+	TitleEdited      bool      // an admin edited the title, it is not updated from the feed
+	UpdatedAt        time.Time // when the title was changed after the article was stored
+	RetractedAt      time.Time // when the article disappeared from its feed
+
 	PublishedAt time.Time
 	PostedAt    time.Time
 	CreatedAt   time.Time
 }
+
+// SearchResult is an article found by a full-text search.
+type SearchResult struct {
+	Article Article
+	// Headline is an excerpt of the article's summary or content with the matches of the query
+	// between HighlightStart and HighlightEnd.
+	Headline string
+	Rank     float64
+}
+
+// Markers of the matches in SearchResult.Headline.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// Channel is a Telegram chat the notifier posts articles to.
+type Channel struct {
+	ID        int64
+	Language  string // target language of summaries, empty means the article's own language
+	Template  string // post template, see package post; empty means the default one
+	ParseMode string // MarkdownV2 or HTML
+	// SendPhoto posts the article's lead image with the post as its caption.
+	SendPhoto bool
+	// LinkPreview is a comma separated list of link preview options:
+	// "disabled", "small", "large" and "above" (show the preview above the text).
+	LinkPreview    string
+	ReadMoreButton bool
+	DiscussURL     string // adds a "Discuss" button opening the URL, e.g. the channel's discussion group
+	Timezone       string // IANA time zone of the channel, UTC by default
+	Moderated      bool   // only articles approved in the moderation chat are posted
+	// Tags is a comma separated list of source tags routing articles to the channel, e.g.
+	// "go,rust,-jobs": only articles of sources with one of the tags and none of the "-" ones
+	// are posted. Empty means articles of all sources.
+	Tags string
+
+	// PostingWindows limits when articles are posted, e.g. "mon-fri 08:00-22:00", see package schedule.
+	PostingWindows string
+	// CatchUp is what happens to articles queued outside the windows, CatchUpSpread by default.
+	CatchUp    string
+	NextPostAt time.Time
+
+	// Digest is "daily" or "weekly" for channels getting digests instead of single posts.
+	Digest         string
+	DigestTime     string       // local time of the digest, e.g. "09:00"
+	DigestWeekday  time.Weekday // day of weekly digests
+	DigestSize     int          // maximum number of articles in a digest
+	DigestOverview bool         // start the digest with an overview written by the summarizer
+	LastDigestAt   time.Time
+
+	CreatedAt time.Time
+}
+
+// DeadLetter is a Bot API request that could not be delivered.
+type DeadLetter struct {
+	ID        int64
+	ChatID    int64
+	Method    string
+	Params    string // JSON encoded request parameters
+	Error     string
+	CreatedAt time.Time
+}
+
+const (
+	ModerationPending   = "pending"
+	ModerationApproved  = "approved"
+	ModerationRejected  = "rejected"
+	ModerationPostponed = "postponed"
+)
+
+const (
+	// CatchUpSpread posts the backlog faster, evenly over the rest of the window.
+	CatchUpSpread = "spread"
+	// CatchUpSkip only posts articles published after the window opened.
+	CatchUpSkip = "skip"
+)
+
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+const (
+	DeliveryClaimed = "claimed"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliveryDeleted = "deleted"
+)
+
+// Kinds of posts, they are edited differently.
+const (
+	PostText   = "text"
+	PostPhoto  = "photo"  // a photo with the post as its caption
+	PostDigest = "digest" // the article is one of many in a digest, the post is not edited
+)
+
+// Delivery is a post of an article to a chat.
+type Delivery struct {
+	ID         int64
+	ArticleID  int64
+	ChatID     int64
+	Status     string
+	Kind       string
+	MessageID  int   // the first message of the post
+	MessageIDs []int // all messages of the post
+	Error      string
+	ClaimedAt  time.Time
+	SentAt     time.Time
+	EditedAt   time.Time
+}
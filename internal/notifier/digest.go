@@ -0,0 +1,259 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/post"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/schedule"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
+)
+
+const (
+	defaultDigestTime = 9 * 60 // minutes since midnight
+	defaultDigestSize = 10
+)
+
+// SendDueDigests sends the digests that are due to channels in digest mode.
+func (n *Notifier) SendDueDigests(ctx context.Context) error {
+	channels, err := n.channelsRepository.Channels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch channels: %w", err)
+	}
+
+	now := time.Now()
+	for _, channel := range channels {
+		if channel.Digest == "" {
+			continue
+		}
+
+		scheduledAt, due := digestDue(channel, now)
+		if !due {
+			continue
+		}
+
+		if err := n.sendDigest(ctx, channel, scheduledAt); err != nil {
+			log.Printf("[ERROR] failed to send digest to %d: %v", channel.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sendDigest sends the digest scheduled at the given time unless another instance claimed it.
+// A digest that fails before its first message is sent is released and sent again in full on
+// the next tick.
+func (n *Notifier) sendDigest(ctx context.Context, channel model.Channel, scheduledAt time.Time) (err error) {
+	claimed, err := n.channelsRepository.ClaimDigest(ctx, channel.ID, scheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to claim digest: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	var sent bool
+	defer func() {
+		if err == nil || sent {
+			return
+		}
+		if releaseErr := n.channelsRepository.ReleaseDigest(context.WithoutCancel(ctx), channel.ID, scheduledAt, channel.LastDigestAt); releaseErr != nil {
+			log.Printf("[ERROR] failed to release digest of %d: %v", channel.ID, releaseErr)
+		}
+	}()
+
+	since := channel.LastDigestAt
+	if since.IsZero() {
+		since = previousDigest(channel, scheduledAt)
+	}
+
+	candidates, err := n.articlesRepository.NotDeliveredTo(ctx, channel.ID, since, maxCandidates)
+	if err != nil {
+		return fmt.Errorf("failed to fetch articles: %w", err)
+	}
+	if len(candidates) == 0 {
+		log.Printf("No articles for the digest of %d", channel.ID)
+		return nil
+	}
+
+	ranked, err := n.rank(ctx, candidates, time.Now())
+	if err != nil {
+		return err
+	}
+	ranked = routed(channel, ranked)
+
+	if channel.Moderated {
+		ranked = approved(ranked)
+	}
+
+	size := channel.DigestSize
+	if size <= 0 {
+		size = defaultDigestSize
+	}
+	ranked = ranked[:min(len(ranked), size)]
+
+	digest := post.Digest{Title: digestTitle(channel, scheduledAt)}
+	for _, item := range ranked {
+		digest.Articles = append(digest.Articles, n.digestArticle(ctx, channel, item))
+	}
+
+	if channel.DigestOverview {
+		digest.Overview = n.digestOverview(channel, digest)
+	}
+
+	parseMode := channel.ParseMode
+	if parseMode == "" {
+		parseMode = markup.ModeMarkdownV2
+	}
+
+	messages, err := post.RenderDigest(digest, parseMode, markup.MaxMessageLength)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	// Once a part is in the channel the digest counts as sent, sending it again would repeat
+	// that part. The parts that went out are recorded.
+	var (
+		messageIDs []int
+		sendErr    error
+	)
+	for _, text := range messages {
+		req := botkit.NewTextRequest(channel.ID, text, parseMode).
+			WithLinkPreview(botkit.LinkPreview{IsDisabled: true})
+
+		msg, err := n.sender.Send(ctx, req)
+		if err != nil {
+			sendErr = fmt.Errorf("failed to send digest message %d of %d: %w", len(messageIDs)+1, len(messages), err)
+			break
+		}
+		messageIDs = append(messageIDs, msg.MessageID)
+		sent = true
+	}
+	if !sent {
+		return sendErr
+	}
+
+	// Articles included in the digest are not included in the next one.
+	for _, item := range ranked {
+		deliveryID, claimed, err := n.deliveriesRepository.Claim(ctx, item.Article.ID, channel.ID, time.Now(), n.claimLease)
+		if err != nil {
+			return fmt.Errorf("failed to record delivery of article %d: %w", item.Article.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+		if err := n.deliveriesRepository.MarkSent(ctx, deliveryID, model.PostDigest, messageIDs); err != nil {
+			return fmt.Errorf("failed to record delivery of article %d: %w", item.Article.ID, err)
+		}
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	log.Printf("Digest with %d articles sent to %d", len(ranked), channel.ID)
+	return nil
+}
+
+// digestArticle returns the digest entry of the article, summarizing it if needed.
+// Articles that can't be summarized are listed without a summary.
+func (n *Notifier) digestArticle(ctx context.Context, channel model.Channel, item rank.Ranked) post.Data {
+	article := item.Article
+	language := n.targetLanguage(channel, article)
+
+	summary, err := n.storedSummary(ctx, article, item.Source.Name, language, func() (string, error) {
+		text, _, err := ExtractText(article)
+		return text, err
+	})
+	if err != nil {
+		log.Printf("[ERROR] failed to summarize article %d for the digest of %d: %v", article.ID, channel.ID, err)
+	}
+
+	return post.Data{
+		Title:       article.Title,
+		Summary:     summary,
+		Link:        article.Link,
+		Source:      item.Source.Name,
+		Language:    language,
+		Tags:        item.Source.Tags,
+		PublishedAt: article.PublishedAt,
+	}
+}
+
+// digestOverview asks the summarizer for an overview of the digest, an empty one is returned on failure.
+func (n *Notifier) digestOverview(channel model.Channel, digest post.Digest) string {
+	var text strings.Builder
+	for _, article := range digest.Articles {
+		fmt.Fprintf(&text, "%s\n%s\n\n", article.Title, article.Summary)
+	}
+
+	language := channel.Language
+	if language == "" && len(digest.Articles) > 0 {
+		language = digest.Articles[0].Language
+	}
+
+	overview, err := n.summarizer.Overview(text.String(), summary.PromptData{
+		Title:        digest.Title,
+		Language:     language,
+		MaxSentences: n.settings().maxSentences,
+	})
+	if err != nil {
+		log.Printf("[ERROR] failed to write the digest overview for %d: %v", channel.ID, err)
+		return ""
+	}
+	return overview
+}
+
+func digestTitle(channel model.Channel, scheduledAt time.Time) string {
+	date := scheduledAt.In(location(channel.Timezone)).Format("2 January")
+	if channel.Digest == model.DigestWeekly {
+		return "Weekly digest, " + date
+	}
+	return "Daily digest, " + date
+}
+
+// digestDue returns the time the latest digest of the channel was scheduled at and whether it wasn't sent yet.
+func digestDue(channel model.Channel, now time.Time) (time.Time, bool) {
+	loc := location(channel.Timezone)
+	local := now.In(loc)
+
+	minutes, err := schedule.ParseClock(channel.DigestTime)
+	if err != nil {
+		minutes = defaultDigestTime
+	}
+	scheduledAt := time.Date(local.Year(), local.Month(), local.Day(), 0, minutes, 0, 0, loc)
+
+	if channel.Digest == model.DigestWeekly {
+		scheduledAt = scheduledAt.AddDate(0, 0, -((int(local.Weekday()) - int(channel.DigestWeekday) + 7) % 7))
+	}
+	if scheduledAt.After(now) {
+		scheduledAt = previousDigest(channel, scheduledAt)
+	}
+
+	return scheduledAt, channel.LastDigestAt.Before(scheduledAt)
+}
+
+// previousDigest returns the time the digest before the one scheduled at the given time was scheduled at.
+func previousDigest(channel model.Channel, scheduledAt time.Time) time.Time {
+	if channel.Digest == model.DigestWeekly {
+		return scheduledAt.AddDate(0, 0, -7)
+	}
+	return scheduledAt.AddDate(0, 0, -1)
+}
+
+// location loads the time zone, falling back to UTC.
+func location(name string) *time.Location {
+	loc, err := schedule.Location(name)
+	if err != nil {
+		log.Printf("[ERROR] unknown time zone %q, using UTC: %v", name, err)
+		return time.UTC
+	}
+	return loc
+}
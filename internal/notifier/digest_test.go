@@ -0,0 +1,133 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestNotifier_SendDueDigests_Failure(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: digestID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	channels := configuredChannels{store.Channels, func(channel *model.Channel) {
+		channel.Digest = model.DigestDaily
+	}}
+	articleID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26"}, "Go 1.26 is released.")
+
+	var sender notifiertest.Sender
+	n := newNotifier(store, &sender, options{channels: channels})
+
+	lastDigest := func() time.Time {
+		t.Helper()
+
+		stored, err := store.Channels.Channels(ctx)
+		if err != nil {
+			t.Fatalf("Channels failed: %v", err)
+		}
+		return stored[0].LastDigestAt
+	}
+
+	sender.FailOn(func(req botkit.Request) error {
+		return errors.New("Too Many Requests")
+	})
+	if err := n.SendDueDigests(ctx); err != nil {
+		t.Fatalf("SendDueDigests failed: %v", err)
+	}
+	if last := lastDigest(); !last.IsZero() {
+		t.Errorf("digest that failed to be sent is recorded as sent at %s", last)
+	}
+	if deliveries, _ := store.Deliveries.Sent(ctx, articleID); len(deliveries) != 0 {
+		t.Errorf("article of the failed digest is recorded as delivered: %+v", deliveries)
+	}
+
+	// The digest is sent on the next tick.
+	sender.FailOn(nil)
+	if err := n.SendDueDigests(ctx); err != nil {
+		t.Fatalf("SendDueDigests failed: %v", err)
+	}
+	if sent := sender.Sent(); len(sent) != 1 || sent[0].ChatID() != digestID {
+		t.Fatalf("got requests %+v, want the digest", sent)
+	}
+	if lastDigest().IsZero() {
+		t.Error("sent digest is not recorded")
+	}
+	if deliveries, _ := store.Deliveries.Sent(ctx, articleID); len(deliveries) != 1 || deliveries[0].Kind != model.PostDigest {
+		t.Errorf("unexpected deliveries of the digest article: %+v", deliveries)
+	}
+
+	if err := n.SendDueDigests(ctx); err != nil {
+		t.Fatalf("SendDueDigests failed: %v", err)
+	}
+	if sent := sender.Sent(); len(sent) != 1 {
+		t.Errorf("digest sent again: %+v", sent[1:])
+	}
+}
+
+func TestNotifier_SendDueDigests_PartlySent(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: digestID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	channels := configuredChannels{store.Channels, func(channel *model.Channel) {
+		channel.Digest = model.DigestDaily
+	}}
+
+	// Every summary fills most of a message, the digest takes three.
+	summary := strings.Repeat("A long summary of the release. ", 100)
+	var articleIDs []int64
+	for i := range 3 {
+		link := fmt.Sprintf("https://go.dev/blog/go1.%d", 26+i)
+		articleIDs = append(articleIDs, storeArticle(t, store, model.Article{Title: fmt.Sprintf("Go 1.%d", 26+i), Link: link}, summary))
+	}
+
+	var (
+		sender   notifiertest.Sender
+		attempts int
+	)
+	sender.FailOn(func(req botkit.Request) error {
+		if attempts++; attempts == 2 {
+			return errors.New("Too Many Requests")
+		}
+		return nil
+	})
+	n := newNotifier(store, &sender, options{channels: channels})
+
+	if err := n.SendDueDigests(ctx); err != nil {
+		t.Fatalf("SendDueDigests failed: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("got %d attempts, want the digest to stop at the failed second message", attempts)
+	}
+
+	// The first part is in the channel, the digest isn't sent again.
+	if err := n.SendDueDigests(ctx); err != nil {
+		t.Fatalf("SendDueDigests failed: %v", err)
+	}
+	if sent := sender.Sent(); len(sent) != 1 {
+		t.Fatalf("got requests %q, want only the first part", requests(sent))
+	}
+	for _, articleID := range articleIDs {
+		deliveries, err := store.Deliveries.Sent(ctx, articleID)
+		if err != nil {
+			t.Fatalf("Sent failed: %v", err)
+		}
+		if len(deliveries) != 1 || deliveries[0].Kind != model.PostDigest || !slices.Equal(deliveries[0].MessageIDs, []int{1}) {
+			t.Errorf("got deliveries %+v of article %d, want the sent part recorded", deliveries, articleID)
+		}
+	}
+}
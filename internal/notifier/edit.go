@@ -0,0 +1,233 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+)
+
+// maxSyncedPosts is how many outdated or retracted posts are edited or deleted per tick.
+const maxSyncedPosts = 20
+
+// EditTitle replaces the title of the article, the feed no longer updates it.
+func (n *Notifier) EditTitle(ctx context.Context, articleID int64, title string) error {
+	if err := n.articlesRepository.UpdateTitle(ctx, articleID, title); err != nil {
+		return fmt.Errorf("failed to update title of article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// SetSummary replaces the summary of the article in the language.
+func (n *Notifier) SetSummary(ctx context.Context, articleID int64, language, summary string) error {
+	if err := n.articlesRepository.StoreSummary(ctx, articleID, language, summary); err != nil {
+		return fmt.Errorf("failed to store summary of article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// EditPosts renders the posts of the article again with its current title and summaries and
+// the current channel templates. It returns the number of edited posts.
+func (n *Notifier) EditPosts(ctx context.Context, articleID int64) (int, error) {
+	item, err := n.itemByID(ctx, articleID)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveries, err := n.deliveriesRepository.Sent(ctx, articleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch posts of article %d: %w", articleID, err)
+	}
+
+	var edited int
+	for _, delivery := range deliveries {
+		if delivery.Kind == model.PostDigest {
+			continue
+		}
+
+		if err := n.editPost(ctx, delivery, item); err != nil {
+			return edited, err
+		}
+		edited++
+	}
+
+	return edited, nil
+}
+
+// DeletePosts deletes the posts of the article and keeps it from being posted again.
+// It returns the number of deleted posts.
+func (n *Notifier) DeletePosts(ctx context.Context, articleID int64) (int, error) {
+	if err := n.Skip(ctx, articleID); err != nil {
+		return 0, err
+	}
+
+	deliveries, err := n.deliveriesRepository.Sent(ctx, articleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch posts of article %d: %w", articleID, err)
+	}
+
+	var deleted int
+	for _, delivery := range deliveries {
+		if delivery.Kind == model.PostDigest {
+			continue
+		}
+
+		if err := n.deletePost(ctx, delivery); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// SyncPosts edits the posts of articles whose title changed in the feed and deletes the posts
+// of articles removed from their feeds if deleting retracted posts is enabled.
+// Posts that can't be edited or deleted are not retried.
+func (n *Notifier) SyncPosts(ctx context.Context) error {
+	outdated, err := n.deliveriesRepository.Outdated(ctx, maxSyncedPosts)
+	if err != nil {
+		return fmt.Errorf("failed to fetch outdated posts: %w", err)
+	}
+
+	for _, delivery := range outdated {
+		item, err := n.itemByID(ctx, delivery.ArticleID)
+		if err == nil {
+			err = n.editPost(ctx, delivery, item)
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		log.Printf("[ERROR] failed to edit post of article %d in %d: %v", delivery.ArticleID, delivery.ChatID, err)
+		if err := n.deliveriesRepository.MarkEdited(ctx, delivery.ID, delivery.MessageIDs); err != nil {
+			return fmt.Errorf("failed to mark post %d as edited: %w", delivery.ID, err)
+		}
+	}
+
+	if !n.settings().deleteRetracted {
+		return nil
+	}
+
+	retracted, err := n.deliveriesRepository.Retracted(ctx, maxSyncedPosts)
+	if err != nil {
+		return fmt.Errorf("failed to fetch retracted posts: %w", err)
+	}
+
+	for _, delivery := range retracted {
+		err := n.deletePost(ctx, delivery)
+		if err == nil {
+			log.Printf("Deleted post of retracted article %d in %d", delivery.ArticleID, delivery.ChatID)
+			continue
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		log.Printf("[ERROR] failed to delete post of retracted article %d in %d: %v", delivery.ArticleID, delivery.ChatID, err)
+		if err := n.deliveriesRepository.MarkFailed(ctx, delivery.ID, err.Error(), delivery.MessageIDs); err != nil {
+			return fmt.Errorf("failed to mark post %d as failed: %w", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// editPost replaces the messages of the post with the post rendered again. A text post can't grow:
+// if the new post needs more messages than were sent, the post is not edited.
+func (n *Notifier) editPost(ctx context.Context, delivery model.Delivery, item rank.Ranked) error {
+	if len(delivery.MessageIDs) == 0 {
+		return fmt.Errorf("post %d has no messages", delivery.ID)
+	}
+
+	channel, err := n.channel(ctx, delivery.ChatID)
+	if err != nil {
+		return err
+	}
+
+	article, data, err := n.prepare(ctx, channel, item)
+	if err != nil {
+		return err
+	}
+	keyboard := buttons(channel, article.Link, data.Language)
+
+	messageIDs := delivery.MessageIDs
+	switch delivery.Kind {
+	case model.PostPhoto:
+		caption, parseMode, err := n.renderCaption(channel, data)
+		if err != nil {
+			return err
+		}
+
+		req := botkit.NewEditCaptionRequest(channel.ID, messageIDs[0], caption, parseMode).WithReplyMarkup(keyboard)
+		if _, err := n.sender.Send(ctx, req); err != nil {
+			return fmt.Errorf("failed to edit caption: %w", err)
+		}
+	default:
+		messages, parseMode, err := n.renderText(channel, data)
+		if err != nil {
+			return err
+		}
+		if len(messages) > len(messageIDs) {
+			return fmt.Errorf("edited post needs %d messages, the post has %d", len(messages), len(messageIDs))
+		}
+
+		preview := textPreview(channel, article)
+		for i, text := range messages {
+			req := botkit.NewEditTextRequest(channel.ID, messageIDs[i], text, parseMode).WithLinkPreview(preview)
+			if i == len(messages)-1 {
+				req = req.WithReplyMarkup(keyboard)
+			}
+
+			if _, err := n.sender.Send(ctx, req); err != nil {
+				return fmt.Errorf("failed to edit message: %w", err)
+			}
+		}
+
+		for _, id := range messageIDs[len(messages):] {
+			if _, err := n.sender.Send(ctx, botkit.NewDeleteRequest(channel.ID, id)); err != nil {
+				return fmt.Errorf("failed to delete message: %w", err)
+			}
+		}
+		messageIDs = messageIDs[:len(messages)]
+	}
+
+	if err := n.deliveriesRepository.MarkEdited(ctx, delivery.ID, messageIDs); err != nil {
+		return fmt.Errorf("failed to mark post %d as edited: %w", delivery.ID, err)
+	}
+
+	log.Printf("Edited post of article %d in %d", article.ID, channel.ID)
+	return nil
+}
+
+func (n *Notifier) deletePost(ctx context.Context, delivery model.Delivery) error {
+	for _, id := range delivery.MessageIDs {
+		if _, err := n.sender.Send(ctx, botkit.NewDeleteRequest(delivery.ChatID, id)); err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+	}
+
+	if err := n.deliveriesRepository.MarkDeleted(ctx, delivery.ID); err != nil {
+		return fmt.Errorf("failed to mark post %d as deleted: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// itemByID returns the article with the given ID with its source.
+func (n *Notifier) itemByID(ctx context.Context, articleID int64) (rank.Ranked, error) {
+	article, err := n.articlesRepository.ArticleByID(ctx, articleID)
+	if err != nil {
+		return rank.Ranked{}, fmt.Errorf("failed to fetch article %d: %w", articleID, err)
+	}
+	if article == nil {
+		return rank.Ranked{}, fmt.Errorf("article %d not found", articleID)
+	}
+
+	return n.manualItem(ctx, *article)
+}
@@ -0,0 +1,175 @@
+package notifier_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+// storeArticle stores the article with its English summary, so that it isn't downloaded
+// for summarizing, and returns its ID.
+func storeArticle(t *testing.T, store *storage.Storage, article model.Article, summary string) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	article.Language = "en"
+	if article.PublishedAt.IsZero() {
+		article.PublishedAt = time.Now().Add(-time.Hour).UTC()
+	}
+	if err := store.Articles.Store(ctx, article); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	stored, err := store.Articles.ArticleByLink(ctx, article.Link)
+	if err != nil || stored == nil {
+		t.Fatalf("ArticleByLink() = %v, %v", stored, err)
+	}
+	if err := store.Articles.StoreSummary(ctx, stored.ID, "en", summary); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+	return stored.ID
+}
+
+// storePost records the article as posted to the chat with the messages.
+func storePost(t *testing.T, store *storage.Storage, articleID, chatID int64, kind string, messageIDs ...int) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: chatID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	id, ok, err := store.Deliveries.Claim(ctx, articleID, chatID, time.Now(), time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Claim() = %v, %v", ok, err)
+	}
+	if err := store.Deliveries.MarkSent(ctx, id, kind, messageIDs); err != nil {
+		t.Fatalf("MarkSent failed: %v", err)
+	}
+}
+
+// requests returns the method and the message ID of every request.
+func requests(sent []botkit.Request) []string {
+	var calls []string
+	for _, req := range sent {
+		calls = append(calls, req.Method+" "+req.Params["message_id"])
+	}
+	return calls
+}
+
+func TestNotifier_EditPosts_Shrink(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if _, err := store.Sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom", Tags: []string{"go"}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Added by an admin by link, so it has no source.
+	articleID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://www.go.dev/blog/go1.26"}, "Go 1.26 is released.")
+	storePost(t, store, articleID, channelID, model.PostText, 11, 12, 13)
+
+	var sender notifiertest.Sender
+	edited, err := newNotifier(store, &sender, options{deleteRetracted: true}).EditPosts(ctx, articleID)
+	if err != nil || edited != 1 {
+		t.Fatalf("EditPosts() = %d, %v", edited, err)
+	}
+
+	sent := sender.Sent()
+	want := []string{"editMessageText 11", "deleteMessage 12", "deleteMessage 13"}
+	if got := requests(sent); !slices.Equal(got, want) {
+		t.Fatalf("got requests %q, want %q", got, want)
+	}
+	if text := sent[0].Params["text"]; !strings.HasSuffix(text, `\#go`) {
+		t.Errorf("post %q isn't posted as an article of the source on the same host", text)
+	}
+
+	deliveries, err := store.Deliveries.Sent(ctx, articleID)
+	if err != nil {
+		t.Fatalf("Sent failed: %v", err)
+	}
+	if len(deliveries) != 1 || !slices.Equal(deliveries[0].MessageIDs, []int{11}) {
+		t.Errorf("deleted messages are still recorded: %+v", deliveries)
+	}
+}
+
+func TestNotifier_EditPosts_Grow(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	summary := strings.Repeat("The summary now needs more than one message. ", 200)
+	articleID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26"}, summary)
+	storePost(t, store, articleID, channelID, model.PostText, 11)
+
+	var sender notifiertest.Sender
+	if _, err := newNotifier(store, &sender, options{deleteRetracted: true}).EditPosts(ctx, articleID); err == nil {
+		t.Fatal("EditPosts succeeded for a post that needs more messages")
+	}
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Errorf("the post was partly edited: %q", requests(sent))
+	}
+}
+
+func TestNotifier_SyncPosts(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	outdatedID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26"}, "Go 1.26 is released.")
+	storePost(t, store, outdatedID, channelID, model.PostText, 11)
+	storePost(t, store, outdatedID, digestID, model.PostDigest, 21)
+
+	retractedID := storeArticle(t, store, model.Article{Title: "Go 1.27", Link: "https://go.dev/blog/go1.27"}, "Go 1.27 is released.")
+	storePost(t, store, retractedID, channelID, model.PostText, 12, 13)
+	storePost(t, store, retractedID, digestID, model.PostDigest, 21)
+
+	if err := store.Articles.UpdateTitle(ctx, outdatedID, "Go 1.26 is released"); err != nil {
+		t.Fatalf("UpdateTitle failed: %v", err)
+	}
+	if err := store.Articles.MarkRetracted(ctx, retractedID); err != nil {
+		t.Fatalf("MarkRetracted failed: %v", err)
+	}
+
+	var sender notifiertest.Sender
+	n := newNotifier(store, &sender, options{deleteRetracted: true})
+	if err := n.SyncPosts(ctx); err != nil {
+		t.Fatalf("SyncPosts failed: %v", err)
+	}
+
+	// Digests list many articles, they are neither edited nor deleted.
+	sent := sender.Sent()
+	want := []string{"editMessageText 11", "deleteMessage 12", "deleteMessage 13"}
+	if got := requests(sent); !slices.Equal(got, want) {
+		t.Fatalf("got requests %q, want %q", got, want)
+	}
+	for _, req := range sent {
+		if req.ChatID() != channelID {
+			t.Errorf("%s sent to %d", req.Method, req.ChatID())
+		}
+	}
+	if text := sent[0].Params["text"]; !strings.Contains(text, "Go 1\\.26 is released") {
+		t.Errorf("edited post %q doesn't have the new title", text)
+	}
+
+	// Synced posts are not synced again.
+	if err := n.SyncPosts(ctx); err != nil {
+		t.Fatalf("SyncPosts failed: %v", err)
+	}
+	if got := len(sender.Sent()); got != len(want) {
+		t.Errorf("second SyncPosts sent %d more requests", got-len(want))
+	}
+
+	deleted, err := n.DeletePosts(ctx, outdatedID)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeletePosts() = %d, %v", deleted, err)
+	}
+	if got := requests(sender.Sent()[len(want):]); !slices.Equal(got, []string{"deleteMessage 11"}) {
+		t.Errorf("DeletePosts sent %q, want only the post to be deleted", got)
+	}
+}
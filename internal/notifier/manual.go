@@ -0,0 +1,205 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+)
+
+// Article returns the article with the given ID or link. An unknown link is fetched and stored
+// as an article without a source; it is posted as an article of the source with the same host,
+// see manualItem.
+func (n *Notifier) Article(ctx context.Context, ref string) (model.Article, error) {
+	ref = strings.TrimSpace(ref)
+
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		article, err := n.articlesRepository.ArticleByID(ctx, id)
+		if err != nil {
+			return model.Article{}, fmt.Errorf("failed to fetch article %d: %w", id, err)
+		}
+		if article == nil {
+			return model.Article{}, fmt.Errorf("article %d not found", id)
+		}
+		return *article, nil
+	}
+
+	article, err := n.articlesRepository.ArticleByLink(ctx, ref)
+	if err != nil {
+		return model.Article{}, fmt.Errorf("failed to fetch article %s: %w", ref, err)
+	}
+	if article != nil {
+		return *article, nil
+	}
+
+	item, err := source.FetchPage(ctx, ref)
+	if err != nil {
+		return model.Article{}, err
+	}
+
+	if err := n.articlesRepository.Store(ctx, model.Article{
+		Title:       item.Title,
+		Link:        item.Link,
+		Summary:     item.Summary,
+		Language:    lang.Detect(item.Title + "\n" + item.Summary),
+		ImageURL:    item.ImageURL,
+		PublishedAt: item.Date.UTC(),
+	}); err != nil {
+		return model.Article{}, fmt.Errorf("failed to store article %s: %w", ref, err)
+	}
+
+	article, err = n.articlesRepository.ArticleByLink(ctx, item.Link)
+	if err != nil {
+		return model.Article{}, fmt.Errorf("failed to fetch stored article %s: %w", ref, err)
+	}
+	if article == nil {
+		return model.Article{}, fmt.Errorf("article %s not found after storing it", ref)
+	}
+
+	log.Printf("Article added by link: %s", article.Title)
+	return *article, nil
+}
+
+// PostNow posts the article to the chat right away, regardless of the queue, posting windows and moderation.
+// An earlier delivery that failed is retried. ErrAlreadyPosted is returned if the article is posted to the
+// chat already.
+func (n *Notifier) PostNow(ctx context.Context, chatID int64, article model.Article) error {
+	channel, err := n.channel(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	item, err := n.manualItem(ctx, article)
+	if err != nil {
+		return err
+	}
+
+	return n.post(ctx, channel, item, true)
+}
+
+// Schedule posts the article to every channel at the given time instead of in its turn.
+func (n *Notifier) Schedule(ctx context.Context, articleID int64, at time.Time) error {
+	if err := n.articlesRepository.Schedule(ctx, articleID, at); err != nil {
+		return fmt.Errorf("failed to schedule article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// Skip keeps the article from being posted.
+func (n *Notifier) Skip(ctx context.Context, articleID int64) error {
+	if err := n.articlesRepository.Skip(ctx, articleID); err != nil {
+		return fmt.Errorf("failed to skip article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// Scheduled returns the scheduled articles not posted to the chat yet in the order they are going to be posted.
+func (n *Notifier) Scheduled(ctx context.Context, chatID int64) ([]model.Article, error) {
+	articles, err := n.articlesRepository.Scheduled(ctx, chatID, time.Now().Add(-n.settings().lookupTimeWindow), maxCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch scheduled articles: %w", err)
+	}
+	return articles, nil
+}
+
+// SendScheduled posts the articles whose scheduled time has come to every channel not in digest mode.
+// Articles scheduled longer than the lookup time window ago are not posted to channels added since.
+func (n *Notifier) SendScheduled(ctx context.Context) error {
+	channels, err := n.channelsRepository.Channels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch channels: %w", err)
+	}
+
+	now := time.Now()
+	for _, channel := range channels {
+		if channel.Digest != "" {
+			continue
+		}
+
+		articles, err := n.Scheduled(ctx, channel.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, article := range articles {
+			if article.ScheduledAt.After(now) {
+				break
+			}
+
+			item, err := n.manualItem(ctx, article)
+			if err != nil {
+				return err
+			}
+
+			if err := n.post(ctx, channel, item, false); err != nil && !errors.Is(err, ErrAlreadyPosted) {
+				log.Printf("[ERROR] failed to post scheduled article %d to %d: %v", article.ID, channel.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// channel returns the channel with the given ID, chats missing in the channels table get the default settings.
+func (n *Notifier) channel(ctx context.Context, chatID int64) (model.Channel, error) {
+	channels, err := n.channelsRepository.Channels(ctx)
+	if err != nil {
+		return model.Channel{}, fmt.Errorf("failed to fetch channels: %w", err)
+	}
+
+	for _, channel := range channels {
+		if channel.ID == chatID {
+			return channel, nil
+		}
+	}
+	return model.Channel{ID: chatID}, nil
+}
+
+// manualItem returns the article with its source. Articles added by admins by link are stored
+// without a source, so that the fetcher never retracts them as missing from the source's feed,
+// and are posted as articles of the source whose feed is on the same host, if there is one.
+func (n *Notifier) manualItem(ctx context.Context, article model.Article) (rank.Ranked, error) {
+	item := rank.Ranked{Article: article}
+
+	sources, err := n.sourcesRepository.Sources(ctx)
+	if err != nil {
+		return item, fmt.Errorf("failed to fetch sources: %w", err)
+	}
+
+	if article.SourceID == 0 {
+		item.Source = sourceOfLink(article.Link, sources)
+		return item, nil
+	}
+
+	for _, source := range sources {
+		if source.ID == article.SourceID {
+			item.Source = source
+		}
+	}
+	return item, nil
+}
+
+// sourceOfLink returns the source whose feed is on the same host as the link or a zero source.
+func sourceOfLink(link string, sources []model.Source) model.Source {
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return model.Source{}
+	}
+
+	for _, source := range sources {
+		feedURL, err := url.Parse(source.FeedURL)
+		if err == nil && strings.TrimPrefix(feedURL.Hostname(), "www.") == strings.TrimPrefix(linkURL.Hostname(), "www.") {
+			return source
+		}
+	}
+	return model.Source{}
+}
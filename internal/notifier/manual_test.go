@@ -0,0 +1,46 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestNotifier_PostNow_Failed(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	articleID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26"}, "Go 1.26 is released.")
+	article, err := store.Articles.ArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatalf("ArticleByID failed: %v", err)
+	}
+
+	var sender notifiertest.Sender
+	sender.FailOn(func(req botkit.Request) error { return errors.New("Bad Request: chat not found") })
+	n := newNotifier(store, &sender, options{})
+	if err := n.PostNow(ctx, channelID, *article); err == nil {
+		t.Fatal("PostNow succeeded with a failing sender")
+	}
+
+	// Posting again is how an admin retries a failed delivery.
+	sender.FailOn(nil)
+	if err := n.PostNow(ctx, channelID, *article); err != nil {
+		t.Fatalf("PostNow of a failed delivery failed: %v", err)
+	}
+	if sent := sender.Sent(); len(sent) != 1 || sent[0].ChatID() != channelID {
+		t.Errorf("got requests %q, want the post", requests(sent))
+	}
+	if err := n.PostNow(ctx, channelID, *article); !errors.Is(err, notifier.ErrAlreadyPosted) {
+		t.Errorf("PostNow of a posted article returned %v, want ErrAlreadyPosted", err)
+	}
+}
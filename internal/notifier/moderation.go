@@ -0,0 +1,176 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxPendingModeration is how many articles wait for a decision in the moderation chat at once.
+	maxPendingModeration = 5
+	// postponeInterval is how long a postponed article waits before it is sent for moderation again.
+	postponeInterval = 6 * time.Hour
+)
+
+// Moderation actions, the callback data of the buttons is "mod:<action>:<article ID>[:<language>]".
+const (
+	ModerationCallbackPrefix = "mod"
+
+	ActionApprove  = "approve"
+	ActionEdit     = "edit"
+	ActionReject   = "reject"
+	ActionPostpone = "postpone"
+)
+
+// SendForModeration sends the best articles queued for moderated channels to the moderation chat,
+// keeping at most maxPendingModeration of them waiting for a decision.
+func (n *Notifier) SendForModeration(ctx context.Context) error {
+	if n.moderationChatID == 0 {
+		return nil
+	}
+
+	channels, err := n.channelsRepository.Channels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch channels: %w", err)
+	}
+
+	idx := slices.IndexFunc(channels, func(channel model.Channel) bool { return channel.Moderated })
+	if idx == -1 {
+		return nil
+	}
+	// Previews are rendered like posts to the first moderated channel.
+	channel := channels[idx]
+
+	pending, err := n.articlesRepository.CountModeration(ctx, model.ModerationPending)
+	if err != nil {
+		return fmt.Errorf("failed to count pending articles: %w", err)
+	}
+	if pending >= maxPendingModeration {
+		return nil
+	}
+
+	queue, err := n.Queue(ctx, channel.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, item := range queue {
+		if pending >= maxPendingModeration {
+			break
+		}
+		if !awaitsModeration(item.Article, now) {
+			continue
+		}
+
+		claimed, err := n.articlesRepository.ClaimModeration(ctx, item.Article.ID, now)
+		if err != nil {
+			return fmt.Errorf("failed to claim moderation of article %d: %w", item.Article.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := n.sendPreview(ctx, channel, item); err != nil {
+			log.Printf("[ERROR] failed to send article %d for moderation: %v", item.Article.ID, err)
+
+			// Let the article be sent again on the next tick.
+			if err := n.articlesRepository.SetModerationStatus(context.WithoutCancel(ctx), item.Article.ID, "", time.Time{}); err != nil {
+				log.Printf("[ERROR] failed to reset moderation of article %d: %v", item.Article.ID, err)
+			}
+			continue
+		}
+		pending++
+	}
+
+	return nil
+}
+
+// sendPreview sends the post of the article as it would look in the channel with the moderation buttons.
+func (n *Notifier) sendPreview(ctx context.Context, channel model.Channel, item rank.Ranked) error {
+	article, data, err := n.prepare(ctx, channel, item)
+	if err != nil {
+		return err
+	}
+
+	preview := channel
+	preview.ID = n.moderationChatID
+
+	_, _, err = n.sendArticle(ctx, preview, article, data, moderationButtons(article.ID, data.Language))
+	return err
+}
+
+// Approve lets the article be posted to moderated channels.
+func (n *Notifier) Approve(ctx context.Context, articleID int64) error {
+	return n.setModerationStatus(ctx, articleID, model.ModerationApproved, time.Time{})
+}
+
+// Reject keeps the article from being posted to moderated channels.
+func (n *Notifier) Reject(ctx context.Context, articleID int64) error {
+	return n.setModerationStatus(ctx, articleID, model.ModerationRejected, time.Time{})
+}
+
+// Postpone sends the article for moderation again later and returns when.
+func (n *Notifier) Postpone(ctx context.Context, articleID int64) (time.Time, error) {
+	until := time.Now().Add(postponeInterval)
+	return until, n.setModerationStatus(ctx, articleID, model.ModerationPostponed, until)
+}
+
+// EditSummary replaces the summary of the article in the language and approves the article.
+func (n *Notifier) EditSummary(ctx context.Context, articleID int64, language, summary string) error {
+	if err := n.SetSummary(ctx, articleID, language, summary); err != nil {
+		return err
+	}
+	return n.Approve(ctx, articleID)
+}
+
+func (n *Notifier) setModerationStatus(ctx context.Context, articleID int64, status string, postponedUntil time.Time) error {
+	if err := n.articlesRepository.SetModerationStatus(ctx, articleID, status, postponedUntil); err != nil {
+		return fmt.Errorf("failed to set moderation status of article %d: %w", articleID, err)
+	}
+	return nil
+}
+
+// awaitsModeration reports whether the article should be sent to the moderation chat.
+func awaitsModeration(article model.Article, now time.Time) bool {
+	switch article.ModerationStatus {
+	case "":
+		return true
+	case model.ModerationPostponed:
+		return !article.PostponedUntil.After(now)
+	default:
+		return false
+	}
+}
+
+// approved returns the approved articles of the queue.
+func approved(queue []rank.Ranked) []rank.Ranked {
+	return slices.DeleteFunc(queue, func(item rank.Ranked) bool {
+		return item.Article.ModerationStatus != model.ModerationApproved
+	})
+}
+
+func moderationButtons(articleID int64, language string) tgbotapi.InlineKeyboardMarkup {
+	data := func(action string) string {
+		return ModerationCallbackPrefix + ":" + action + ":" + strconv.FormatInt(articleID, 10)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Approve", data(ActionApprove)),
+			tgbotapi.NewInlineKeyboardButtonData("Edit summary", data(ActionEdit)+":"+language),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Reject", data(ActionReject)),
+			tgbotapi.NewInlineKeyboardButtonData("Postpone", data(ActionPostpone)),
+		),
+	)
+}
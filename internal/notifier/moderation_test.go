@@ -0,0 +1,142 @@
+package notifier_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+func TestNotifier_Moderation(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en", Moderated: true}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	var ids []int64
+	for i := range 7 {
+		article := model.Article{
+			Title:       fmt.Sprintf("Go news %d", i+1),
+			Link:        fmt.Sprintf("https://go.dev/blog/%d", i+1),
+			PublishedAt: time.Now().Add(-time.Duration(i+1) * time.Minute).UTC(),
+		}
+		ids = append(ids, storeArticle(t, store, article, "Summary."))
+	}
+
+	var sender notifiertest.Sender
+	n := newNotifier(store, &sender, options{moderationChatID: moderationID})
+
+	status := func(id int64) string {
+		t.Helper()
+
+		article, err := store.Articles.ArticleByID(ctx, id)
+		if err != nil || article == nil {
+			t.Fatalf("ArticleByID() = %v, %v", article, err)
+		}
+		return article.ModerationStatus
+	}
+	sendForModeration := func(want int) {
+		t.Helper()
+
+		before := len(sender.Sent())
+		if err := n.SendForModeration(ctx); err != nil {
+			t.Fatalf("SendForModeration failed: %v", err)
+		}
+
+		sent := sender.Sent()[before:]
+		if len(sent) != want {
+			t.Fatalf("got %d previews, want %d", len(sent), want)
+		}
+		for i, req := range sent {
+			if req.ChatID() != moderationID || !strings.Contains(req.Params["reply_markup"], "mod:approve:") {
+				t.Errorf("preview %d: unexpected request %+v", i, req)
+			}
+		}
+	}
+	sendDuePost := func() string {
+		t.Helper()
+
+		before := len(sender.Sent())
+		if err := n.SendDuePosts(ctx); err != nil {
+			t.Fatalf("SendDuePosts failed: %v", err)
+		}
+
+		sent := sender.Sent()[before:]
+		if len(sent) == 0 {
+			return ""
+		}
+		if len(sent) != 1 || sent[0].ChatID() != channelID {
+			t.Fatalf("got requests %+v, want one post", sent)
+		}
+		return sent[0].Params["text"]
+	}
+
+	// Only a few articles wait for a decision at once.
+	sendForModeration(5)
+	var pending []int64
+	for _, id := range ids {
+		if status(id) == model.ModerationPending {
+			pending = append(pending, id)
+		}
+	}
+	if len(pending) != 5 {
+		t.Fatalf("%d articles are pending, want 5", len(pending))
+	}
+	sendForModeration(0)
+	if text := sendDuePost(); text != "" {
+		t.Fatalf("article posted before it was approved: %q", text)
+	}
+
+	approvedID, rejectedID, postponedID, editedID := pending[0], pending[1], pending[2], pending[3]
+	if err := n.Approve(ctx, approvedID); err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if err := n.Reject(ctx, rejectedID); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	until, err := n.Postpone(ctx, postponedID)
+	if err != nil {
+		t.Fatalf("Postpone failed: %v", err)
+	}
+	if wait := time.Until(until); wait < 5*time.Hour || wait > 6*time.Hour {
+		t.Errorf("postponed for %s, want 6h", wait)
+	}
+	if err := n.EditSummary(ctx, editedID, "en", "Edited summary."); err != nil {
+		t.Fatalf("EditSummary failed: %v", err)
+	}
+
+	for id, want := range map[int64]string{
+		approvedID:  model.ModerationApproved,
+		rejectedID:  model.ModerationRejected,
+		postponedID: model.ModerationPostponed,
+		editedID:    model.ModerationApproved,
+	} {
+		if got := status(id); got != want {
+			t.Errorf("article %d: moderation status %q, want %q", id, got, want)
+		}
+	}
+
+	// Decided articles make room for the two not sent for moderation yet.
+	sendForModeration(2)
+
+	// Only approved articles are posted, with the edited summary.
+	var posts []string
+	for range 3 {
+		if text := sendDuePost(); text != "" {
+			posts = append(posts, text)
+		}
+	}
+	if len(posts) != 2 {
+		t.Fatalf("got posts %q, want the two approved articles", posts)
+	}
+	if !strings.Contains(strings.Join(posts, "\n"), `Edited summary\.`) {
+		t.Errorf("posts %q don't have the edited summary", posts)
+	}
+}
@@ -2,65 +2,193 @@ package notifier
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
 	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
 	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/post"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/schedule"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
 	"github.com/go-shiori/go-readability"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// ErrAlreadyPosted is returned when the article is posted or being posted to the chat.
+var ErrAlreadyPosted = errors.New("article is already posted to the chat")
+
 type ArticlesRepository interface {
 	AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
 	MarkPosted(ctx context.Context, articleID int64) error
+	PostedCountsBySource(ctx context.Context, since time.Time) (map[int64]int, error)
+	NotDeliveredTo(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error)
+	Summary(ctx context.Context, articleID int64, language string) (string, error)
+	StoreSummary(ctx context.Context, articleID int64, language, summary string) error
+	ClaimModeration(ctx context.Context, articleID int64, now time.Time) (bool, error)
+	SetModerationStatus(ctx context.Context, articleID int64, status string, postponedUntil time.Time) error
+	CountModeration(ctx context.Context, status string) (int, error)
+	Store(ctx context.Context, article model.Article) error
+	ArticleByID(ctx context.Context, id int64) (*model.Article, error)
+	ArticleByLink(ctx context.Context, link string) (*model.Article, error)
+	Scheduled(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error)
+	Schedule(ctx context.Context, articleID int64, at time.Time) error
+	Skip(ctx context.Context, articleID int64) error
+	UpdateTitle(ctx context.Context, articleID int64, title string) error
+}
+
+type SourcesRepository interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+}
+
+type DeliveriesRepository interface {
+	Claim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error)
+	Reclaim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error)
+	Release(ctx context.Context, id int64) error
+	MarkSent(ctx context.Context, id int64, kind string, messageIDs []int) error
+	MarkFailed(ctx context.Context, id int64, reason string, messageIDs []int) error
+	MarkEdited(ctx context.Context, id int64, messageIDs []int) error
+	MarkDeleted(ctx context.Context, id int64) error
+	Sent(ctx context.Context, articleID int64) ([]model.Delivery, error)
+	Outdated(ctx context.Context, limit uint64) ([]model.Delivery, error)
+	Retracted(ctx context.Context, limit uint64) ([]model.Delivery, error)
+}
+
+type ChannelsRepository interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+	ClaimSlot(ctx context.Context, id int64, now, until time.Time) (bool, error)
+	SetNextPost(ctx context.Context, id int64, at time.Time) error
+	ClaimDigest(ctx context.Context, id int64, scheduledAt time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, id int64, scheduledAt, previous time.Time) error
 }
 
 type Summarizer interface {
-	Summarizer(text string) (string, error)
+	Summarizer(text string, data summary.PromptData) (string, error)
+	Overview(text string, data summary.PromptData) (string, error)
+}
+
+// Sender delivers Bot API requests, e.g. delivery.Queue.
+type Sender interface {
+	Send(ctx context.Context, req botkit.Request) (tgbotapi.Message, error)
 }
 
 type Notifier struct {
-	articlesRepository ArticlesRepository
-	summarizer         Summarizer
-	bot                *tgbotapi.BotAPI
-	sendInterval       time.Duration
-	lookupTimeWindow   time.Duration
-	channelID          int64
+	articlesRepository   ArticlesRepository
+	sourcesRepository    SourcesRepository
+	channelsRepository   ChannelsRepository
+	deliveriesRepository DeliveriesRepository
+	summarizer           Summarizer
+	sender               Sender
+	moderationChatID     int64
+	claimLease           time.Duration
+
+	mu      sync.RWMutex
+	current settings
+	dryRun  bool // summaries are not stored, see Preview
 }
 
-func New(articlesRepository ArticlesRepository, summarizer Summarizer, bot *tgbotapi.BotAPI, sendInterval, lookupTimeWindow time.Duration, channelID int64) *Notifier {
+// settings can be changed while the notifier runs, see Reconfigure.
+type settings struct {
+	ranker           rank.Ranker
+	sendInterval     time.Duration
+	lookupTimeWindow time.Duration
+	maxSentences     int
+	deleteRetracted  bool
+}
+
+func New(
+	articlesRepository ArticlesRepository,
+	sourcesRepository SourcesRepository,
+	channelsRepository ChannelsRepository,
+	deliveriesRepository DeliveriesRepository,
+	summarizer Summarizer,
+	ranker rank.Ranker,
+	sender Sender,
+	moderationChatID int64,
+	sendInterval, lookupTimeWindow, claimLease time.Duration,
+	maxSentences int,
+	deleteRetracted bool,
+) *Notifier {
 	return &Notifier{
-		articlesRepository: articlesRepository,
-		summarizer:         summarizer,
-		bot:                bot,
-		sendInterval:       sendInterval,
-		lookupTimeWindow:   lookupTimeWindow,
-		channelID:          channelID,
+		articlesRepository:   articlesRepository,
+		sourcesRepository:    sourcesRepository,
+		channelsRepository:   channelsRepository,
+		deliveriesRepository: deliveriesRepository,
+		summarizer:           summarizer,
+		sender:               sender,
+		moderationChatID:     moderationChatID,
+		claimLease:           claimLease,
+		current: settings{
+			ranker:           ranker,
+			sendInterval:     sendInterval,
+			lookupTimeWindow: lookupTimeWindow,
+			maxSentences:     maxSentences,
+			deleteRetracted:  deleteRetracted,
+		},
+	}
+}
+
+// Reconfigure changes the settings of a running notifier, they apply from the next post.
+func (n *Notifier) Reconfigure(
+	ranker rank.Ranker,
+	sendInterval, lookupTimeWindow time.Duration,
+	maxSentences int,
+	deleteRetracted bool,
+) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.current = settings{
+		ranker:           ranker,
+		sendInterval:     sendInterval,
+		lookupTimeWindow: lookupTimeWindow,
+		maxSentences:     maxSentences,
+		deleteRetracted:  deleteRetracted,
 	}
 }
 
+func (n *Notifier) settings() settings {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.current
+}
+
+const (
+	// tickInterval is how often channels are checked for due posts and digests.
+	tickInterval = time.Minute
+	// maxCandidates is how many of the newest unposted articles are ranked.
+	maxCandidates = 200
+	// recentPostsWindow is the period posts are counted in to let other sources take turns.
+	recentPostsWindow = 24 * time.Hour
+	// minCatchUpInterval is the shortest interval between posts when catching up with a backlog.
+	minCatchUpInterval = 5 * time.Minute
+)
+
+// Start sends due posts and digests every minute until the context is done. Posts being
+// summarized or sent when it is done are finished rather than cut off.
 func (n *Notifier) Start(ctx context.Context) {
-	ticker := time.NewTicker(n.sendInterval)
+	ticker := time.NewTicker(tickInterval)
 	defer ticker.Stop()
-	log.Println("Notifier started, will send articles every", n.sendInterval)
+	log.Println("Notifier started, will send articles every", n.settings().sendInterval)
 
-	if err := n.SelectAndSendArticle(ctx); err != nil {
-		log.Printf("initial article selection failed: %v", err)
-	}
+	n.RunOnce(context.WithoutCancel(ctx))
 
 	for {
 		select {
 		case <-ticker.C:
-			if err := n.SelectAndSendArticle(ctx); err != nil {
-				log.Printf("failed to select and send article: %v", err)
-			}
+			n.RunOnce(context.WithoutCancel(ctx))
 		case <-ctx.Done():
 			log.Println("Notifier stopped:", ctx.Err())
 			return
@@ -68,33 +196,386 @@ func (n *Notifier) Start(ctx context.Context) {
 	}
 }
 
-func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
-	topOneArticles, err := n.articlesRepository.AllNotPosted(ctx, time.Now().Add(-n.lookupTimeWindow), 1)
+// RunOnce sends the scheduled and due posts, digests and moderation previews and syncs edited
+// posts, as Start does every minute.
+func (n *Notifier) RunOnce(ctx context.Context) {
+	if err := n.SendScheduled(ctx); err != nil {
+		log.Printf("failed to send scheduled posts: %v", err)
+	}
+	if err := n.SendDuePosts(ctx); err != nil {
+		log.Printf("failed to send posts: %v", err)
+	}
+	if err := n.SendDueDigests(ctx); err != nil {
+		log.Printf("failed to send digests: %v", err)
+	}
+	if err := n.SendForModeration(ctx); err != nil {
+		log.Printf("failed to send articles for moderation: %v", err)
+	}
+	if err := n.SyncPosts(ctx); err != nil {
+		log.Printf("failed to sync posts: %v", err)
+	}
+}
+
+// Queue returns the articles not posted to the chat yet in the order they are going to be posted.
+// Articles the tags of the channel don't route to it are left out.
+func (n *Notifier) Queue(ctx context.Context, chatID int64) ([]rank.Ranked, error) {
+	now := time.Now()
+
+	channel, err := n.channel(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := n.articlesRepository.NotDeliveredTo(ctx, chatID, now.Add(-n.settings().lookupTimeWindow), maxCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch articles: %w", err)
+	}
+
+	ranked, err := n.rank(ctx, candidates, now)
+	if err != nil {
+		return nil, err
+	}
+	return routed(channel, ranked), nil
+}
+
+func (n *Notifier) rank(ctx context.Context, candidates []model.Article, now time.Time) ([]rank.Ranked, error) {
+	sources, err := n.sourcesRepository.Sources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sources: %w", err)
+	}
+
+	recentPosts, err := n.articlesRepository.PostedCountsBySource(ctx, now.Add(-recentPostsWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recent posts: %w", err)
+	}
+
+	signals := rank.Signals{
+		Now:         now,
+		Sources:     make(map[int64]model.Source, len(sources)),
+		RecentPosts: recentPosts,
+	}
+	for _, source := range sources {
+		signals.Sources[source.ID] = source
+	}
+
+	return n.settings().ranker.Rank(candidates, signals), nil
+}
+
+// SendDuePosts posts the best queued article to every channel whose posting window is open
+// and whose next post is due.
+func (n *Notifier) SendDuePosts(ctx context.Context) error {
+	channels, err := n.channelsRepository.Channels(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch articles: %w", err)
+		return fmt.Errorf("failed to fetch channels: %w", err)
 	}
 
-	if len(topOneArticles) == 0 {
-		log.Println("No articles to post at the moment")
+	now := time.Now()
+	for _, channel := range channels {
+		if channel.Digest != "" || channel.NextPostAt.After(now) {
+			continue
+		}
+
+		if err := n.postToChannel(ctx, channel, now); err != nil {
+			log.Printf("[ERROR] failed to post to %d: %v", channel.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *Notifier) postToChannel(ctx context.Context, channel model.Channel, now time.Time) error {
+	sched, err := channelSchedule(channel)
+	if err != nil {
+		log.Printf("[ERROR] invalid posting windows of %d, posting at any time: %v", channel.ID, err)
+	}
+
+	windowStart, windowEnd, open := sched.Current(now)
+	if !open {
+		return nil
+	}
+
+	// The slot is held while the post is summarized and sent, then set to the next post time.
+	claimed, err := n.channelsRepository.ClaimSlot(ctx, channel.ID, now, now.Add(n.claimLease))
+	if err != nil {
+		return fmt.Errorf("failed to claim posting slot: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	next := now
+	defer func() {
+		if err := n.channelsRepository.SetNextPost(context.WithoutCancel(ctx), channel.ID, next); err != nil {
+			log.Printf("[ERROR] failed to set the next post time of %d: %v", channel.ID, err)
+		}
+	}()
+
+	queue, err := n.Queue(ctx, channel.ID)
+	if err != nil {
+		return err
+	}
+
+	if channel.CatchUp == model.CatchUpSkip && !windowStart.IsZero() {
+		queue = slices.DeleteFunc(queue, func(item rank.Ranked) bool {
+			return item.Article.PublishedAt.Before(windowStart)
+		})
+	}
+
+	if channel.Moderated {
+		queue = approved(queue)
+	}
+
+	if len(queue) == 0 {
+		log.Printf("No articles to post to %d at the moment", channel.ID)
 		return nil
 	}
 
-	log.Println("Selected article for posting:", topOneArticles[0].Title)
-	article := topOneArticles[0]
-	summary, err := n.ExtractSummary(ctx, article)
+	posted, err := n.postBest(ctx, channel, queue)
+	if err != nil {
+		return err
+	}
+	if posted {
+		next = now.Add(n.nextInterval(channel, now, windowEnd, len(queue)-1))
+	}
+
+	return nil
+}
+
+// nextInterval returns the time until the next post to the channel. With the spread catch-up policy
+// a backlog that won't fit into the rest of the window at the usual interval is posted faster.
+func (n *Notifier) nextInterval(channel model.Channel, now, windowEnd time.Time, backlog int) time.Duration {
+	sendInterval := n.settings().sendInterval
+	interval := sendInterval
+	if channel.CatchUp == model.CatchUpSkip || windowEnd.IsZero() || backlog == 0 {
+		return interval
+	}
+
+	if spread := windowEnd.Sub(now) / time.Duration(backlog); spread < interval {
+		interval = max(spread, min(minCatchUpInterval, sendInterval))
+	}
+	return interval
+}
+
+// postBest posts the best article of the queue not being posted to the channel by another instance.
+func (n *Notifier) postBest(ctx context.Context, channel model.Channel, queue []rank.Ranked) (bool, error) {
+	for _, item := range queue {
+		err := n.post(ctx, channel, item, false)
+		if errors.Is(err, ErrAlreadyPosted) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// post claims the delivery of the article to the channel and posts it. A failed delivery is
+// claimed again if retryFailed is set.
+func (n *Notifier) post(ctx context.Context, channel model.Channel, item rank.Ranked, retryFailed bool) error {
+	claim := n.deliveriesRepository.Claim
+	if retryFailed {
+		claim = n.deliveriesRepository.Reclaim
+	}
+
+	deliveryID, claimed, err := claim(ctx, item.Article.ID, channel.ID, time.Now(), n.claimLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim delivery of article %d: %w", item.Article.ID, err)
+	}
+	if !claimed {
+		return ErrAlreadyPosted
+	}
+
+	log.Printf("Selected article for posting to %d: %s", channel.ID, item.Article.Title)
+
+	if err := n.deliver(ctx, channel, item, deliveryID); err != nil {
+		// Let the article be posted on the next tick instead of waiting for the claim to expire.
+		// Failed deliveries are kept.
+		if releaseErr := n.deliveriesRepository.Release(context.WithoutCancel(ctx), deliveryID); releaseErr != nil {
+			log.Printf("[ERROR] failed to release delivery %d: %v", deliveryID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// deliver summarizes the article in the channel language and posts it. Posts that can't be
+// delivered are recorded as failed.
+func (n *Notifier) deliver(ctx context.Context, channel model.Channel, item rank.Ranked, deliveryID int64) error {
+	article, data, err := n.prepare(ctx, channel, item)
+	if err != nil {
+		return err
+	}
+
+	kind, messageIDs, err := n.sendArticle(ctx, channel, article, data, buttons(channel, article.Link, data.Language))
 	if err != nil {
-		return fmt.Errorf("failed to extract summary: %w", err)
+		// Half a post isn't left in the channel, posting the article again would repeat it.
+		messageIDs = n.deleteMessages(context.WithoutCancel(ctx), channel.ID, messageIDs)
+		if ctx.Err() != nil {
+			// Shutting down, the post is retried later.
+			return err
+		}
+
+		// The messages that couldn't be deleted are kept with the failed delivery.
+		if markErr := n.deliveriesRepository.MarkFailed(ctx, deliveryID, err.Error(), messageIDs); markErr != nil {
+			return fmt.Errorf("failed to mark delivery to %d as failed: %w", channel.ID, markErr)
+		}
+		return fmt.Errorf("failed to send article %d: %w", article.ID, err)
 	}
 
-	if err := n.sendArticle(article, summary); err != nil {
-		return fmt.Errorf("failed to send article: %w", err)
+	if err := n.deliveriesRepository.MarkSent(ctx, deliveryID, kind, messageIDs); err != nil {
+		return fmt.Errorf("failed to mark delivery to %d as sent: %w", channel.ID, err)
 	}
 
 	log.Println("Article posted successfully:", article.Title)
 	return n.articlesRepository.MarkPosted(ctx, article.ID)
 }
 
-func (n *Notifier) ExtractSummary(ctx context.Context, article model.Article) (string, error) {
+// deleteMessages deletes the messages of a post that failed to be sent and returns the ones
+// that couldn't be deleted.
+func (n *Notifier) deleteMessages(ctx context.Context, chatID int64, messageIDs []int) []int {
+	var left []int
+	for _, id := range messageIDs {
+		if _, err := n.sender.Send(ctx, botkit.NewDeleteRequest(chatID, id)); err != nil {
+			log.Printf("[ERROR] failed to delete message %d of a failed post in %d: %v", id, chatID, err)
+			left = append(left, id)
+		}
+	}
+	return left
+}
+
+// prepare returns the post data of the article in the channel language, summarizing it if needed.
+// The returned article has its language and lead image filled in from the article text.
+func (n *Notifier) prepare(ctx context.Context, channel model.Channel, item rank.Ranked) (model.Article, post.Data, error) {
+	article := item.Article
+
+	// The article text is extracted lazily, only if it has to be summarized.
+	var text string
+	articleText := func() (string, error) {
+		if text != "" {
+			return text, nil
+		}
+
+		var (
+			imageURL string
+			err      error
+		)
+		text, imageURL, err = ExtractText(article)
+		if err != nil {
+			return "", fmt.Errorf("failed to extract text: %w", err)
+		}
+
+		if article.ImageURL == "" {
+			article.ImageURL = imageURL
+		}
+		if article.Language == "" {
+			article.Language = lang.Detect(text)
+		}
+		return text, nil
+	}
+
+	if channel.Language == "" && article.Language == "" {
+		if _, err := articleText(); err != nil {
+			return article, post.Data{}, err
+		}
+	}
+	language := n.targetLanguage(channel, article)
+
+	summary, err := n.storedSummary(ctx, article, item.Source.Name, language, articleText)
+	if err != nil {
+		return article, post.Data{}, err
+	}
+
+	return article, post.Data{
+		Title:       article.Title,
+		Summary:     summary,
+		Link:        article.Link,
+		Source:      item.Source.Name,
+		Language:    language,
+		Tags:        item.Source.Tags,
+		PublishedAt: article.PublishedAt,
+	}, nil
+}
+
+// storedSummary returns the stored summary of the article in the language or summarizes
+// the text returned by articleText and stores the summary.
+func (n *Notifier) storedSummary(
+	ctx context.Context,
+	article model.Article,
+	source, language string,
+	articleText func() (string, error),
+) (string, error) {
+	summary, err := n.articlesRepository.Summary(ctx, article.ID, language)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch stored summary: %w", err)
+	}
+	if summary != "" {
+		return summary, nil
+	}
+
+	text, err := articleText()
+	if err != nil {
+		return "", err
+	}
+
+	if summary, err = n.Summarize(article, source, text, language); err != nil {
+		return "", fmt.Errorf("failed to extract summary: %w", err)
+	}
+
+	if n.dryRun {
+		return summary, nil
+	}
+
+	if err := n.articlesRepository.StoreSummary(ctx, article.ID, language, summary); err != nil {
+		return "", fmt.Errorf("failed to store summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// channelSchedule returns the posting schedule of the channel. If the windows are invalid,
+// the schedule without windows is returned with the error.
+func channelSchedule(channel model.Channel) (*schedule.Schedule, error) {
+	loc := location(channel.Timezone)
+
+	windows, err := schedule.ParseWindows(channel.PostingWindows)
+	if err != nil {
+		return schedule.New(loc, nil), err
+	}
+	return schedule.New(loc, windows), nil
+}
+
+// targetLanguage returns the language the article should be posted in to the channel.
+func (n *Notifier) targetLanguage(channel model.Channel, article model.Article) string {
+	switch {
+	case channel.Language != "":
+		return channel.Language
+	case article.Language != "":
+		return article.Language
+	default:
+		return lang.English
+	}
+}
+
+// Summarize summarizes the article text in the given language.
+func (n *Notifier) Summarize(article model.Article, source, text, language string) (string, error) {
+	result, err := n.summarizer.Summarizer(text, summary.PromptData{
+		Title:        article.Title,
+		Source:       source,
+		Language:     language,
+		MaxSentences: n.settings().maxSentences,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize article content: %w", err)
+	}
+	return result, nil
+}
+
+// ExtractText returns the readable text and the lead image of the article,
+// downloading the page if the feed has no summary.
+func ExtractText(article model.Article) (string, string, error) {
 	var r io.Reader
 
 	if article.Summary != "" {
@@ -103,7 +584,7 @@ func (n *Notifier) ExtractSummary(ctx context.Context, article model.Article) (s
 		response, err := http.Get(article.Link)
 		if err != nil {
 			log.Printf("failed to fetch article content from %s: %v", article.Link, err)
-			return "", fmt.Errorf("article has no summary, tried to text from http get request but failed to fetch article content: %w", err)
+			return "", "", fmt.Errorf("article has no summary, tried to text from http get request but failed to fetch article content: %w", err)
 		}
 
 		defer response.Body.Close()
@@ -113,14 +594,10 @@ func (n *Notifier) ExtractSummary(ctx context.Context, article model.Article) (s
 
 	doc, err := readability.FromReader(r, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse article content: %w", err)
+		return "", "", fmt.Errorf("failed to parse article content: %w", err)
 	}
 
-	summary, err := n.summarizer.Summarizer(clearText(doc.TextContent))
-	if err != nil {
-		return "", fmt.Errorf("failed to summarize article content: %w", err)
-	}
-	return "\n\n" + summary, nil
+	return clearText(doc.TextContent), doc.Image, nil
 }
 
 var redundantNewLines = regexp.MustCompile(`\n{3,}`)
@@ -129,23 +606,159 @@ func clearText(text string) string {
 	return redundantNewLines.ReplaceAllString(text, "\n")
 }
 
-func (n *Notifier) sendArticle(article model.Article, summary string) error {
-	const msgFormat = "*%s*%s\n\n%s"
+// sendArticle sends the post with the keyboard under its last message and returns the kind of the post
+// and the IDs of its messages. If a text post fails part way, the IDs of the parts that were sent are
+// returned with the error.
+func (n *Notifier) sendArticle(
+	ctx context.Context,
+	channel model.Channel,
+	article model.Article,
+	data post.Data,
+	keyboard tgbotapi.InlineKeyboardMarkup,
+) (string, []int, error) {
+	if channel.SendPhoto && article.ImageURL != "" {
+		caption, parseMode, err := n.renderCaption(channel, data)
+		if err == nil {
+			var msg tgbotapi.Message
+			req := botkit.NewPhotoRequest(channel.ID, article.ImageURL, caption, parseMode).WithReplyMarkup(keyboard).WithFallback()
+			if msg, err = n.sender.Send(ctx, req); err == nil {
+				return model.PostPhoto, []int{msg.MessageID}, nil
+			}
+			if ctx.Err() != nil {
+				return "", nil, err
+			}
+		}
+		log.Printf("[ERROR] failed to send photo post to channel %d, sending text instead: %v", channel.ID, err)
+	}
+
+	messages, parseMode, err := n.renderText(channel, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	preview := textPreview(channel, article)
+
+	var messageIDs []int
+	for i, text := range messages {
+		req := botkit.NewTextRequest(channel.ID, text, parseMode).WithLinkPreview(preview)
+		if i == len(messages)-1 {
+			req = req.WithReplyMarkup(keyboard)
+		}
+
+		msg, err := n.sender.Send(ctx, req)
+		if err != nil {
+			// The parts sent so far are returned, so that they can be deleted.
+			return model.PostText, messageIDs, fmt.Errorf("failed to send message: %w", err)
+		}
+		messageIDs = append(messageIDs, msg.MessageID)
+	}
+
+	return model.PostText, messageIDs, nil
+}
+
+// renderText renders the post as text messages, falling back to the default template.
+func (n *Notifier) renderText(channel model.Channel, data post.Data) ([]string, string, error) {
+	messages, parseMode, err := n.renderPost(channel, data, markup.MaxMessageLength)
+	if err != nil {
+		log.Printf("[ERROR] failed to render post for channel %d, using the default template: %v", channel.ID, err)
 
-	// Telegram requires escaping markdown characters
-	msg := tgbotapi.NewMessage(n.channelID, fmt.Sprintf(
-		msgFormat,
-		markup.EscapeForMarkdown(article.Title),
-		markup.EscapeForMarkdown(summary),
-		markup.EscapeForMarkdown(article.Link),
-	))
+		return n.renderPost(model.Channel{ID: channel.ID}, data, markup.MaxMessageLength)
+	}
+	return messages, parseMode, nil
+}
 
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
+// textPreview returns the link preview of a text post. The lead image of a channel that sends photos
+// is shown as a large preview when the post doesn't fit a caption.
+func textPreview(channel model.Channel, article model.Article) botkit.LinkPreview {
+	preview := linkPreview(channel.LinkPreview)
+	if !preview.IsDisabled && channel.SendPhoto && article.ImageURL != "" {
+		preview.URL, preview.PreferLargeMedia, preview.PreferSmallMedia = article.ImageURL, true, false
+		preview.ShowAboveText = true
+	}
+	return preview
+}
 
-	_, err := n.bot.Send(msg)
+// renderCaption renders the post as a single photo caption, it fails if the post is too long.
+func (n *Notifier) renderCaption(channel model.Channel, data post.Data) (string, string, error) {
+	messages, parseMode, err := n.renderPost(channel, data, markup.MaxCaptionLength)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", "", err
 	}
 
-	return nil
+	if len(messages) != 1 {
+		return "", "", fmt.Errorf("post doesn't fit the %d character caption limit", markup.MaxCaptionLength)
+	}
+
+	return messages[0], parseMode, nil
+}
+
+// renderPost renders the post with the channel template and checks that Telegram will accept it.
+func (n *Notifier) renderPost(channel model.Channel, data post.Data, limit int) ([]string, string, error) {
+	tmpl, err := post.New(channel.Template, channel.ParseMode)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages, err := tmpl.RenderMessages(data, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, text := range messages {
+		if err := markup.Validate(text, tmpl.ParseMode()); err != nil {
+			return nil, "", fmt.Errorf("rendered post is invalid: %w", err)
+		}
+	}
+
+	return messages, tmpl.ParseMode(), nil
+}
+
+// linkPreview parses the channel link preview setting, unknown options are ignored.
+func linkPreview(setting string) botkit.LinkPreview {
+	var preview botkit.LinkPreview
+
+	for _, option := range strings.Split(setting, ",") {
+		switch strings.ToLower(strings.TrimSpace(option)) {
+		case "disabled":
+			preview.IsDisabled = true
+		case "small":
+			preview.PreferSmallMedia = true
+		case "large":
+			preview.PreferLargeMedia = true
+		case "above":
+			preview.ShowAboveText = true
+		}
+	}
+
+	return preview
+}
+
+var buttonLabels = map[string][2]string{
+	lang.English:   {"Read more", "Discuss"},
+	lang.Russian:   {"Читать далее", "Обсудить"},
+	lang.Ukrainian: {"Читати далі", "Обговорити"},
+	lang.German:    {"Weiterlesen", "Diskutieren"},
+	lang.French:    {"Lire la suite", "Discuter"},
+	lang.Spanish:   {"Leer más", "Discutir"},
+}
+
+// buttons returns the "Read more" and "Discuss" buttons enabled for the channel.
+func buttons(channel model.Channel, link, language string) tgbotapi.InlineKeyboardMarkup {
+	labels, ok := buttonLabels[language]
+	if !ok {
+		labels = buttonLabels[lang.English]
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	if channel.ReadMoreButton && link != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL(labels[0], link))
+	}
+	if channel.DiscussURL != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL(labels[1], channel.DiscussURL))
+	}
+
+	if len(row) == 0 {
+		return tgbotapi.InlineKeyboardMarkup{}
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
 }
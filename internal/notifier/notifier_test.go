@@ -0,0 +1,224 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier"
+	"github.com/amir-amirov/go-news-feed-bot/internal/notifier/notifiertest"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const (
+	channelID    = -100123
+	digestID     = -100456
+	moderationID = -100789
+)
+
+// options are the arguments of notifier.New that differ between tests.
+type options struct {
+	channels         notifier.ChannelsRepository // the store's by default
+	moderationChatID int64
+	sendInterval     time.Duration
+	deleteRetracted  bool
+}
+
+func newNotifier(store *storage.Storage, sender notifier.Sender, opts options) *notifier.Notifier {
+	channels := opts.channels
+	if channels == nil {
+		channels = store.Channels
+	}
+
+	return notifier.New(
+		store.Articles,
+		store.Sources,
+		channels,
+		store.Deliveries,
+		&notifiertest.Summarizer{},
+		rank.New(nil, 24*time.Hour),
+		sender,
+		opts.moderationChatID,
+		opts.sendInterval,
+		48*time.Hour,
+		time.Minute,
+		3,
+		opts.deleteRetracted,
+	)
+}
+
+// configuredChannels applies settings that are only edited in the database to the channels.
+type configuredChannels struct {
+	notifier.ChannelsRepository
+	configure func(channel *model.Channel)
+}
+
+func (c configuredChannels) Channels(ctx context.Context) ([]model.Channel, error) {
+	channels, err := c.ChannelsRepository.Channels(ctx)
+	for i := range channels {
+		c.configure(&channels[i])
+	}
+	return channels, err
+}
+
+func TestNotifier_SendDuePosts_CatchUp(t *testing.T) {
+	const sendInterval = 3 * time.Hour
+
+	tests := []struct {
+		catchUp string
+		// The backlog of two articles is spread over the two hours left of the window.
+		wantInterval time.Duration
+		wantBacklog  bool
+	}{
+		{model.CatchUpSpread, time.Hour, true},
+		{model.CatchUpSkip, sendInterval, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.catchUp, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemory()
+			now := time.Now().UTC()
+
+			// The window opened an hour ago and closes in two hours, it may span midnight.
+			windows := fmt.Sprintf("%s-%s", now.Add(-time.Hour).Format("15:04"), now.Add(2*time.Hour).Format("15:04"))
+			channels := configuredChannels{store.Channels, func(channel *model.Channel) {
+				channel.PostingWindows = windows
+				channel.CatchUp = tt.catchUp
+			}}
+			if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+
+			// Articles published before the window opened are the backlog.
+			for i, published := range []time.Time{now.Add(-3 * time.Hour), now.Add(-4 * time.Hour), now.Add(-30 * time.Minute)} {
+				title := fmt.Sprintf("Backlog %d", i+1)
+				if i == 2 {
+					title = "Fresh"
+				}
+				link := fmt.Sprintf("https://go.dev/blog/%d", i+1)
+				storeArticle(t, store, model.Article{Title: title, Link: link, PublishedAt: published}, "Summary.")
+			}
+
+			var sender notifiertest.Sender
+			n := newNotifier(store, &sender, options{channels: channels, sendInterval: sendInterval})
+
+			before := time.Now()
+			if err := n.SendDuePosts(ctx); err != nil {
+				t.Fatalf("SendDuePosts failed: %v", err)
+			}
+			after := time.Now()
+
+			sent := sender.Sent()
+			if len(sent) != 1 || !strings.Contains(sent[0].Params["text"], "Fresh") {
+				t.Fatalf("got posts %+v, want the fresh article", sent)
+			}
+
+			stored, err := store.Channels.Channels(ctx)
+			if err != nil {
+				t.Fatalf("Channels failed: %v", err)
+			}
+			// The window ends on a whole minute.
+			next := stored[0].NextPostAt
+			if next.Before(before.Add(tt.wantInterval-time.Minute)) || next.After(after.Add(tt.wantInterval)) {
+				t.Errorf("next post at %s, want %s after %s", next, tt.wantInterval, before)
+			}
+
+			if err := store.Channels.SetNextPost(ctx, channelID, time.Time{}); err != nil {
+				t.Fatalf("SetNextPost failed: %v", err)
+			}
+			if err := n.SendDuePosts(ctx); err != nil {
+				t.Fatalf("SendDuePosts failed: %v", err)
+			}
+
+			sent = sender.Sent()
+			if tt.wantBacklog && (len(sent) != 2 || !strings.Contains(sent[1].Params["text"], "Backlog")) {
+				t.Errorf("got posts %+v, want the backlog to be posted next", sent)
+			}
+			if !tt.wantBacklog && len(sent) != 1 {
+				t.Errorf("skipped backlog was posted: %+v", sent[1:])
+			}
+		})
+	}
+}
+
+func TestNotifier_SendDuePosts_PhotoFallback(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	channels := configuredChannels{store.Channels, func(channel *model.Channel) { channel.SendPhoto = true }}
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26", ImageURL: "https://go.dev/gopher.png"}, "Go 1.26 is released.")
+
+	// Telegram can't download the image.
+	var photos []botkit.Request
+	var sender notifiertest.Sender
+	sender.FailOn(func(req botkit.Request) error {
+		if req.Method != "sendPhoto" {
+			return nil
+		}
+		photos = append(photos, req)
+		return errors.New("Bad Request: wrong type of the web page content")
+	})
+
+	if err := newNotifier(store, &sender, options{channels: channels}).SendDuePosts(ctx); err != nil {
+		t.Fatalf("SendDuePosts failed: %v", err)
+	}
+
+	// The photo isn't kept as undeliverable, the post is sent as text instead.
+	if len(photos) != 1 || !photos[0].HasFallback {
+		t.Errorf("got photo requests %+v, want one with a fallback", photos)
+	}
+	if sent := sender.Sent(); len(sent) != 1 || sent[0].Method != "sendMessage" {
+		t.Errorf("got requests %q, want the post as text", requests(sent))
+	}
+}
+
+func TestNotifier_SendDuePosts_PartlySent(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+
+	if err := store.Channels.Upsert(ctx, model.Channel{ID: channelID, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	summary := strings.Repeat("The summary needs more than one message. ", 300)
+	articleID := storeArticle(t, store, model.Article{Title: "Go 1.26", Link: "https://go.dev/blog/go1.26"}, summary)
+
+	// The second part of the post fails.
+	var (
+		sender   notifiertest.Sender
+		messages int
+	)
+	sender.FailOn(func(req botkit.Request) error {
+		if req.Method != "sendMessage" {
+			return nil
+		}
+		if messages++; messages == 2 {
+			return errors.New("Bad Request: message is too long")
+		}
+		return nil
+	})
+
+	n := newNotifier(store, &sender, options{})
+	if err := n.SendDuePosts(ctx); err != nil {
+		t.Fatalf("SendDuePosts failed: %v", err)
+	}
+
+	// The part that was sent is deleted rather than left alone in the channel.
+	want := []string{"sendMessage ", "deleteMessage 1"}
+	if got := requests(sender.Sent()); !slices.Equal(got, want) {
+		t.Errorf("got requests %q, want %q", got, want)
+	}
+	if deliveries, err := store.Deliveries.Sent(ctx, articleID); err != nil || len(deliveries) != 0 {
+		t.Errorf("Sent() = %+v, %v for a failed post", deliveries, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package notifiertest provides a fake Telegram sender and a stub summarizer for tests that
+// run the notifier without Telegram and an LLM.
+package notifiertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit"
+	"github.com/amir-amirov/go-news-feed-bot/internal/summary"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender captures the requests instead of sending them to Telegram. Every sent message gets
+// the number of requests sent so far as its ID.
+type Sender struct {
+	mu       sync.Mutex
+	requests []botkit.Request
+	fail     func(req botkit.Request) error
+}
+
+func (s *Sender) Send(_ context.Context, req botkit.Request) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail != nil {
+		if err := s.fail(req); err != nil {
+			return tgbotapi.Message{}, err
+		}
+	}
+
+	s.requests = append(s.requests, req)
+	return tgbotapi.Message{MessageID: len(s.requests), Chat: &tgbotapi.Chat{ID: req.ChatID()}}, nil
+}
+
+// Sent returns the requests sent so far, failed ones are not included.
+func (s *Sender) Sent() []botkit.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]botkit.Request(nil), s.requests...)
+}
+
+// FailOn makes Send fail with the error fail returns for a request, nil to send it.
+func (s *Sender) FailOn(fail func(req botkit.Request) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+}
+
+// Summarizer "summarizes" articles by title and records the texts it was given.
+type Summarizer struct {
+	mu    sync.Mutex
+	texts map[string]string
+}
+
+func (s *Summarizer) Summarizer(text string, data summary.PromptData) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.texts == nil {
+		s.texts = make(map[string]string)
+	}
+	s.texts[data.Title] = text
+	return "Summary of " + data.Title, nil
+}
+
+func (s *Summarizer) Overview(text string, data summary.PromptData) (string, error) {
+	return "Overview", nil
+}
+
+// Text returns the text the article with the title was summarized from, false if it wasn't.
+func (s *Summarizer) Text(title string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	text, ok := s.texts[title]
+	return text, ok
+}
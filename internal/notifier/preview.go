@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+)
+
+// Preview sends the post the chat would get next to the sender instead of the chat, e.g. to
+// print it, without claiming the post or storing its summary. It returns the previewed
+// article, or false if the chat has nothing to post.
+func (n *Notifier) Preview(ctx context.Context, chatID int64, sender Sender) (rank.Ranked, bool, error) {
+	channel, err := n.channel(ctx, chatID)
+	if err != nil {
+		return rank.Ranked{}, false, err
+	}
+
+	queue, err := n.Queue(ctx, chatID)
+	if err != nil {
+		return rank.Ranked{}, false, err
+	}
+	if channel.Moderated {
+		queue = approved(queue)
+	}
+	if len(queue) == 0 {
+		return rank.Ranked{}, false, nil
+	}
+	item := queue[0]
+
+	dry := n.withSender(sender)
+	article, data, err := dry.prepare(ctx, channel, item)
+	if err != nil {
+		return item, true, err
+	}
+
+	if _, _, err := dry.sendArticle(ctx, channel, article, data, buttons(channel, article.Link, data.Language)); err != nil {
+		return item, true, fmt.Errorf("failed to render article %d: %w", article.ID, err)
+	}
+	return item, true, nil
+}
+
+// withSender returns a copy of the notifier sending to the sender that doesn't store summaries.
+func (n *Notifier) withSender(sender Sender) *Notifier {
+	return &Notifier{
+		articlesRepository:   n.articlesRepository,
+		sourcesRepository:    n.sourcesRepository,
+		channelsRepository:   n.channelsRepository,
+		deliveriesRepository: n.deliveriesRepository,
+		summarizer:           n.summarizer,
+		sender:               sender,
+		moderationChatID:     n.moderationChatID,
+		claimLease:           n.claimLease,
+		current:              n.settings(),
+		dryRun:               true,
+	}
+}
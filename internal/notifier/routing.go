@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+)
+
+// routed drops the articles the tags of the channel don't route to it.
+func routed(channel model.Channel, queue []rank.Ranked) []rank.Ranked {
+	if strings.TrimSpace(channel.Tags) == "" {
+		return queue
+	}
+
+	return slices.DeleteFunc(queue, func(item rank.Ranked) bool {
+		return item.Article.SourceID != 0 && !routes(channel.Tags, item.Source.Tags)
+	})
+}
+
+// routes reports whether a source with the tags is routed to a channel with the channel tags,
+// a comma separated list such as "go,rust,-jobs": the source must have one of the tags, if
+// any are listed, and none of the ones starting with "-".
+func routes(channelTags string, sourceTags []string) bool {
+	var listed, matched bool
+	for _, tag := range strings.Split(channelTags, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+
+		if excluded, ok := strings.CutPrefix(tag, "-"); ok {
+			if slices.Contains(sourceTags, strings.TrimSpace(excluded)) {
+				return false
+			}
+			continue
+		}
+
+		if tag != "" {
+			listed = true
+			matched = matched || slices.Contains(sourceTags, tag)
+		}
+	}
+	return !listed || matched
+}
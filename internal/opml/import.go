@@ -0,0 +1,139 @@
+package opml
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+)
+
+// SourceStorage is the part of the sources repository imports and exports use.
+type SourceStorage interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	Add(ctx context.Context, source model.Source) (int64, error)
+}
+
+// Report is the outcome of an import.
+type Report struct {
+	DryRun     bool
+	Added      []model.Source // added sources, on dry runs the ones that would be added, without IDs
+	Duplicates []Duplicate
+	Invalid    []Invalid
+}
+
+// Duplicate is a feed that is already a source, or repeats an earlier feed of the list.
+type Duplicate struct {
+	Feed Feed
+	Of   string // name of the source or the earlier feed
+}
+
+// Invalid is a feed that can't be a source.
+type Invalid struct {
+	Feed Feed
+	Err  error
+}
+
+// String lists the outcome for the admin, one feed per line.
+func (r Report) String() string {
+	var b strings.Builder
+
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(&b, "%s %d sources, %d duplicates, %d invalid.", verb, len(r.Added), len(r.Duplicates), len(r.Invalid))
+
+	for _, source := range r.Added {
+		fmt.Fprintf(&b, "\n+ %s %s", source.Name, source.FeedURL)
+		if len(source.Tags) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(source.Tags, ", "))
+		}
+	}
+	for _, duplicate := range r.Duplicates {
+		fmt.Fprintf(&b, "\n= %s %s: duplicate of %s", duplicate.Feed.Title, duplicate.Feed.FeedURL, duplicate.Of)
+	}
+	for _, invalid := range r.Invalid {
+		fmt.Fprintf(&b, "\n! %s %s: %v", invalid.Feed.Title, invalid.Feed.FeedURL, invalid.Err)
+	}
+
+	return b.String()
+}
+
+// Import adds the feeds of the subscription list as sources. Feeds whose normalized URL,
+// see source.NormalizeURL, is already a source are skipped. On dry runs nothing is added.
+func Import(ctx context.Context, sources SourceStorage, r io.Reader, dryRun bool) (Report, error) {
+	feeds, err := Parse(r)
+	if err != nil {
+		return Report{}, err
+	}
+
+	existing, err := sources.Sources(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to fetch sources: %w", err)
+	}
+
+	names := make(map[string]string, len(existing))
+	for _, src := range existing {
+		names[source.NormalizeURL(src.FeedURL)] = src.Name
+	}
+
+	report := Report{DryRun: dryRun}
+	for _, feed := range feeds {
+		if err := validate(feed.FeedURL); err != nil {
+			report.Invalid = append(report.Invalid, Invalid{Feed: feed, Err: err})
+			continue
+		}
+
+		key := source.NormalizeURL(feed.FeedURL)
+		if name, ok := names[key]; ok {
+			report.Duplicates = append(report.Duplicates, Duplicate{Feed: feed, Of: name})
+			continue
+		}
+
+		src := model.Source{Name: feed.Title, FeedURL: feed.FeedURL, Tags: feed.Tags}
+		if src.Name == "" {
+			src.Name = feed.FeedURL
+		}
+
+		if !dryRun {
+			id, err := sources.Add(ctx, src)
+			if err != nil {
+				return report, fmt.Errorf("failed to add source %s: %w", feed.FeedURL, err)
+			}
+			src.ID = id
+		}
+
+		names[key] = src.Name
+		report.Added = append(report.Added, src)
+	}
+
+	return report, nil
+}
+
+// Export writes the sources as a subscription list, the tags of a source become the outlines
+// it is nested in.
+func Export(ctx context.Context, sources SourceStorage, w io.Writer) error {
+	list, err := sources.Sources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sources: %w", err)
+	}
+
+	feeds := make([]Feed, 0, len(list))
+	for _, src := range list {
+		feeds = append(feeds, Feed{Title: src.Name, FeedURL: src.FeedURL, Tags: src.Tags})
+	}
+
+	return Write(w, "News feed bot sources", feeds)
+}
+
+func validate(feedURL string) error {
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid feed URL %q", feedURL)
+	}
+	return nil
+}
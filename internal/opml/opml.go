@@ -0,0 +1,179 @@
+// Package opml reads and writes OPML 2.0 subscription lists, the format feed readers import
+// and export their feeds in, and imports them as sources.
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Feed is an outline of a subscription list with a feed URL.
+type Feed struct {
+	Title   string
+	FeedURL string
+	HTMLURL string
+	// Tags are the texts of the outlines the feed is nested in, outermost first, followed by
+	// the segments of its category attribute.
+	Tags []string
+}
+
+// outline is an OPML outline. Readers disagree on the case of attribute names, e.g. xmlUrl
+// and xmlurl, so they are looked up case-insensitively, see attr.
+type outline struct {
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Outlines []outline  `xml:"outline"`
+}
+
+func (o outline) attr(name string) string {
+	for _, a := range o.Attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// Parse reads the feeds of the subscription list. Outlines without a feed URL are categories,
+// their texts become tags of the feeds nested in them.
+func Parse(r io.Reader) ([]Feed, error) {
+	var doc struct {
+		XMLName xml.Name
+		Body    struct {
+			Outlines []outline `xml:"outline"`
+		} `xml:"body"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+	if doc.XMLName.Local != "opml" {
+		return nil, errors.New("not an OPML document")
+	}
+
+	var feeds []Feed
+	var walk func(outlines []outline, path []string)
+	walk = func(outlines []outline, path []string) {
+		for _, o := range outlines {
+			text := o.attr("text")
+			if text == "" {
+				text = o.attr("title")
+			}
+
+			feedURL := o.attr("xmlUrl")
+			if feedURL == "" {
+				if text != "" {
+					walk(o.Outlines, append(path[:len(path):len(path)], text))
+				} else {
+					walk(o.Outlines, path)
+				}
+				continue
+			}
+
+			feed := Feed{Title: text, FeedURL: feedURL, HTMLURL: o.attr("htmlUrl")}
+			feed.Tags = append(feed.Tags, path...)
+			feed.Tags = append(feed.Tags, categories(o.attr("category"))...)
+			feeds = append(feeds, feed)
+		}
+	}
+	walk(doc.Body.Outlines, nil)
+
+	return feeds, nil
+}
+
+// categories splits the category attribute, a comma separated list of slash delimited paths,
+// e.g. "/Tech/Go,News".
+func categories(attr string) []string {
+	var tags []string
+	for _, category := range strings.Split(attr, ",") {
+		for _, segment := range strings.Split(category, "/") {
+			if segment = strings.TrimSpace(segment); segment != "" {
+				tags = append(tags, segment)
+			}
+		}
+	}
+	return tags
+}
+
+// Write writes the feeds as a subscription list titled title. Feeds are nested in outlines
+// of their tags, so lists written by Write parse to the same feeds.
+func Write(w io.Writer, title string, feeds []Feed) error {
+	root := &folder{}
+	for _, feed := range feeds {
+		f := root.folder(feed.Tags)
+		f.feeds = append(f.feeds, feed)
+	}
+
+	type head struct {
+		Title string `xml:"title"`
+	}
+	doc := struct {
+		XMLName xml.Name       `xml:"opml"`
+		Version string         `xml:"version,attr"`
+		Head    head           `xml:"head"`
+		Body    []outlineWrite `xml:"body>outline"`
+	}{
+		Version: "2.0",
+		Head:    head{Title: title},
+		Body:    root.outlines(),
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type outlineWrite struct {
+	Text     string         `xml:"text,attr"`
+	Title    string         `xml:"title,attr,omitempty"`
+	Type     string         `xml:"type,attr,omitempty"`
+	XMLURL   string         `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string         `xml:"htmlUrl,attr,omitempty"`
+	Outlines []outlineWrite `xml:"outline"`
+}
+
+// folder is a category outline of Write, its subfolders are kept in the order they appear in.
+type folder struct {
+	name    string
+	folders []*folder
+	feeds   []Feed
+}
+
+func (f *folder) folder(path []string) *folder {
+	if len(path) == 0 {
+		return f
+	}
+	for _, sub := range f.folders {
+		if sub.name == path[0] {
+			return sub.folder(path[1:])
+		}
+	}
+	sub := &folder{name: path[0]}
+	f.folders = append(f.folders, sub)
+	return sub.folder(path[1:])
+}
+
+func (f *folder) outlines() []outlineWrite {
+	var outlines []outlineWrite
+	for _, sub := range f.folders {
+		outlines = append(outlines, outlineWrite{Text: sub.name, Title: sub.name, Outlines: sub.outlines()})
+	}
+	for _, feed := range f.feeds {
+		outlines = append(outlines, outlineWrite{
+			Text:    feed.Title,
+			Title:   feed.Title,
+			Type:    "rss",
+			XMLURL:  feed.FeedURL,
+			HTMLURL: feed.HTMLURL,
+		})
+	}
+	return outlines
+}
@@ -0,0 +1,111 @@
+package opml_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/opml"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+const subscriptions = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Reader subscriptions</title></head>
+  <body>
+    <outline text="Tech">
+      <outline text="Go">
+        <outline text="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+      </outline>
+      <outline title="Hacker News" xmlurl="https://news.ycombinator.com/rss" category="/News/Daily"/>
+    </outline>
+    <outline text="Untagged" xmlUrl="http://www.example.com/feed/"/>
+    <outline text="Broken" xmlUrl="ftp://example.com/feed"/>
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	feeds, err := opml.Parse(strings.NewReader(subscriptions))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []opml.Feed{
+		{Title: "The Go Blog", FeedURL: "https://go.dev/blog/feed.atom", HTMLURL: "https://go.dev/blog", Tags: []string{"Tech", "Go"}},
+		{Title: "Hacker News", FeedURL: "https://news.ycombinator.com/rss", Tags: []string{"Tech", "News", "Daily"}},
+		{Title: "Untagged", FeedURL: "http://www.example.com/feed/"},
+		{Title: "Broken", FeedURL: "ftp://example.com/feed"},
+	}
+	if !reflect.DeepEqual(feeds, want) {
+		t.Errorf("got feeds\n%+v\nwant\n%+v", feeds, want)
+	}
+
+	if _, err := opml.Parse(strings.NewReader(`<rss version="2.0"><channel/></rss>`)); err == nil {
+		t.Error("an RSS feed parsed as OPML")
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	feeds := []opml.Feed{
+		{Title: "The Go Blog", FeedURL: "https://go.dev/blog/feed.atom", HTMLURL: "https://go.dev/blog", Tags: []string{"tech", "go"}},
+		{Title: "Hacker News", FeedURL: "https://news.ycombinator.com/rss", Tags: []string{"tech"}},
+		{Title: "Untagged & <odd>", FeedURL: "https://example.com/feed?a=1&b=2"},
+	}
+
+	var buf bytes.Buffer
+	if err := opml.Write(&buf, "Sources", feeds); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	parsed, err := opml.Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Subfolders are written before the feeds of a folder.
+	want := []opml.Feed{feeds[0], feeds[1], feeds[2]}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("got feeds\n%+v\nwant\n%+v", parsed, want)
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	repositories := storage.NewMemory()
+	if _, err := repositories.Sources.Add(ctx, model.Source{Name: "Example", FeedURL: "https://example.com/feed"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	report, err := opml.Import(ctx, repositories.Sources, strings.NewReader(subscriptions), true)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Added) != 2 || len(report.Duplicates) != 1 || len(report.Invalid) != 1 {
+		t.Fatalf("unexpected dry run report:\n%s", report)
+	}
+	if report.Duplicates[0].Of != "Example" {
+		t.Errorf("got duplicate of %q, want Example", report.Duplicates[0].Of)
+	}
+	if sources, _ := repositories.Sources.Sources(ctx); len(sources) != 1 {
+		t.Fatalf("dry run added %d sources", len(sources)-1)
+	}
+
+	if _, err := opml.Import(ctx, repositories.Sources, strings.NewReader(subscriptions), false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	sources, _ := repositories.Sources.Sources(ctx)
+	if len(sources) != 3 || sources[1].Name != "The Go Blog" || !reflect.DeepEqual(sources[1].Tags, []string{"tech", "go"}) {
+		t.Fatalf("unexpected sources after import: %+v", sources)
+	}
+
+	// Importing again finds only duplicates.
+	report, err = opml.Import(ctx, repositories.Sources, strings.NewReader(subscriptions), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Duplicates) != 3 {
+		t.Errorf("unexpected report of the second import:\n%s", report)
+	}
+}
@@ -0,0 +1,49 @@
+package post
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+)
+
+// Digest is a post bundling several articles.
+type Digest struct {
+	Title    string
+	Overview string
+	Articles []Data
+}
+
+// RenderDigest renders the digest in the parse mode, an empty one means MarkdownV2,
+// and splits it into messages of at most limit characters.
+func RenderDigest(digest Digest, parseMode string, limit int) ([]string, error) {
+	switch parseMode {
+	case "":
+		parseMode = markup.ModeMarkdownV2
+	case markup.ModeMarkdownV2, markup.ModeHTML:
+	default:
+		return nil, fmt.Errorf("unsupported parse mode %q", parseMode)
+	}
+
+	b := markup.NewBuilder(parseMode)
+	b.Bold(digest.Title)
+
+	if digest.Overview != "" {
+		b.Line().Line().Text(digest.Overview)
+	}
+
+	for i, article := range digest.Articles {
+		b.Line().Line().
+			Text(strconv.Itoa(i+1) + ". ").
+			Append(markup.Link(article.Link, markup.Bold(markup.Text(article.Title))))
+
+		if article.Source != "" {
+			b.Text(" — ").Italic(article.Source)
+		}
+		if article.Summary != "" {
+			b.Line().Text(article.Summary)
+		}
+	}
+
+	return markup.Split(b.String(), parseMode, limit), nil
+}
@@ -0,0 +1,174 @@
+// Package post renders articles into Telegram messages using per-channel templates.
+package post
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+)
+
+// Default templates reproduce the classic post: bold title, summary and link, followed by the
+// hashtags of the source's tags if it has any.
+const (
+	DefaultMarkdownV2 = "*{{escape .Title}}*\n\n{{escape .Summary}}\n\n{{escape .Link}}{{with .Tags}}\n\n{{hashtags .}}{{end}}"
+	DefaultHTML       = "<b>{{escape .Title}}</b>\n\n{{escape .Summary}}\n\n{{escape .Link}}{{with .Tags}}\n\n{{hashtags .}}{{end}}"
+)
+
+// Data holds the variables available in post templates.
+type Data struct {
+	Title       string
+	Summary     string
+	Link        string
+	Source      string
+	Language    string
+	Tags        []string
+	PublishedAt time.Time
+}
+
+type Template struct {
+	tmpl      *template.Template
+	parseMode string
+}
+
+// New parses a post template for the given parse mode. An empty text means the default
+// template and an empty parse mode means MarkdownV2.
+func New(text, parseMode string) (*Template, error) {
+	switch parseMode {
+	case "":
+		parseMode = markup.ModeMarkdownV2
+	case markup.ModeMarkdownV2, markup.ModeHTML:
+	default:
+		return nil, fmt.Errorf("unsupported parse mode %q", parseMode)
+	}
+
+	if text == "" {
+		text = DefaultMarkdownV2
+		if parseMode == markup.ModeHTML {
+			text = DefaultHTML
+		}
+	}
+
+	tmpl, err := template.New("post").Funcs(funcs(parseMode)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse post template: %w", err)
+	}
+
+	return &Template{tmpl: tmpl, parseMode: parseMode}, nil
+}
+
+// ParseMode returns the parse mode messages must be sent with.
+func (t *Template) ParseMode() string {
+	return t.parseMode
+}
+
+// Render renders a single message, it may exceed Telegram limits.
+func (t *Template) Render(data Data) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render post: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// RenderMessages renders the post and splits it into messages of at most limit characters.
+func (t *Template) RenderMessages(data Data, limit int) ([]string, error) {
+	text, err := t.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return markup.Split(text, t.parseMode, limit), nil
+}
+
+func funcs(parseMode string) template.FuncMap {
+	escape := func(s string) string {
+		return markup.Escape(parseMode, s)
+	}
+
+	return template.FuncMap{
+		"escape": escape,
+		"bold": func(s string) string {
+			return markup.Render(parseMode, markup.Bold(markup.Text(s)))
+		},
+		"italic": func(s string) string {
+			return markup.Render(parseMode, markup.Italic(markup.Text(s)))
+		},
+		"code": func(s string) string {
+			return markup.Render(parseMode, markup.Code(s))
+		},
+		"spoiler": func(s string) string {
+			return markup.Render(parseMode, markup.Spoiler(markup.Text(s)))
+		},
+		"link": func(text, url string) string {
+			return markup.Render(parseMode, markup.Link(url, markup.Text(text)))
+		},
+		"hashtag": func(s string) string {
+			return escape(Hashtag(s))
+		},
+		"hashtags": func(tags []string) string {
+			var result []string
+			for _, s := range tags {
+				if tag := Hashtag(s); tag != "" {
+					result = append(result, escape(tag))
+				}
+			}
+			return strings.Join(result, " ")
+		},
+		"truncate": Truncate,
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+	}
+}
+
+// Hashtag turns s into a Telegram hashtag, e.g. "Go Releases" becomes "#go_releases".
+// An empty string is returned if s has no letters or digits.
+func Hashtag(s string) string {
+	var sb strings.Builder
+	underscore := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if underscore && sb.Len() > 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+			underscore = false
+		default:
+			underscore = true
+		}
+	}
+
+	// Telegram does not treat digits-only words as hashtags.
+	tag := sb.String()
+	if strings.IndexFunc(tag, unicode.IsLetter) < 0 {
+		return ""
+	}
+
+	return "#" + tag
+}
+
+// Truncate shortens plain text to at most n characters at a word boundary, adding an ellipsis.
+// It must be applied before escaping.
+func Truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return "…"
+	}
+
+	cut := string(runes[:n-1])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
@@ -0,0 +1,238 @@
+package post_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/botkit/markup"
+	"github.com/amir-amirov/go-news-feed-bot/internal/post"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var article = post.Data{
+	Title:       "Go 1.22 is released!",
+	Summary:     "Go 1.22 brings range-over-int (e.g. for i := range 10) and fixes the for-loop variable capture bug. Upgrade with `go install golang.org/dl/go1.22.0@latest` & enjoy <3.",
+	Link:        "https://go.dev/blog/go1.22?utm_source=feed&x=(1)",
+	Source:      "The Go Blog",
+	Language:    "en",
+	Tags:        []string{"Go", "Releases", "Go 1.22", "2024"},
+	PublishedAt: time.Date(2024, 2, 6, 17, 0, 0, 0, time.UTC),
+}
+
+const (
+	customMarkdownV2 = `{{bold .Title}}
+{{italic .Source}}, {{date "2 Jan 2006" .PublishedAt}}
+
+{{.Summary | truncate 80 | escape}}
+
+{{link "Read more" .Link}}
+{{hashtags .Tags}} {{hashtag .Language}}`
+
+	customHTML = `{{bold .Title}}
+{{italic .Source}}, {{date "2 Jan 2006" .PublishedAt}}
+
+{{.Summary | truncate 80 | escape}}
+
+{{link "Read more" .Link}}
+{{hashtags .Tags}} {{hashtag .Language}}`
+)
+
+func TestTemplate_Golden(t *testing.T) {
+	long := article
+	long.Summary = strings.TrimSpace(strings.Repeat("Go is an open source programming language that makes it simple to build secure, scalable systems. ", 90))
+
+	tests := []struct {
+		name      string
+		template  string
+		parseMode string
+		data      post.Data
+		limit     int
+	}{
+		{"default_markdownv2", "", markup.ModeMarkdownV2, article, markup.MaxMessageLength},
+		{"default_html", "", markup.ModeHTML, article, markup.MaxMessageLength},
+		{"custom_markdownv2", customMarkdownV2, markup.ModeMarkdownV2, article, markup.MaxMessageLength},
+		{"custom_html", customHTML, markup.ModeHTML, article, markup.MaxMessageLength},
+		{"split_markdownv2", "*{{escape .Title}}*\n\n_{{escape .Summary}}_\n\n{{link .Title .Link}}", markup.ModeMarkdownV2, long, markup.MaxMessageLength},
+		{"split_html", "<b>{{escape .Title}}</b>\n\n<i>{{escape .Summary}}</i>\n\n{{link .Title .Link}}", markup.ModeHTML, long, markup.MaxMessageLength},
+		{"split_small_limit", "", markup.ModeMarkdownV2, article, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := post.New(tt.template, tt.parseMode)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			messages, err := tmpl.RenderMessages(tt.data, tt.limit)
+			if err != nil {
+				t.Fatalf("RenderMessages: %v", err)
+			}
+
+			var sb strings.Builder
+			for i, msg := range messages {
+				if l := markup.Length(msg); l > tt.limit {
+					t.Errorf("message %d is %d characters long, limit is %d", i, l, tt.limit)
+				}
+				if err := markup.Validate(msg, tmpl.ParseMode()); err != nil {
+					t.Errorf("message %d is invalid: %v", i, err)
+				}
+				fmt.Fprintf(&sb, "----- message %d -----\n%s\n", i+1, msg)
+			}
+
+			assertGolden(t, tt.name, sb.String())
+		})
+	}
+}
+
+func TestTemplate_Caption(t *testing.T) {
+	tmpl, err := post.New("", markup.ModeMarkdownV2)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	text, err := tmpl.Render(article)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	caption := markup.Truncate(text, markup.ModeMarkdownV2, 100)
+	if err := markup.Validate(caption, markup.ModeMarkdownV2); err != nil {
+		t.Errorf("caption is invalid: %v", err)
+	}
+
+	assertGolden(t, "caption_markdownv2", caption)
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := post.New("", "Markdown"); err == nil {
+		t.Error("expected error for unsupported parse mode")
+	}
+	if _, err := post.New("{{.Title", markup.ModeHTML); err == nil {
+		t.Error("expected error for malformed template")
+	}
+}
+
+func TestHashtag(t *testing.T) {
+	tests := map[string]string{
+		"Go":            "#go",
+		"Go Releases":   "#go_releases",
+		"  c++ / rust ": "#c_rust",
+		"Go 1.22":       "#go_1_22",
+		"Новости":       "#новости",
+		"2024":          "",
+		"!!!":           "",
+	}
+
+	for in, want := range tests {
+		if got := post.Hashtag(in); got != want {
+			t.Errorf("Hashtag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		n    int
+		in   string
+		want string
+	}{
+		{10, "short", "short"},
+		{12, "Go is a fast and simple language.", "Go is a…"},
+		{5, "Unbreakable", "Unbr…"},
+		{1, "Go", "…"},
+	}
+
+	for _, tt := range tests {
+		if got := post.Truncate(tt.n, tt.in); got != tt.want {
+			t.Errorf("Truncate(%d, %q) = %q, want %q", tt.n, tt.in, got, tt.want)
+		}
+	}
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output does not match %s\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestRenderDigest(t *testing.T) {
+	second := article
+	second.Title = "Range over func"
+	second.Source = ""
+	second.Link = "https://go.dev/blog/range-functions"
+
+	digest := post.Digest{
+		Title:    "Daily digest, 6 Feb",
+		Overview: "Go 1.22 is out.",
+		Articles: []post.Data{article, second},
+	}
+
+	for _, mode := range []string{markup.ModeMarkdownV2, markup.ModeHTML} {
+		messages, err := post.RenderDigest(digest, mode, markup.MaxMessageLength)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("%s: expected a single message, got %d", mode, len(messages))
+		}
+		if err := markup.Validate(messages[0], mode); err != nil {
+			t.Errorf("%s: invalid digest %q: %v", mode, messages[0], err)
+		}
+	}
+
+	messages, err := post.RenderDigest(digest, "", markup.MaxMessageLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "*Daily digest, 6 Feb*\n\nGo 1\\.22 is out\\.\n\n" +
+		"1\\. [*Go 1\\.22 is released\\!*](https://go.dev/blog/go1.22?utm_source=feed&x=(1\\)) — _The Go Blog_\n" +
+		markup.EscapeForMarkdown(article.Summary) + "\n\n" +
+		"2\\. [*Range over func*](https://go.dev/blog/range-functions)\n" +
+		markup.EscapeForMarkdown(article.Summary)
+	if messages[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", messages[0], want)
+	}
+
+	// Long digests are split.
+	for i := 0; i < 40; i++ {
+		digest.Articles = append(digest.Articles, article)
+	}
+	messages, err = post.RenderDigest(digest, markup.ModeHTML, markup.MaxMessageLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) < 2 {
+		t.Errorf("expected a long digest to be split, got %d messages", len(messages))
+	}
+	for i, msg := range messages {
+		if markup.Length(msg) > markup.MaxMessageLength {
+			t.Errorf("message %d is too long", i)
+		}
+	}
+
+	if _, err := post.RenderDigest(digest, "Markdown", markup.MaxMessageLength); err == nil {
+		t.Error("expected an error for an unsupported parse mode")
+	}
+}
@@ -0,0 +1,247 @@
+// Package rank orders unposted articles by how worth posting they are.
+package rank
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+// Ranker orders candidate articles, the best one first.
+type Ranker interface {
+	Rank(articles []model.Article, signals Signals) []Ranked
+}
+
+// Signals is what rankers know besides the articles themselves.
+type Signals struct {
+	Now         time.Time
+	Sources     map[int64]model.Source
+	RecentPosts map[int64]int // number of recently posted articles per source ID
+}
+
+// Ranked is a scored article with the factors its score is the product of.
+type Ranked struct {
+	Article model.Article
+	Source  model.Source
+	Score   float64
+	Factors []Factor
+}
+
+type Factor struct {
+	Name  string
+	Value float64
+	Note  string // e.g. the matched keywords
+}
+
+func (f Factor) String() string {
+	if f.Note == "" {
+		return fmt.Sprintf("%s ×%.2f", f.Name, f.Value)
+	}
+	return fmt.Sprintf("%s ×%.2f (%s)", f.Name, f.Value, f.Note)
+}
+
+const (
+	// sourceDecay is applied to each next article of the same source, so sources take turns.
+	sourceDecay = 0.5
+	// popularityBoost is added for every other source that has the same story.
+	popularityBoost = 0.5
+	// minSimilarity is the share of common title words for two articles to be the same story.
+	minSimilarity = 0.5
+)
+
+// Scorer is the default ranker. An article's score is the product of its source priority,
+// freshness decay, keyword boosts, popularity across sources and source fairness.
+type Scorer struct {
+	keywords map[string]float64
+	halfLife time.Duration
+}
+
+// New creates a Scorer. Keywords map lowercase words or phrases found in the title or
+// summary to multipliers; the freshness score halves every halfLife.
+func New(keywords map[string]float64, halfLife time.Duration) *Scorer {
+	lowered := make(map[string]float64, len(keywords))
+	for keyword, weight := range keywords {
+		lowered[strings.ToLower(keyword)] = weight
+	}
+
+	return &Scorer{
+		keywords: lowered,
+		halfLife: halfLife,
+	}
+}
+
+func (s *Scorer) Rank(articles []model.Article, signals Signals) []Ranked {
+	words := make([]map[string]bool, len(articles))
+	for i, article := range articles {
+		words[i] = titleWords(article.Title)
+	}
+
+	ranked := make([]Ranked, len(articles))
+	for i, article := range articles {
+		source := signals.Sources[article.SourceID]
+
+		ranked[i] = Ranked{
+			Article: article,
+			Source:  source,
+			Factors: []Factor{
+				priority(source),
+				s.freshness(article, signals.Now),
+				s.keywordBoost(article),
+				popularity(i, articles, words),
+				recentPosts(signals.RecentPosts[article.SourceID]),
+			},
+		}
+		ranked[i].Score = score(ranked[i].Factors)
+	}
+
+	sortByScore(ranked)
+
+	// Round-robin between sources: the second article of a source competes with the first ones of others.
+	seen := make(map[int64]int)
+	for i := range ranked {
+		n := seen[ranked[i].Article.SourceID]
+		seen[ranked[i].Article.SourceID]++
+
+		if n > 0 {
+			ranked[i].Factors = append(ranked[i].Factors, Factor{
+				Name:  "round-robin",
+				Value: math.Pow(sourceDecay, float64(n)),
+				Note:  fmt.Sprintf("%d more from the source", n),
+			})
+			ranked[i].Score = score(ranked[i].Factors)
+		}
+	}
+
+	sortByScore(ranked)
+	return ranked
+}
+
+// priority weighs the article by its source. Stored sources have a positive priority, zero
+// means the article has no source, e.g. it was posted by link, and weighs as much as 1.
+func priority(source model.Source) Factor {
+	if source.Priority <= 0 {
+		return Factor{Name: "priority", Value: 1}
+	}
+	return Factor{Name: "priority", Value: source.Priority}
+}
+
+func (s *Scorer) freshness(article model.Article, now time.Time) Factor {
+	age := now.Sub(article.PublishedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	value := 1.0
+	if s.halfLife > 0 {
+		value = math.Exp2(-float64(age) / float64(s.halfLife))
+	}
+
+	return Factor{Name: "freshness", Value: value, Note: age.Round(time.Minute).String() + " old"}
+}
+
+func (s *Scorer) keywordBoost(article model.Article) Factor {
+	text := strings.ToLower(article.Title + "\n" + article.Summary)
+
+	value := 1.0
+	var matched []string
+	for keyword, weight := range s.keywords {
+		if strings.Contains(text, keyword) {
+			value *= weight
+			matched = append(matched, keyword)
+		}
+	}
+	sort.Strings(matched)
+
+	return Factor{Name: "keywords", Value: value, Note: strings.Join(matched, ", ")}
+}
+
+// popularity boosts stories seen in several feeds.
+func popularity(i int, articles []model.Article, words []map[string]bool) Factor {
+	sources := make(map[int64]bool)
+	for j, other := range articles {
+		if other.SourceID == articles[i].SourceID {
+			continue
+		}
+		if similarity(words[i], words[j]) >= minSimilarity {
+			sources[other.SourceID] = true
+		}
+	}
+
+	if len(sources) == 0 {
+		return Factor{Name: "popularity", Value: 1}
+	}
+
+	return Factor{
+		Name:  "popularity",
+		Value: 1 + popularityBoost*float64(len(sources)),
+		Note:  fmt.Sprintf("in %d more feeds", len(sources)),
+	}
+}
+
+// recentPosts lowers the score of sources that were posted from recently.
+func recentPosts(n int) Factor {
+	if n == 0 {
+		return Factor{Name: "recent posts", Value: 1}
+	}
+	return Factor{Name: "recent posts", Value: 1 / float64(1+n), Note: fmt.Sprintf("%d posted", n)}
+}
+
+func score(factors []Factor) float64 {
+	result := 1.0
+	for _, f := range factors {
+		result *= f.Value
+	}
+	return result
+}
+
+// sortByScore sorts the articles by score, the newest first among equal ones.
+func sortByScore(ranked []Ranked) {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Article.PublishedAt.After(ranked[j].Article.PublishedAt)
+	})
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"how": true, "why": true, "what": true, "your": true, "you": true, "are": true,
+	"new": true, "this": true, "that": true, "its": true, "now": true,
+}
+
+// titleWords returns the significant lowercase words of a title.
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	}) {
+		w = strings.Trim(w, ".")
+		if len([]rune(w)) < 3 && !strings.ContainsFunc(w, unicode.IsDigit) || stopWords[w] {
+			continue
+		}
+		words[w] = true
+	}
+	return words
+}
+
+// similarity is the Jaccard index of two word sets, titles with less than 3 words are never similar.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) < 3 || len(b) < 3 {
+		return 0
+	}
+
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
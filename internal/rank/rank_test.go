@@ -0,0 +1,129 @@
+package rank_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/rank"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func article(id, sourceID int64, title string, age time.Duration) model.Article {
+	return model.Article{ID: id, SourceID: sourceID, Title: title, PublishedAt: now.Add(-age)}
+}
+
+func ids(ranked []rank.Ranked) []int64 {
+	var result []int64
+	for _, r := range ranked {
+		result = append(result, r.Article.ID)
+	}
+	return result
+}
+
+func assertOrder(t *testing.T, ranked []rank.Ranked, want ...int64) {
+	t.Helper()
+
+	got := ids(ranked)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRank_Freshness(t *testing.T) {
+	r := rank.New(nil, time.Hour)
+
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Old", 3*time.Hour),
+		article(2, 2, "Fresh", 0),
+	}, rank.Signals{Now: now})
+
+	assertOrder(t, ranked, 2, 1)
+	if score := ranked[1].Score; score < 0.124 || score > 0.126 {
+		t.Errorf("expected 3 half-lives to score 1/8, got %f", score)
+	}
+}
+
+func TestRank_SourcePriority(t *testing.T) {
+	r := rank.New(nil, 12*time.Hour)
+
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Fresh from a low priority source", 0),
+		article(2, 2, "Older from an important source", time.Hour),
+	}, rank.Signals{
+		Now: now,
+		Sources: map[int64]model.Source{
+			1: {ID: 1, Priority: 0.5},
+			2: {ID: 2, Priority: 2},
+		},
+	})
+
+	assertOrder(t, ranked, 2, 1)
+}
+
+func TestRank_RoundRobin(t *testing.T) {
+	r := rank.New(nil, 12*time.Hour)
+
+	// A burst from source 1 must not starve source 2.
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Burst one", 0),
+		article(2, 1, "Burst two", time.Minute),
+		article(3, 1, "Burst three", 2*time.Minute),
+		article(4, 2, "Other", time.Hour),
+	}, rank.Signals{Now: now})
+
+	assertOrder(t, ranked, 1, 4, 2, 3)
+}
+
+func TestRank_RecentPosts(t *testing.T) {
+	r := rank.New(nil, 12*time.Hour)
+
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Posted from a lot today", 0),
+		article(2, 2, "Not posted from today", time.Hour),
+	}, rank.Signals{Now: now, RecentPosts: map[int64]int{1: 3}})
+
+	assertOrder(t, ranked, 2, 1)
+}
+
+func TestRank_Keywords(t *testing.T) {
+	r := rank.New(map[string]float64{"Generics": 3, "crypto": 0.5}, 12*time.Hour)
+
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Crypto coins", 0),
+		article(2, 2, "Something else", time.Hour),
+		article(3, 3, "Generics in practice", 2*time.Hour),
+	}, rank.Signals{Now: now})
+
+	assertOrder(t, ranked, 3, 2, 1)
+
+	for _, f := range ranked[0].Factors {
+		if f.Name == "keywords" && f.Note != "generics" {
+			t.Errorf("unexpected keywords note %q", f.Note)
+		}
+	}
+}
+
+func TestRank_Popularity(t *testing.T) {
+	r := rank.New(nil, 12*time.Hour)
+
+	ranked := r.Rank([]model.Article{
+		article(1, 1, "Kubernetes operators explained", 0),
+		article(2, 2, "Go 1.24 released with generic type aliases", 2*time.Hour),
+		article(3, 3, "Go 1.24 is released: generic type aliases", 3*time.Hour),
+		article(4, 4, "Released: Go 1.24 with generic type aliases", 3*time.Hour),
+	}, rank.Signals{Now: now})
+
+	if ranked[0].Article.ID != 2 {
+		t.Errorf("expected the story seen in three feeds first, got %v", ids(ranked))
+	}
+	if ranked[len(ranked)-1].Article.ID != 1 {
+		t.Errorf("expected the unique story last, got %v", ids(ranked))
+	}
+}
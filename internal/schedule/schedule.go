@@ -0,0 +1,198 @@
+// Package schedule describes when a channel may be posted to: weekly windows in the channel's time zone.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a daily period of local time on some days of the week, e.g. "mon-fri 08:00-22:00".
+// A window ending before it starts lasts until the next day.
+type Window struct {
+	Days  [7]bool // indexed by time.Weekday
+	Start int     // minutes since midnight
+	End   int     // minutes since midnight, up to 24:00
+}
+
+type Schedule struct {
+	loc     *time.Location
+	windows []Window
+}
+
+// New creates a schedule, no windows means the channel may be posted to at any time.
+func New(loc *time.Location, windows []Window) *Schedule {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return &Schedule{
+		loc:     loc,
+		windows: windows,
+	}
+}
+
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// Open reports whether t is within a window.
+func (s *Schedule) Open(t time.Time) bool {
+	_, _, ok := s.Current(t)
+	return ok
+}
+
+// Current returns the window t is within. Zero times are returned for a schedule without windows.
+func (s *Schedule) Current(t time.Time) (start, end time.Time, ok bool) {
+	if len(s.windows) == 0 {
+		return time.Time{}, time.Time{}, true
+	}
+
+	local := t.In(s.loc)
+	for _, w := range s.windows {
+		// A window that started yesterday may last until today.
+		for _, offset := range []int{0, -1} {
+			year, month, day := local.Year(), local.Month(), local.Day()+offset
+			if !w.Days[time.Date(year, month, day, 0, 0, 0, 0, s.loc).Weekday()] {
+				continue
+			}
+
+			// Bounds are wall clock times: on days when clocks change, adding minutes to
+			// midnight would be an hour off.
+			start := time.Date(year, month, day, 0, w.Start, 0, 0, s.loc)
+			end := time.Date(year, month, day, 0, w.End, 0, 0, s.loc)
+			if w.End <= w.Start {
+				end = time.Date(year, month, day+1, 0, w.End, 0, 0, s.loc)
+			}
+
+			if !t.Before(start) && t.Before(end) {
+				return start, end, true
+			}
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWindows parses windows separated by semicolons, e.g. "mon-fri 08:00-22:00; sat,sun 10:00-20:00".
+// Days may be omitted for windows that are open every day.
+func ParseWindows(s string) ([]Window, error) {
+	var windows []Window
+
+	for _, spec := range strings.Split(s, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", spec, err)
+		}
+		windows = append(windows, w)
+	}
+
+	return windows, nil
+}
+
+func parseWindow(spec string) (Window, error) {
+	var w Window
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+	case 2:
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return Window{}, err
+		}
+		w.Days = days
+	default:
+		return Window{}, fmt.Errorf("expected days and hours")
+	}
+
+	start, end, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return Window{}, fmt.Errorf("expected hours like 08:00-22:00")
+	}
+
+	var err error
+	if w.Start, err = ParseClock(start); err != nil {
+		return Window{}, err
+	}
+	if w.End, err = ParseClock(end); err != nil {
+		return Window{}, err
+	}
+	if w.Start == w.End {
+		return Window{}, fmt.Errorf("window is empty")
+	}
+
+	return w, nil
+}
+
+// parseDays parses "mon-fri" or "sat,sun".
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+
+		first, ok := weekdays[from]
+		if !ok {
+			return days, fmt.Errorf("unknown day %q", from)
+		}
+
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return days, fmt.Errorf("unknown day %q", to)
+			}
+		}
+
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	return days, nil
+}
+
+// ParseClock parses a local time like "15:04" into minutes since midnight, "24:00" is allowed.
+func ParseClock(s string) (int, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || h == 24 && m != 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	return h*60 + m, nil
+}
+
+// Location loads a time zone by its IANA name, an empty name means UTC.
+func Location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
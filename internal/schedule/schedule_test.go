@@ -0,0 +1,146 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/schedule"
+)
+
+func TestParseWindows(t *testing.T) {
+	tests := []struct {
+		spec  string
+		valid bool
+	}{
+		{"", true},
+		{"08:00-22:00", true},
+		{"mon-fri 08:00-22:00; sat,sun 10:00-20:00", true},
+		{"fri-mon 22:00-02:00", true},
+		{"mon 00:00-24:00", true},
+		{"mon-fri", false},
+		{"funday 08:00-22:00", false},
+		{"mon 8-22", false},
+		{"mon 25:00-26:00", false},
+		{"mon 10:00-10:00", false},
+	}
+
+	for _, tt := range tests {
+		_, err := schedule.ParseWindows(tt.spec)
+		if tt.valid && err != nil {
+			t.Errorf("%q: unexpected error %v", tt.spec, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%q: expected an error", tt.spec)
+		}
+	}
+}
+
+func TestSchedule_Open(t *testing.T) {
+	berlin, err := schedule.Location("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	windows, err := schedule.ParseWindows("mon-fri 08:00-22:00; fri,sat 23:00-01:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := schedule.New(berlin, windows)
+
+	// 19 Oct 2026 is a Monday.
+	tests := []struct {
+		local string
+		open  bool
+	}{
+		{"2026-10-19 07:59", false},
+		{"2026-10-19 08:00", true},
+		{"2026-10-19 21:59", true},
+		{"2026-10-19 22:00", false},
+		{"2026-10-19 03:00", false},
+		{"2026-10-23 23:30", true},  // Friday night
+		{"2026-10-24 01:00", true},  // after midnight of the Friday window
+		{"2026-10-24 01:30", false}, // Saturday
+		{"2026-10-24 12:00", false},
+		{"2026-10-25 00:30", true}, // after midnight of the Saturday window
+		{"2026-10-26 00:30", false},
+	}
+
+	for _, tt := range tests {
+		at, err := time.ParseInLocation("2006-01-02 15:04", tt.local, berlin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The schedule works in its own time zone whatever the location of the given time.
+		if got := s.Open(at.UTC()); got != tt.open {
+			t.Errorf("%s: open = %v, want %v", tt.local, got, tt.open)
+		}
+	}
+}
+
+func TestSchedule_Open_DST(t *testing.T) {
+	berlin, err := schedule.Location("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	windows, err := schedule.ParseWindows("08:00-22:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := schedule.New(berlin, windows)
+
+	// Clocks go back from 03:00 CEST to 02:00 CET on 25 Oct 2026, the day has 25 hours.
+	start, end, ok := s.Current(time.Date(2026, 10, 25, 12, 0, 0, 0, berlin))
+	if !ok {
+		t.Fatal("expected the window to be open")
+	}
+	if want := time.Date(2026, 10, 25, 8, 0, 0, 0, berlin); !start.Equal(want) {
+		t.Errorf("start = %s, want %s", start, want)
+	}
+	if want := time.Date(2026, 10, 25, 22, 0, 0, 0, berlin); !end.Equal(want) {
+		t.Errorf("end = %s, want %s", end, want)
+	}
+
+	tests := []struct {
+		utc  string
+		open bool
+	}{
+		{"2026-10-25 06:59", false}, // 07:59 CET
+		{"2026-10-25 07:00", true},  // 08:00 CET
+		{"2026-10-25 20:59", true},  // 21:59 CET
+		{"2026-10-25 21:00", false}, // 22:00 CET
+	}
+	for _, tt := range tests {
+		at, err := time.Parse("2006-01-02 15:04", tt.utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Open(at); got != tt.open {
+			t.Errorf("%s UTC: open = %v, want %v", tt.utc, got, tt.open)
+		}
+	}
+}
+
+func TestSchedule_Current(t *testing.T) {
+	windows, err := schedule.ParseWindows("22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := schedule.New(time.UTC, windows)
+
+	start, end, ok := s.Current(time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC))
+	if !ok {
+		t.Fatal("expected the window to be open")
+	}
+	if want := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %s, want %s", start, want)
+	}
+	if want := time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %s, want %s", end, want)
+	}
+
+	if !schedule.New(nil, nil).Open(time.Now()) {
+		t.Error("a schedule without windows must always be open")
+	}
+}
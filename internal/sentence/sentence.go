@@ -0,0 +1,236 @@
+// Package sentence splits text into sentences.
+//
+// It is a rule based segmenter tuned for news: it does not break on decimals,
+// version numbers, URLs, common abbreviations and initials, keeps closing quotes
+// with their sentence and understands Cyrillic and CJK punctuation.
+package sentence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbreviations are lower-cased words (without the final period) that are
+// usually followed by more words of the same sentence.
+var abbreviations = map[string]struct{}{
+	// English
+	"mr": {}, "mrs": {}, "ms": {}, "dr": {}, "prof": {}, "sr": {}, "jr": {}, "st": {},
+	"vs": {}, "e.g": {}, "i.e": {}, "cf": {}, "fig": {}, "no": {}, "nos": {}, "approx": {},
+	"vol": {}, "p": {}, "pp": {}, "ch": {}, "sec": {}, "est": {}, "dept": {}, "gen": {},
+	"jan": {}, "feb": {}, "mar": {}, "apr": {}, "jun": {}, "jul": {}, "aug": {},
+	"sep": {}, "sept": {}, "oct": {}, "nov": {}, "dec": {},
+	"u.s": {}, "u.k": {}, "a.m": {}, "p.m": {},
+	// Russian
+	"т.е": {}, "т.к": {}, "т.н": {}, "т.ч": {}, "напр": {}, "г": {}, "гг": {}, "им": {},
+	"ул": {}, "д": {}, "см": {}, "стр": {}, "рис": {}, "тыс": {}, "млн": {}, "млрд": {},
+	"руб": {}, "коп": {}, "проф": {}, "акад": {}, "ок": {},
+}
+
+// Sentence terminators. CJK terminators end a sentence even without a following space.
+func isTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+func isCJKTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+// Closers are allowed between a terminator and the whitespace that follows it.
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', '”', '’', '»', ')', ']', '）', '」', '』':
+		return true
+	}
+	return false
+}
+
+// Split splits text into sentences. Terminal punctuation and closing quotes
+// are kept, surrounding whitespace is trimmed. Paragraph breaks always end a sentence.
+func Split(text string) []string {
+	runes := []rune(text)
+	n := len(runes)
+
+	var (
+		sentences []string
+		start     int
+	)
+
+	emit := func(end int) {
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		r := runes[i]
+
+		if r == '\n' {
+			if k := skipSpace(runes, i); k > i+1 && strings.Count(string(runes[i:k]), "\n") > 1 {
+				emit(i)
+				start = k
+				i = k - 1
+			}
+			continue
+		}
+
+		if !isTerminator(r) {
+			continue
+		}
+
+		j := i + 1
+		for j < n && isTerminator(runes[j]) {
+			j++
+		}
+		for j < n && isCloser(runes[j]) {
+			j++
+		}
+
+		if j == n {
+			emit(j)
+			start = j
+			break
+		}
+
+		if !unicode.IsSpace(runes[j]) && !isCJKTerminator(runes[j-1]) && !isCJKTerminator(r) {
+			// Decimals, versions, URLs, domains and "e.g.x" like tokens.
+			continue
+		}
+
+		k := skipSpace(runes, j)
+		if k == n {
+			emit(j)
+			start = k
+			break
+		}
+
+		if r == '.' && runes[i+1] != '.' && !breaksAfterPeriod(runes, start, i) {
+			continue
+		}
+
+		if unicode.IsLower(runes[k]) {
+			continue
+		}
+
+		emit(j)
+		start = k
+		i = k - 1
+	}
+
+	if start < n {
+		emit(n)
+	}
+
+	return sentences
+}
+
+// breaksAfterPeriod reports whether the single period at runes[dot] can end the sentence
+// that starts at runes[start], judging by the word in front of it.
+func breaksAfterPeriod(runes []rune, start, dot int) bool {
+	wordStart := dot
+	for wordStart > start && !unicode.IsSpace(runes[wordStart-1]) && !isOpener(runes[wordStart-1]) {
+		wordStart--
+	}
+
+	word := string(runes[wordStart:dot])
+	if word == "" {
+		return true
+	}
+
+	if _, ok := abbreviations[strings.ToLower(word)]; ok {
+		return false
+	}
+
+	wordRunes := []rune(word)
+
+	// Initials like "J. R. R. Tolkien".
+	if len(wordRunes) == 1 && unicode.IsUpper(wordRunes[0]) {
+		return false
+	}
+
+	// Numbered lists like "1. Install Go".
+	if wordStart == start && isNumber(word) {
+		return false
+	}
+
+	return true
+}
+
+func isOpener(r rune) bool {
+	switch r {
+	case '"', '\'', '“', '‘', '«', '(', '[', '（', '「', '『':
+		return true
+	}
+	return false
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func skipSpace(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+// IsComplete reports whether the sentence ends with terminal punctuation,
+// optionally followed by closing quotes or brackets.
+func IsComplete(s string) bool {
+	s = strings.TrimRightFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || isCloser(r)
+	})
+	if s == "" {
+		return false
+	}
+
+	runes := []rune(s)
+	return isTerminator(runes[len(runes)-1])
+}
+
+// TrimIncomplete drops the trailing unfinished sentence, e.g. one cut off by a token limit.
+// The text is returned as is if it has no complete sentences at all.
+func TrimIncomplete(text string) string {
+	sentences := Split(text)
+	if len(sentences) == 0 {
+		return strings.TrimSpace(text)
+	}
+
+	if last := sentences[len(sentences)-1]; !IsComplete(last) && len(sentences) > 1 {
+		sentences = sentences[:len(sentences)-1]
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// TruncateWords returns as many leading sentences of text as fit into maxWords words.
+// If even the first sentence is longer, it is cut at a word boundary and an ellipsis is added.
+func TruncateWords(text string, maxWords int) string {
+	var (
+		result    []string
+		wordCount int
+	)
+
+	for _, s := range Split(text) {
+		words := strings.Fields(s)
+		if wordCount+len(words) > maxWords {
+			if len(result) == 0 && maxWords > 0 {
+				return strings.Join(words[:maxWords], " ") + "…"
+			}
+			break
+		}
+		result = append(result, s)
+		wordCount += len(words)
+	}
+
+	return strings.Join(result, " ")
+}
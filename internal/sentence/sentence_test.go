@@ -0,0 +1,166 @@
+package sentence_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/sentence"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"whitespace only", " \n\t ", nil},
+		{"single without terminator", "Go is fun", []string{"Go is fun"}},
+		{"single", "Go is fun.", []string{"Go is fun."}},
+		{"two", "Go is fun. Rust is too.", []string{"Go is fun.", "Rust is too."}},
+		{"exclamation and question kept", "Wow! Is it out? Yes.", []string{"Wow!", "Is it out?", "Yes."}},
+		{"interrobang", "Really?! Yes.", []string{"Really?!", "Yes."}},
+		{"ellipsis", "Wait... It compiled.", []string{"Wait...", "It compiled."}},
+		{"unicode ellipsis", "Wait… It compiled.", []string{"Wait…", "It compiled."}},
+		{"ellipsis mid sentence", "Wait… it compiled.", []string{"Wait… it compiled."}},
+		{"no space after period", "Go.Rust.", []string{"Go.Rust."}},
+		{"extra spaces", "  One.   Two.  ", []string{"One.", "Two."}},
+
+		{"go version", "Go 1.22 is released. It adds range over int.", []string{"Go 1.22 is released.", "It adds range over int."}},
+		{"version at end", "Upgrade to Go 1.22. It is faster.", []string{"Upgrade to Go 1.22.", "It is faster."}},
+		{"semver", "Version v2.3.1-rc.1 fixes the bug. Update now.", []string{"Version v2.3.1-rc.1 fixes the bug.", "Update now."}},
+		{"decimal", "It is 3.5 times faster. Nice.", []string{"It is 3.5 times faster.", "Nice."}},
+		{"money", "It costs $3.99 per month. Cheap.", []string{"It costs $3.99 per month.", "Cheap."}},
+		{"percent", "Usage grew 12.5%. Nice.", []string{"Usage grew 12.5%.", "Nice."}},
+		{"ip address", "Bind to 127.0.0.1 by default. Then restart.", []string{"Bind to 127.0.0.1 by default.", "Then restart."}},
+
+		{"url", "See https://go.dev/blog/go1.22 for details. Enjoy.", []string{"See https://go.dev/blog/go1.22 for details.", "Enjoy."}},
+		{"url with query", "Open https://example.com/a?b=1&c=2! Then read.", []string{"Open https://example.com/a?b=1&c=2!", "Then read."}},
+		{"url at end", "Read more at https://go.dev.", []string{"Read more at https://go.dev."}},
+		{"domain", "Visit go.dev. It has docs.", []string{"Visit go.dev.", "It has docs."}},
+		{"email", "Write to team@golang.org. They answer.", []string{"Write to team@golang.org.", "They answer."}},
+		{"file name", "Edit main.go and run it. Done.", []string{"Edit main.go and run it.", "Done."}},
+
+		{"e.g.", "Use tools, e.g. gopls. They help.", []string{"Use tools, e.g. gopls.", "They help."}},
+		{"e.g. before capital", "Use an IDE, e.g. GoLand. It helps.", []string{"Use an IDE, e.g. GoLand.", "It helps."}},
+		{"i.e.", "The zero value, i.e. Nil for pointers. Done.", []string{"The zero value, i.e. Nil for pointers.", "Done."}},
+		{"mr", "Mr. Pike wrote it. Mrs. Smith agreed.", []string{"Mr. Pike wrote it.", "Mrs. Smith agreed."}},
+		{"dr and prof", "Dr. Who met Prof. Xavier. They talked.", []string{"Dr. Who met Prof. Xavier.", "They talked."}},
+		{"vs", "Go vs. Rust. Who wins?", []string{"Go vs. Rust.", "Who wins?"}},
+		{"month", "Released on Feb. 6 in the US. Enjoy.", []string{"Released on Feb. 6 in the US.", "Enjoy."}},
+		{"etc ends sentence", "Maps, slices, etc. They are built in.", []string{"Maps, slices, etc.", "They are built in."}},
+		{"u.s.", "The U.S. Government uses Go. Really.", []string{"The U.S. Government uses Go.", "Really."}},
+		{"case insensitive abbreviation", "See FIG. 3 below. Done.", []string{"See FIG. 3 below.", "Done."}},
+		{"abbreviation in parens", "Many tools (e.g. Vet) exist. Use them.", []string{"Many tools (e.g. Vet) exist.", "Use them."}},
+		{"initials", "J. R. R. Tolkien wrote books. Read them.", []string{"J. R. R. Tolkien wrote books.", "Read them."}},
+		{"numbered list", "1. Install Go and run it.", []string{"1. Install Go and run it."}},
+		{"lowercase after period", "Use the go. command carefully.", []string{"Use the go. command carefully."}},
+
+		{"double quotes", `He said "Ship it." Then left.`, []string{`He said "Ship it."`, "Then left."}},
+		{"curly quotes", "He said “Ship it.” Then left.", []string{"He said “Ship it.”", "Then left."}},
+		{"guillemets", "Он сказал «Готово.» Потом ушёл.", []string{"Он сказал «Готово.»", "Потом ушёл."}},
+		{"parenthesis", "Go is fast (really.) Use it.", []string{"Go is fast (really.)", "Use it."}},
+		{"next starts with quote", `It is out. "Great," they said.`, []string{"It is out.", `"Great," they said.`}},
+		{"next starts with digit", "Update now. 42 bugs were fixed.", []string{"Update now.", "42 bugs were fixed."}},
+
+		{"russian", "Вышел Go 1.22. Он быстрее.", []string{"Вышел Go 1.22.", "Он быстрее."}},
+		{"russian abbreviation", "Язык Go, т.е. Golang, прост. Попробуйте.", []string{"Язык Go, т.е. Golang, прост.", "Попробуйте."}},
+		{"russian year", "В 2024 г. Вышел релиз. Ура!", []string{"В 2024 г. Вышел релиз.", "Ура!"}},
+		{"russian numbers", "Привлекли 5 млрд. Рублей вложили. Хорошо.", []string{"Привлекли 5 млрд. Рублей вложили.", "Хорошо."}},
+		{"russian questions", "Что нового? Всё!", []string{"Что нового?", "Всё!"}},
+		{"chinese", "新版本发布了。性能提升很大！你试过吗？", []string{"新版本发布了。", "性能提升很大！", "你试过吗？"}},
+		{"japanese", "新しいバージョンです。試してください。", []string{"新しいバージョンです。", "試してください。"}},
+		{"chinese with closing quote", "他说「好。」然后走了。", []string{"他说「好。」", "然后走了。"}},
+		{"greek", "Η Go είναι γρήγορη. Δοκιμάστε τη.", []string{"Η Go είναι γρήγορη.", "Δοκιμάστε τη."}},
+		{"arabic caseless", "هذا جديد. جربه الآن.", []string{"هذا جديد.", "جربه الآن."}},
+
+		{"paragraph break", "First paragraph without dot\n\nSecond one.", []string{"First paragraph without dot", "Second one."}},
+		{"single newline joins", "A line that\ncontinues here.", []string{"A line that\ncontinues here."}},
+		{"newline after period", "First.\nSecond.", []string{"First.", "Second."}},
+		{"trailing fragment", "Complete one. And an incomplete", []string{"Complete one.", "And an incomplete"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sentence.Split(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q)\n got: %q\nwant: %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"", false},
+		{"Done.", true},
+		{"Done!", true},
+		{"Done?", true},
+		{"Done…", true},
+		{`"Done."`, true},
+		{"(Done.)  ", true},
+		{"完成。", true},
+		{"Not done", false},
+		{"Not done,", false},
+		{"Version 1.22", false},
+	}
+
+	for _, tt := range tests {
+		if got := sentence.IsComplete(tt.text); got != tt.want {
+			t.Errorf("IsComplete(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTrimIncomplete(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"complete", "Go 1.22 is out. It is fast!", "Go 1.22 is out. It is fast!"},
+		{"cut off", "Go 1.22 is out. It is fast and the", "Go 1.22 is out."},
+		{"cut off after version", "Go 1.22 is out. Upgrade to 1.", "Go 1.22 is out. Upgrade to 1."},
+		{"cut off inside version", "Go 1.22 is out. Upgrade to Go 1", "Go 1.22 is out."},
+		{"keeps exclamation", "Wow! Such", "Wow!"},
+		{"keeps single fragment", "Only a fragment", "Only a fragment"},
+		{"e.g. not cut", "Use tools, e.g. gopls. They", "Use tools, e.g. gopls."},
+		{"normalizes whitespace between sentences", "One.\nTwo.", "One. Two."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sentence.TrimIncomplete(tt.text); got != tt.want {
+				t.Errorf("TrimIncomplete(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxWords int
+		want     string
+	}{
+		{"fits", "Go is fun. Rust is too.", 10, "Go is fun. Rust is too."},
+		{"cuts at sentence", "Go is fun. Rust is too.", 4, "Go is fun."},
+		{"keeps punctuation", "Is Go fun? Yes! Sure.", 3, "Is Go fun?"},
+		{"keeps version numbers", "Go 1.22 is out. Update.", 4, "Go 1.22 is out."},
+		{"first sentence too long", "Go is a fast and simple language.", 3, "Go is a…"},
+		{"zero words", "Go is fun.", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sentence.TruncateWords(tt.text, tt.maxWords); got != tt.want {
+				t.Errorf("TruncateWords(%q, %d) = %q, want %q", tt.text, tt.maxWords, got, tt.want)
+			}
+		})
+	}
+}
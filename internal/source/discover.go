@@ -0,0 +1,224 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxDownloadSize limits the size of pages and feeds.
+const maxDownloadSize = 10 << 20
+
+// httpClient downloads pages and feeds, a site that doesn't respond mustn't hold up the bot.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// ErrNoFeeds is returned by Discover when neither the page links to a feed nor a common feed
+// path of its site has one.
+var ErrNoFeeds = errors.New("no feeds found")
+
+// feedTypes are the MIME types of feeds linked with <link rel="alternate">.
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// commonPaths are tried relative to the page and to the root of its site.
+var commonPaths = []string{"feed", "rss.xml", "index.xml"}
+
+// DiscoveredFeed is a feed found by Discover.
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+	Items int
+}
+
+// Discover returns the feeds of a website page: the page itself if it is a feed, else the feeds
+// it links to with <link rel="alternate"> and the ones found at common paths such as /feed.
+// Every candidate is downloaded and parsed, so only working feeds are returned, linked ones first.
+func Discover(ctx context.Context, pageURL string) ([]DiscoveredFeed, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q", pageURL)
+	}
+
+	data, finalURL, err := download(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if feed, err := parseFeed(data); err == nil {
+		return []DiscoveredFeed{{URL: finalURL.String(), Title: feed.Title, Items: len(feed.Items)}}, nil
+	}
+
+	candidates := append(linkedFeeds(data, finalURL), commonFeeds(finalURL)...)
+
+	var (
+		wg    sync.WaitGroup
+		found = make([]*DiscoveredFeed, len(candidates))
+		seen  = make(map[string]bool)
+	)
+	for i, candidate := range candidates {
+		key := NormalizeURL(candidate.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i] = validate(ctx, candidate)
+		}()
+	}
+	wg.Wait()
+
+	// Candidates can redirect to the same feed, e.g. /feed to /feed/.
+	var feeds []DiscoveredFeed
+	clear(seen)
+	for _, feed := range found {
+		if feed != nil && !seen[NormalizeURL(feed.URL)] {
+			seen[NormalizeURL(feed.URL)] = true
+			feeds = append(feeds, *feed)
+		}
+	}
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("%w at %s", ErrNoFeeds, pageURL)
+	}
+	return feeds, nil
+}
+
+// linkedFeeds returns the feeds the HTML page links to with <link rel="alternate">.
+func linkedFeeds(page []byte, base *url.URL) []DiscoveredFeed {
+	var feeds []DiscoveredFeed
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return feeds
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "base" {
+				if href, ok := resolve(base, attr(token, "href")); ok {
+					base = href
+				}
+			}
+			if token.Data != "link" || !hasToken(attr(token, "rel"), "alternate") {
+				continue
+			}
+
+			mediaType, _, _ := strings.Cut(strings.ToLower(attr(token, "type")), ";")
+			if !feedTypes[strings.TrimSpace(mediaType)] {
+				continue
+			}
+
+			if href, ok := resolve(base, attr(token, "href")); ok {
+				feeds = append(feeds, DiscoveredFeed{URL: href.String(), Title: attr(token, "title")})
+			}
+		case html.EndTagToken:
+			// Feeds are linked in the head, the body can be large.
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return feeds
+			}
+		}
+	}
+}
+
+// commonFeeds returns the common feed paths relative to the page and to the site root.
+func commonFeeds(page *url.URL) []DiscoveredFeed {
+	dirs := []string{"/"}
+	if dir := page.Path[:strings.LastIndex(page.Path, "/")+1]; dir != "/" && dir != "" {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	var feeds []DiscoveredFeed
+	for _, dir := range dirs {
+		for _, path := range commonPaths {
+			u := url.URL{Scheme: page.Scheme, Host: page.Host, Path: dir + path}
+			feeds = append(feeds, DiscoveredFeed{URL: u.String()})
+		}
+	}
+	return feeds
+}
+
+// validate downloads and parses the candidate, it returns nil if it isn't a feed. The URL of
+// the returned feed is the one the candidate redirects to.
+func validate(ctx context.Context, candidate DiscoveredFeed) *DiscoveredFeed {
+	data, finalURL, err := download(ctx, candidate.URL)
+	if err != nil {
+		return nil
+	}
+
+	feed, err := parseFeed(data)
+	if err != nil {
+		return nil
+	}
+
+	if feed.Title != "" {
+		candidate.Title = feed.Title
+	}
+	candidate.URL = finalURL.String()
+	candidate.Items = len(feed.Items)
+	return &candidate
+}
+
+// download returns the body of the URL and the URL it was redirected to.
+func download(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	return data, resp.Request.URL, nil
+}
+
+// resolve returns the absolute URL of a non-empty href.
+func resolve(base *url.URL, href string) (*url.URL, bool) {
+	if href == "" {
+		return nil, false
+	}
+	u, err := base.Parse(href)
+	return u, err == nil
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// hasToken reports whether the space separated list, e.g. a rel attribute, contains the token.
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
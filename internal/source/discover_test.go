@@ -0,0 +1,101 @@
+package source_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+)
+
+const (
+	blogPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Gopher blog</title>
+	<link rel="alternate" type="application/atom+xml" title="Atom" href="atom.xml">
+	<link rel="alternate" type="application/feed+json" href="/feed.json">
+	<link rel="alternate" type="application/rss+xml" href="/broken.xml">
+	<link rel="alternate" hreflang="de" href="/de/">
+	<link rel="stylesheet" type="text/css" href="/style.css">
+</head>
+<body><p>Hello.</p></body>
+</html>`
+
+	atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Gopher blog</title>
+	<entry><title>Hello</title><link href="https://example.com/blog/hello"/><id>1</id><updated>2026-10-19T10:00:00Z</updated></entry>
+</feed>`
+
+	jsonFeed = `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "Gopher blog (JSON)",
+	"items": [{"id": "1", "url": "https://example.com/blog/hello", "title": "Hello", "date_published": "2026-10-19T10:00:00Z"}]
+}`
+
+	rssFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Gopher site</title>
+	<item><title>Hello</title><link>https://example.com/hello</link></item>
+</channel></rss>`
+)
+
+func TestDiscover(t *testing.T) {
+	responses := map[string]string{
+		"/blog/":         blogPage,
+		"/blog/atom.xml": atomFeed,
+		"/feed.json":     jsonFeed,
+		"/broken.xml":    "<html><body>Not a feed</body></html>",
+		"/index.xml":     rssFeed,
+		"/blog/rss.xml":  blogPage,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	feeds, err := source.Discover(ctx, server.URL+"/blog/")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	want := []source.DiscoveredFeed{
+		{URL: server.URL + "/blog/atom.xml", Title: "Gopher blog", Items: 1},
+		{URL: server.URL + "/feed.json", Title: "Gopher blog (JSON)", Items: 1},
+		{URL: server.URL + "/index.xml", Title: "Gopher site", Items: 1},
+	}
+	if !reflect.DeepEqual(feeds, want) {
+		t.Errorf("got feeds\n%+v\nwant\n%+v", feeds, want)
+	}
+
+	// A feed URL is returned as is.
+	feeds, err = source.Discover(ctx, server.URL+"/feed.json")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != server.URL+"/feed.json" {
+		t.Errorf("got feeds %+v for a feed URL", feeds)
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><head><title>No feeds</title></head></html>"))
+	}))
+	defer empty.Close()
+
+	if _, err := source.Discover(ctx, empty.URL); !errors.Is(err, source.ErrNoFeeds) {
+		t.Errorf("got error %v for a page without feeds, want ErrNoFeeds", err)
+	}
+}
@@ -0,0 +1,102 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SlyMarbo/rss"
+)
+
+// jsonFeed is a JSON Feed, https://jsonfeed.org/version/1.1. Only the fields items are built
+// from are decoded.
+type jsonFeed struct {
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	HomePageURL string `json:"home_page_url"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Items       []struct {
+		ID            string   `json:"id"`
+		URL           string   `json:"url"`
+		Title         string   `json:"title"`
+		ContentHTML   string   `json:"content_html"`
+		ContentText   string   `json:"content_text"`
+		Summary       string   `json:"summary"`
+		Image         string   `json:"image"`
+		DatePublished string   `json:"date_published"`
+		Tags          []string `json:"tags"`
+	} `json:"items"`
+}
+
+// parseFeed parses an RSS, Atom or JSON feed. Unlike rss.Parse it fails on documents that
+// have neither a title nor items, e.g. web pages.
+func parseFeed(data []byte) (*rss.Feed, error) {
+	var (
+		feed *rss.Feed
+		err  error
+	)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		feed, err = parseJSONFeed(trimmed)
+	} else {
+		feed, err = rss.Parse(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if feed.Title == "" && len(feed.Items) == 0 {
+		return nil, errors.New("not a feed")
+	}
+	return feed, nil
+}
+
+func parseJSONFeed(data []byte) (*rss.Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, errors.New("not a JSON feed")
+	}
+
+	feed := &rss.Feed{
+		Title:       doc.Title,
+		Description: doc.Description,
+		Link:        doc.HomePageURL,
+	}
+	if doc.Icon != "" {
+		feed.Image = &rss.Image{URL: doc.Icon}
+	}
+
+	for _, item := range doc.Items {
+		summary := item.Summary
+		if summary == "" {
+			summary = item.ContentHTML
+		}
+		if summary == "" {
+			summary = item.ContentText
+		}
+
+		parsed := &rss.Item{
+			ID:         item.ID,
+			Title:      item.Title,
+			Summary:    summary,
+			Content:    item.ContentHTML,
+			Categories: item.Tags,
+			Link:       item.URL,
+		}
+		if date, err := time.Parse(time.RFC3339, item.DatePublished); err == nil {
+			parsed.Date, parsed.DateValid = date, true
+		}
+		if item.Image != "" {
+			parsed.Image = &rss.Image{URL: item.Image}
+		}
+		feed.Items = append(feed.Items, parsed)
+	}
+
+	return feed, nil
+}
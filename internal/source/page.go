@@ -0,0 +1,58 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/go-shiori/go-readability"
+)
+
+// FetchPage downloads an article page that is not in any feed and returns it as an item.
+// The summary of the item is the readable HTML of the page.
+func FetchPage(ctx context.Context, pageURL string) (model.Item, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return model.Item{}, fmt.Errorf("invalid article URL %q", pageURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return model.Item{}, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return model.Item{}, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.Item{}, fmt.Errorf("failed to fetch %s: %s", pageURL, resp.Status)
+	}
+
+	doc, err := readability.FromReader(resp.Body, u)
+	if err != nil {
+		return model.Item{}, fmt.Errorf("failed to parse %s: %w", pageURL, err)
+	}
+	if doc.Title == "" {
+		return model.Item{}, fmt.Errorf("no article found at %s", pageURL)
+	}
+
+	item := model.Item{
+		Title:      doc.Title,
+		Link:       pageURL,
+		Date:       time.Now(),
+		Summary:    doc.Content,
+		ImageURL:   doc.Image,
+		SourceName: doc.SiteName,
+	}
+	if doc.PublishedTime != nil {
+		item.Date = *doc.PublishedTime
+	}
+
+	return item, nil
+}
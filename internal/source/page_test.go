@@ -0,0 +1,75 @@
+package source_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Go 1.24 is released</title>
+	<meta property="og:title" content="Go 1.24 is released">
+	<meta property="og:image" content="https://example.com/gopher.png">
+	<meta property="og:site_name" content="The Go Blog">
+	<meta property="article:published_time" content="2025-02-11T10:00:00Z">
+</head>
+<body>
+	<article>
+		<h1>Go 1.24 is released</h1>
+		<p>Today the Go team is happy to release Go 1.24, which you can get by visiting the download page.
+		Go 1.24 comes with many improvements over Go 1.23, including generic type aliases and a new map implementation.</p>
+		<p>Swiss tables make maps faster, and the new weak package provides weak pointers for building caches.
+		Thanks to everyone who contributed to this release by writing code, filing bugs and testing the release candidates.</p>
+	</article>
+</body>
+</html>`
+
+func TestFetchPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/go1.24" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(articlePage))
+	}))
+	defer server.Close()
+
+	item, err := source.FetchPage(context.Background(), server.URL+"/go1.24")
+	if err != nil {
+		t.Fatalf("FetchPage failed: %v", err)
+	}
+
+	if item.Title != "Go 1.24 is released" {
+		t.Errorf("Title = %q", item.Title)
+	}
+	if item.Link != server.URL+"/go1.24" {
+		t.Errorf("Link = %q", item.Link)
+	}
+	if item.ImageURL != "https://example.com/gopher.png" {
+		t.Errorf("ImageURL = %q", item.ImageURL)
+	}
+	if item.SourceName != "The Go Blog" {
+		t.Errorf("SourceName = %q", item.SourceName)
+	}
+	if want := time.Date(2025, 2, 11, 10, 0, 0, 0, time.UTC); !item.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", item.Date, want)
+	}
+	if !strings.Contains(item.Summary, "Swiss tables") {
+		t.Errorf("Summary doesn't contain the article text: %q", item.Summary)
+	}
+
+	if _, err := source.FetchPage(context.Background(), server.URL+"/missing"); err == nil {
+		t.Error("FetchPage of a missing page succeeded")
+	}
+	if _, err := source.FetchPage(context.Background(), "ftp://example.com/article"); err == nil {
+		t.Error("FetchPage of a non-HTTP URL succeeded")
+	}
+}
@@ -2,56 +2,62 @@ package source
 
 import (
 	"context"
+	"strings"
 
 	"github.com/SlyMarbo/rss"
 	"github.com/amir-amirov/go-news-feed-bot/internal/model"
 )
 
 type RSSSource struct {
-	URL        string
-	SourceID   int64
-	SourceName string
+	URL            string
+	SourceID       int64
+	SourceName     string
+	SourceLanguage string
+}
+
+// FeedInfo describes the feed itself rather than its items.
+type FeedInfo struct {
+	HomepageURL string
+	Description string
+	IconURL     string
 }
 
 func NewRSSSourceFromModel(m model.Source) RSSSource {
 	return RSSSource{
-		URL:        m.FeedURL,
-		SourceID:   m.ID,
-		SourceName: m.Name,
+		URL:            m.FeedURL,
+		SourceID:       m.ID,
+		SourceName:     m.Name,
+		SourceLanguage: m.Language,
 	}
 }
 
 func (s RSSSource) loadFeed(ctx context.Context, url string) (*rss.Feed, error) {
-	var (
-		feedCh = make(chan *rss.Feed)
-		errCh  = make(chan error)
-	)
-
-	go func() {
-		feed, err := rss.Fetch(url)
-		if err != nil {
-			errCh <- err
-			return
-		}
-
-		feedCh <- feed
-	}()
-
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errCh:
+	data, _, err := download(ctx, url)
+	if err != nil {
 		return nil, err
-	case feed := <-feedCh:
-		return feed, nil
 	}
 
+	return parseFeed(data)
 }
 
 func (s RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	_, items, err := s.FetchFeed(ctx)
+	return items, err
+}
+
+// FetchFeed returns the items of the feed along with its homepage, description and icon.
+func (s RSSSource) FetchFeed(ctx context.Context) (FeedInfo, []model.Item, error) {
 	feed, err := s.loadFeed(ctx, s.URL)
 	if err != nil {
-		return nil, err
+		return FeedInfo{}, nil, err
+	}
+
+	info := FeedInfo{
+		HomepageURL: strings.TrimSpace(feed.Link),
+		Description: strings.TrimSpace(feed.Description),
+	}
+	if feed.Image != nil {
+		info.IconURL = strings.TrimSpace(feed.Image.URL)
 	}
 
 	items := make([]model.Item, 0, len(feed.Items))
@@ -62,11 +68,13 @@ func (s RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
 			Link:       item.Link,
 			Date:       item.Date,
 			Summary:    item.Summary,
+			Content:    item.Content,
+			ImageURL:   imageURL(item),
 			SourceName: s.SourceName,
 		})
 	}
 
-	return items, nil
+	return info, items, nil
 }
 
 func (s RSSSource) ID() int64 {
@@ -76,3 +84,23 @@ func (s RSSSource) ID() int64 {
 func (s RSSSource) Name() string {
 	return s.SourceName
 }
+
+// Language returns the language set by admins, empty if articles are detected instead.
+func (s RSSSource) Language() string {
+	return s.SourceLanguage
+}
+
+// imageURL returns the lead image of the item: its image or the first image enclosure.
+func imageURL(item *rss.Item) string {
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
+	}
+
+	for _, enclosure := range item.Enclosures {
+		if enclosure != nil && strings.HasPrefix(enclosure.Type, "image/") {
+			return enclosure.URL
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,30 @@
+package source
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL returns the key feed URLs are compared by to find duplicates: the scheme,
+// "www.", default ports, the fragment and trailing slashes are dropped, the host is lowercased
+// and query parameters are sorted. "HTTPS://www.Go.dev/blog/feed.atom/" and
+// "http://go.dev/blog/feed.atom" are the same feed.
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(rawURL)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	normalized := host + strings.TrimRight(u.EscapedPath(), "/")
+	if query := u.Query(); len(query) > 0 {
+		normalized += "?" + query.Encode()
+	}
+	return normalized
+}
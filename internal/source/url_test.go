@@ -0,0 +1,30 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/source"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://go.dev/blog/feed.atom", "http://go.dev/blog/feed.atom", true},
+		{"https://www.Go.dev/blog/feed.atom/", "https://go.dev/blog/feed.atom", true},
+		{"https://go.dev:443/blog/feed.atom#top", "https://go.dev/blog/feed.atom", true},
+		{"https://example.com/feed?b=2&a=1", "https://example.com/feed?a=1&b=2", true},
+		{" https://example.com/feed ", "https://example.com/feed", true},
+		{"https://example.com:8080/feed", "https://example.com/feed", false},
+		{"https://example.com/Feed", "https://example.com/feed", false},
+		{"https://example.com/feed?page=2", "https://example.com/feed", false},
+	}
+
+	for _, tt := range tests {
+		a, b := source.NormalizeURL(tt.a), source.NormalizeURL(tt.b)
+		if (a == b) != tt.same {
+			t.Errorf("NormalizeURL(%q) = %q, NormalizeURL(%q) = %q, same = %v, want %v", tt.a, a, tt.b, b, a == b, tt.same)
+		}
+	}
+}
@@ -3,14 +3,17 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/amir-amirov/go-news-feed-bot/internal/model"
 )
 
 type ArticlePostgresStorage struct {
-	db *sql.DB
+	db querier
 }
 
 func NewArticlePostgresStorage(db *sql.DB) *ArticlePostgresStorage {
@@ -19,16 +22,21 @@ func NewArticlePostgresStorage(db *sql.DB) *ArticlePostgresStorage {
 	}
 }
 
+// Store adds the article. If an article with the same link exists, its title is updated unless
+// an admin edited it, so that posts of the article can be edited too.
 func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Article) error {
 	query := `
-		INSERT INTO articles(source_id, title, link, summary, published_at)
-		VALUES($1, $2, $3, $4, $5)
-		ON CONFLICT (link) DO NOTHING
+		INSERT INTO articles(source_id, title, link, summary, content, language, image_url, published_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (link) DO UPDATE SET title = EXCLUDED.title, updated_at = $9::timestamp
+		WHERE NOT articles.title_edited AND articles.title <> EXCLUDED.title
 	`
-	// The ON CONFLICT clause ensures that if an article with the same link already exists,
-	// it will not be inserted and it will not return an error.
 
-	_, err := s.db.ExecContext(ctx, query, article.SourceID, article.Title, article.Link, article.Summary, article.PublishedAt)
+	// Articles added by admins may not come from any of the sources.
+	sourceID := sql.NullInt64{Int64: article.SourceID, Valid: article.SourceID != 0}
+
+	_, err := s.db.ExecContext(ctx, query, sourceID, article.Title, article.Link, article.Summary, article.Content, article.Language, article.ImageURL, article.PublishedAt,
+		time.Now().UTC().Format(time.RFC3339))
 	if err != nil {
 		return err
 	}
@@ -39,9 +47,9 @@ func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Articl
 func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error) {
 	log.Println("Fetching all not posted articles since", since, "with limit", limit)
 	query := `
-		SELECT id, source_id, title, link, summary, published_at, posted_at, created_at
+		SELECT ` + articleColumns + `
 		FROM articles
-		WHERE posted_at IS NULL AND published_at >= $1::timestamp
+		WHERE posted_at IS NULL AND NOT skipped AND retracted_at IS NULL AND published_at >= $1::timestamp
 		ORDER BY published_at DESC
 		LIMIT $2
 	`
@@ -51,23 +59,36 @@ func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Ti
 	}
 	defer rows.Close()
 
-	var articles []model.Article
-	for rows.Next() {
-		var dbArticle dbArticle
-		if err := rows.Scan(&dbArticle.ID, &dbArticle.SourceID, &dbArticle.Title, &dbArticle.Link, &dbArticle.Summary, &dbArticle.PublishedAt, &dbArticle.PostedAt, &dbArticle.CreatedAt); err != nil {
-			return nil, err
-		}
-		articles = append(articles, *modelArticleFromDB(dbArticle))
+	return scanArticles(rows)
+}
+
+// NotDeliveredTo returns the newest articles published since the given time that were not posted to the chat.
+// Skipped, scheduled and retracted articles are not returned.
+func (s *ArticlePostgresStorage) NotDeliveredTo(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM articles a
+		WHERE a.published_at >= $2::timestamp AND NOT a.skipped AND a.scheduled_at IS NULL AND a.retracted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.article_id = a.id AND d.chat_id = $1)
+		ORDER BY a.published_at DESC
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, chatID, since.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return articles, nil
+	return scanArticles(rows)
 }
 
+// MarkPosted records when the article was first posted.
 func (s *ArticlePostgresStorage) MarkPosted(ctx context.Context, id int64) error {
 	query := `
 		UPDATE articles
 		SET posted_at = $1::timestamp
-		WHERE id = $2
+		WHERE id = $2 AND posted_at IS NULL
 	`
 
 	_, err := s.db.ExecContext(ctx, query, time.Now().UTC().Format(time.RFC3339), id)
@@ -78,24 +99,319 @@ func (s *ArticlePostgresStorage) MarkPosted(ctx context.Context, id int64) error
 	return nil
 }
 
+// PostedCountsBySource returns the number of articles posted since the given time per source ID.
+func (s *ArticlePostgresStorage) PostedCountsBySource(ctx context.Context, since time.Time) (map[int64]int, error) {
+	query := `
+		SELECT source_id, COUNT(*)
+		FROM articles
+		WHERE posted_at >= $1::timestamp AND source_id IS NOT NULL
+		GROUP BY source_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var (
+			sourceID int64
+			count    int
+		)
+		if err := rows.Scan(&sourceID, &count); err != nil {
+			return nil, err
+		}
+		counts[sourceID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// ArticleByID returns the article with the given ID or nil if there is no such article.
+func (s *ArticlePostgresStorage) ArticleByID(ctx context.Context, id int64) (*model.Article, error) {
+	query := `SELECT ` + articleColumns + ` FROM articles WHERE id = $1`
+	return scanArticle(s.db.QueryRowContext(ctx, query, id))
+}
+
+// ArticleByLink returns the article with the given link or nil if there is no such article.
+func (s *ArticlePostgresStorage) ArticleByLink(ctx context.Context, link string) (*model.Article, error) {
+	query := `SELECT ` + articleColumns + ` FROM articles WHERE link = $1`
+	return scanArticle(s.db.QueryRowContext(ctx, query, link))
+}
+
+// Scheduled returns the articles scheduled since the given time that were not posted to the chat,
+// in the order they are scheduled.
+func (s *ArticlePostgresStorage) Scheduled(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM articles a
+		WHERE a.scheduled_at >= $2::timestamp AND NOT a.skipped AND a.retracted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.article_id = a.id AND d.chat_id = $1)
+		ORDER BY a.scheduled_at
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, chatID, since.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows)
+}
+
+// Schedule sets the time the article is posted at regardless of the queue.
+func (s *ArticlePostgresStorage) Schedule(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE articles SET scheduled_at = $2::timestamp, skipped = FALSE WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+// Skip keeps the article from being posted. The article stays in the table, so it is not fetched again.
+func (s *ArticlePostgresStorage) Skip(ctx context.Context, id int64) error {
+	query := `UPDATE articles SET skipped = TRUE, scheduled_at = NULL WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// UpdateTitle replaces the title of the article with one edited by an admin.
+func (s *ArticlePostgresStorage) UpdateTitle(ctx context.Context, id int64, title string) error {
+	query := `UPDATE articles SET title = $2, title_edited = TRUE, updated_at = $3::timestamp WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, title, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// PublishedSince returns the articles of the source published since the given time that are not retracted.
+func (s *ArticlePostgresStorage) PublishedSince(ctx context.Context, sourceID int64, since time.Time) ([]model.Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM articles
+		WHERE source_id = $1 AND published_at >= $2::timestamp AND retracted_at IS NULL
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, sourceID, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows)
+}
+
+// MarkRetracted records that the article was removed from its feed.
+func (s *ArticlePostgresStorage) MarkRetracted(ctx context.Context, id int64) error {
+	query := `UPDATE articles SET retracted_at = $2::timestamp WHERE id = $1 AND retracted_at IS NULL`
+
+	_, err := s.db.ExecContext(ctx, query, id, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// ClaimModeration marks the article as pending moderation if it is not moderated yet or
+// was postponed until now. It returns false if another instance sent it for moderation.
+func (s *ArticlePostgresStorage) ClaimModeration(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `
+		UPDATE articles
+		SET moderation_status = 'pending', postponed_until = NULL
+		WHERE id = $1 AND (moderation_status = '' OR (moderation_status = 'postponed' AND postponed_until <= $2::timestamp))
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SetModerationStatus records the moderation decision on the article, postponedUntil is only
+// stored for postponed articles.
+func (s *ArticlePostgresStorage) SetModerationStatus(ctx context.Context, id int64, status string, postponedUntil time.Time) error {
+	var until sql.NullString
+	if status == model.ModerationPostponed {
+		until = sql.NullString{String: postponedUntil.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	query := `
+		UPDATE articles
+		SET moderation_status = $2, postponed_until = $3::timestamp
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, status, until)
+	return err
+}
+
+// CountModeration returns the number of articles with the given moderation status. Articles posted
+// to channels that aren't moderated still count, they are waiting in the moderation chat all the same.
+func (s *ArticlePostgresStorage) CountModeration(ctx context.Context, status string) (int, error) {
+	query := `SELECT COUNT(*) FROM articles WHERE moderation_status = $1`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, status).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Summary returns the summary of the article written in the given language.
+// An empty string is returned if there is no such summary yet.
+func (s *ArticlePostgresStorage) Summary(ctx context.Context, articleID int64, language string) (string, error) {
+	query := `SELECT summary FROM article_summaries WHERE article_id = $1 AND language = $2`
+
+	var summary string
+	err := s.db.QueryRowContext(ctx, query, articleID, language).Scan(&summary)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return summary, nil
+}
+
+// Summaries returns the summaries of the articles by article ID and language, in one query.
+func (s *ArticlePostgresStorage) Summaries(ctx context.Context, articleIDs []int64) (map[int64]map[string]string, error) {
+	summaries := make(map[int64]map[string]string)
+	if len(articleIDs) == 0 {
+		return summaries, nil
+	}
+
+	placeholders := make([]string, len(articleIDs))
+	args := make([]any, len(articleIDs))
+	for i, id := range articleIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	query := `SELECT article_id, language, summary FROM article_summaries WHERE article_id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			articleID         int64
+			language, summary string
+		)
+		if err := rows.Scan(&articleID, &language, &summary); err != nil {
+			return nil, err
+		}
+		if summaries[articleID] == nil {
+			summaries[articleID] = make(map[string]string)
+		}
+		summaries[articleID][language] = summary
+	}
+
+	return summaries, rows.Err()
+}
+
+func (s *ArticlePostgresStorage) StoreSummary(ctx context.Context, articleID int64, language, summary string) error {
+	query := `
+		INSERT INTO article_summaries(article_id, language, summary)
+		VALUES($1, $2, $3)
+		ON CONFLICT (article_id, language) DO UPDATE SET summary = EXCLUDED.summary
+	`
+
+	_, err := s.db.ExecContext(ctx, query, articleID, language, summary)
+	return err
+}
+
+const articleColumns = `id, source_id, title, link, summary, language, image_url, moderation_status, postponed_until,
+		scheduled_at, skipped, title_edited, updated_at, retracted_at, published_at, posted_at, created_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanArticle(row scanner) (*model.Article, error) {
+	var dbArticle dbArticle
+	err := row.Scan(dbArticle.dest()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return modelArticleFromDB(dbArticle), nil
+}
+
+func scanArticles(rows *sql.Rows) ([]model.Article, error) {
+	var articles []model.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, *article)
+	}
+
+	return articles, rows.Err()
+}
+
 type dbArticle struct {
-	ID          int64        `db:"id"`
-	SourceID    int64        `db:"source_id"`
-	Title       string       `db:"title"`
-	Link        string       `db:"link"`
-	Summary     string       `db:"summary"`
+	ID       int64         `db:"id"`
+	SourceID sql.NullInt64 `db:"source_id"`
+	Title    string        `db:"title"`
+	Link     string        `db:"link"`
+	Summary  string        `db:"summary"`
+	Language string        `db:"language"`
+	ImageURL string        `db:"image_url"`
+
+	ModerationStatus string       `db:"moderation_status"`
+	PostponedUntil   sql.NullTime `db:"postponed_until"`
+	ScheduledAt      sql.NullTime `db:"scheduled_at"`
+	Skipped          bool         `db:"skipped"`
+	TitleEdited      bool         `db:"title_edited"`
+	UpdatedAt        sql.NullTime `db:"updated_at"`
+	RetractedAt      sql.NullTime `db:"retracted_at"`
+
 	PublishedAt time.Time    `db:"published_at"`
 	PostedAt    sql.NullTime `db:"posted_at"`
 	CreatedAt   time.Time    `db:"created_at"`
 }
 
+// dest returns the scan destinations of articleColumns.
+func (a *dbArticle) dest() []any {
+	return []any{
+		&a.ID, &a.SourceID, &a.Title, &a.Link, &a.Summary, &a.Language, &a.ImageURL,
+		&a.ModerationStatus, &a.PostponedUntil, &a.ScheduledAt, &a.Skipped,
+		&a.TitleEdited, &a.UpdatedAt, &a.RetractedAt,
+		&a.PublishedAt, &a.PostedAt, &a.CreatedAt,
+	}
+}
+
 func modelArticleFromDB(dbArticle dbArticle) *model.Article {
 	return &model.Article{
-		ID:          dbArticle.ID,
-		SourceID:    dbArticle.SourceID,
-		Title:       dbArticle.Title,
-		Link:        dbArticle.Link,
-		Summary:     dbArticle.Summary,
+		ID:       dbArticle.ID,
+		SourceID: dbArticle.SourceID.Int64, // NULL for articles added by admins without a source
+		Title:    dbArticle.Title,
+		Link:     dbArticle.Link,
+		Summary:  dbArticle.Summary,
+		Language: dbArticle.Language,
+		ImageURL: dbArticle.ImageURL,
+
+		ModerationStatus: dbArticle.ModerationStatus,
+		PostponedUntil:   dbArticle.PostponedUntil.Time,
+		ScheduledAt:      dbArticle.ScheduledAt.Time,
+		Skipped:          dbArticle.Skipped,
+		TitleEdited:      dbArticle.TitleEdited,
+		UpdatedAt:        dbArticle.UpdatedAt.Time,
+		RetractedAt:      dbArticle.RetractedAt.Time,
+
 		PublishedAt: dbArticle.PublishedAt,
 		CreatedAt:   dbArticle.CreatedAt,
 	}
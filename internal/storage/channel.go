@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+type ChannelPostgresStorage struct {
+	db querier
+}
+
+func NewChannelPostgresStorage(db *sql.DB) *ChannelPostgresStorage {
+	return &ChannelPostgresStorage{
+		db: db,
+	}
+}
+
+func (s *ChannelPostgresStorage) Channels(ctx context.Context) ([]model.Channel, error) {
+	query := `
+		SELECT id, language, template, parse_mode, send_photo, link_preview, read_more_button, discuss_url, timezone, moderated, tags,
+			posting_windows, catch_up, next_post_at, digest, digest_time, digest_weekday, digest_size, digest_overview, last_digest_at, created_at
+		FROM channels
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []model.Channel
+	for rows.Next() {
+		var dbCh dbChannel
+		if err := rows.Scan(
+			&dbCh.ID, &dbCh.Language, &dbCh.Template, &dbCh.ParseMode,
+			&dbCh.SendPhoto, &dbCh.LinkPreview, &dbCh.ReadMoreButton, &dbCh.DiscussURL, &dbCh.Timezone, &dbCh.Moderated, &dbCh.Tags,
+			&dbCh.PostingWindows, &dbCh.CatchUp, &dbCh.NextPostAt, &dbCh.Digest, &dbCh.DigestTime, &dbCh.DigestWeekday, &dbCh.DigestSize, &dbCh.DigestOverview, &dbCh.LastDigestAt,
+			&dbCh.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		channels = append(channels, *modelChannelFromDB(dbCh))
+	}
+
+	return channels, rows.Err()
+}
+
+// Upsert adds the channel or updates the language, moderation and tags of an existing one.
+func (s *ChannelPostgresStorage) Upsert(ctx context.Context, channel model.Channel) error {
+	query := `
+		INSERT INTO channels (id, language, moderated, tags)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET language = EXCLUDED.language, moderated = EXCLUDED.moderated, tags = EXCLUDED.tags
+	`
+
+	_, err := s.db.ExecContext(ctx, query, channel.ID, channel.Language, channel.Moderated, channel.Tags)
+	return err
+}
+
+// ClaimDigest records that the digest scheduled at the given time is being sent. It returns false
+// if it was already sent, e.g. by another instance.
+func (s *ChannelPostgresStorage) ClaimDigest(ctx context.Context, id int64, scheduledAt time.Time) (bool, error) {
+	query := `
+		UPDATE channels
+		SET last_digest_at = $2::timestamp
+		WHERE id = $1 AND (last_digest_at IS NULL OR last_digest_at < $2::timestamp)
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, scheduledAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ReleaseDigest drops the claim of the digest scheduled at the given time, so that it is sent
+// on the next try. previous is the time the digest sent before it was scheduled at, zero for none.
+func (s *ChannelPostgresStorage) ReleaseDigest(ctx context.Context, id int64, scheduledAt, previous time.Time) error {
+	var last sql.NullString
+	if !previous.IsZero() {
+		last = sql.NullString{String: previous.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	query := `
+		UPDATE channels
+		SET last_digest_at = $3::timestamp
+		WHERE id = $1 AND last_digest_at = $2::timestamp
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, scheduledAt.UTC().Format(time.RFC3339), last)
+	return err
+}
+
+// ClaimSlot reserves posting to the channel until the given time if its next post is due now.
+// It returns false if the post is not due or another instance claimed it.
+func (s *ChannelPostgresStorage) ClaimSlot(ctx context.Context, id int64, now, until time.Time) (bool, error) {
+	query := `
+		UPDATE channels
+		SET next_post_at = $3::timestamp
+		WHERE id = $1 AND (next_post_at IS NULL OR next_post_at <= $2::timestamp)
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, now.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SetNextPost sets the time the channel is posted to next.
+func (s *ChannelPostgresStorage) SetNextPost(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE channels SET next_post_at = $2::timestamp WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+type dbChannel struct {
+	ID             int64        `db:"id"`
+	Language       string       `db:"language"`
+	Template       string       `db:"template"`
+	ParseMode      string       `db:"parse_mode"`
+	SendPhoto      bool         `db:"send_photo"`
+	LinkPreview    string       `db:"link_preview"`
+	ReadMoreButton bool         `db:"read_more_button"`
+	DiscussURL     string       `db:"discuss_url"`
+	Timezone       string       `db:"timezone"`
+	Moderated      bool         `db:"moderated"`
+	Tags           string       `db:"tags"`
+	PostingWindows string       `db:"posting_windows"`
+	CatchUp        string       `db:"catch_up"`
+	NextPostAt     sql.NullTime `db:"next_post_at"`
+	Digest         string       `db:"digest"`
+	DigestTime     string       `db:"digest_time"`
+	DigestWeekday  int          `db:"digest_weekday"`
+	DigestSize     int          `db:"digest_size"`
+	DigestOverview bool         `db:"digest_overview"`
+	LastDigestAt   sql.NullTime `db:"last_digest_at"`
+	CreatedAt      time.Time    `db:"created_at"`
+}
+
+func modelChannelFromDB(dbChannel dbChannel) *model.Channel {
+	return &model.Channel{
+		ID:             dbChannel.ID,
+		Language:       dbChannel.Language,
+		Template:       dbChannel.Template,
+		ParseMode:      dbChannel.ParseMode,
+		SendPhoto:      dbChannel.SendPhoto,
+		LinkPreview:    dbChannel.LinkPreview,
+		ReadMoreButton: dbChannel.ReadMoreButton,
+		DiscussURL:     dbChannel.DiscussURL,
+		Timezone:       dbChannel.Timezone,
+		Moderated:      dbChannel.Moderated,
+		Tags:           dbChannel.Tags,
+		PostingWindows: dbChannel.PostingWindows,
+		CatchUp:        dbChannel.CatchUp,
+		NextPostAt:     dbChannel.NextPostAt.Time,
+		Digest:         dbChannel.Digest,
+		DigestTime:     dbChannel.DigestTime,
+		DigestWeekday:  time.Weekday(dbChannel.DigestWeekday),
+		DigestSize:     dbChannel.DigestSize,
+		DigestOverview: dbChannel.DigestOverview,
+		LastDigestAt:   dbChannel.LastDigestAt.Time,
+		CreatedAt:      dbChannel.CreatedAt,
+	}
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+type DeadLetterPostgresStorage struct {
+	db querier
+}
+
+func NewDeadLetterPostgresStorage(db *sql.DB) *DeadLetterPostgresStorage {
+	return &DeadLetterPostgresStorage{
+		db: db,
+	}
+}
+
+func (s *DeadLetterPostgresStorage) Store(ctx context.Context, deadLetter model.DeadLetter) error {
+	query := `
+		INSERT INTO dead_letters (chat_id, method, params, error)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, deadLetter.ChatID, deadLetter.Method, deadLetter.Params, deadLetter.Error)
+	return err
+}
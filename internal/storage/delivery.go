@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+type DeliveryPostgresStorage struct {
+	db querier
+}
+
+func NewDeliveryPostgresStorage(db *sql.DB) *DeliveryPostgresStorage {
+	return &DeliveryPostgresStorage{
+		db: db,
+	}
+}
+
+// Claim reserves posting the article to the chat. It returns false if the article was already
+// posted there or another instance claimed it less than lease before now: a stale claim of a crashed
+// instance is taken over.
+func (s *DeliveryPostgresStorage) Claim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error) {
+	query := `
+		INSERT INTO deliveries (article_id, chat_id, status, claimed_at)
+		VALUES ($1, $2, $3, $4::timestamp)
+		ON CONFLICT (article_id, chat_id) DO UPDATE SET claimed_at = EXCLUDED.claimed_at
+		WHERE deliveries.status = $3 AND deliveries.claimed_at < $5::timestamp
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		articleID, chatID, model.DeliveryClaimed, now.UTC().Format(time.RFC3339), now.Add(-lease).UTC().Format(time.RFC3339),
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return id, true, nil
+}
+
+// Reclaim is Claim for posts an admin asks for: a failed delivery is taken over too, so that
+// the article can be posted to the chat again.
+func (s *DeliveryPostgresStorage) Reclaim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error) {
+	query := `
+		INSERT INTO deliveries (article_id, chat_id, status, claimed_at)
+		VALUES ($1, $2, $3, $4::timestamp)
+		ON CONFLICT (article_id, chat_id) DO UPDATE SET status = $3, error = '', claimed_at = EXCLUDED.claimed_at
+		WHERE deliveries.status = $6 OR (deliveries.status = $3 AND deliveries.claimed_at < $5::timestamp)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		articleID, chatID, model.DeliveryClaimed, now.UTC().Format(time.RFC3339), now.Add(-lease).UTC().Format(time.RFC3339),
+		model.DeliveryFailed,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return id, true, nil
+}
+
+// MarkSent records the messages of the post, the first one is also stored as message_id.
+func (s *DeliveryPostgresStorage) MarkSent(ctx context.Context, id int64, kind string, messageIDs []int) error {
+	query := `
+		UPDATE deliveries
+		SET status = $2, kind = $3, message_id = $4, message_ids = $5, sent_at = $6::timestamp
+		WHERE id = $1
+	`
+
+	var messageID sql.NullInt64
+	if len(messageIDs) > 0 {
+		messageID = sql.NullInt64{Int64: int64(messageIDs[0]), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, query, id, model.DeliverySent, kind, messageID, formatMessageIDs(messageIDs), time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// MarkEdited records that the post was edited, messageIDs are the messages left after editing.
+func (s *DeliveryPostgresStorage) MarkEdited(ctx context.Context, id int64, messageIDs []int) error {
+	query := `UPDATE deliveries SET message_ids = $2, edited_at = $3::timestamp WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, formatMessageIDs(messageIDs), time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// MarkDeleted records that the messages of the post were deleted, the article is not posted to the chat again.
+func (s *DeliveryPostgresStorage) MarkDeleted(ctx context.Context, id int64) error {
+	query := `UPDATE deliveries SET status = $2, edited_at = $3::timestamp WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, model.DeliveryDeleted, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// Sent returns the posts of the article that were sent and not deleted.
+func (s *DeliveryPostgresStorage) Sent(ctx context.Context, articleID int64) ([]model.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM deliveries d WHERE d.article_id = $1 AND d.status = $2 ORDER BY d.id`
+
+	rows, err := s.db.QueryContext(ctx, query, articleID, model.DeliverySent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// Outdated returns the posts whose article title changed after they were sent or last edited.
+// Digests are not edited, so they are not returned.
+func (s *DeliveryPostgresStorage) Outdated(ctx context.Context, limit uint64) ([]model.Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM deliveries d
+		JOIN articles a ON a.id = d.article_id
+		WHERE d.status = $1 AND d.kind <> $2 AND a.updated_at > COALESCE(d.edited_at, d.sent_at)
+		ORDER BY d.id
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, model.DeliverySent, model.PostDigest, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// Retracted returns the posts of articles that were removed from their feeds.
+func (s *DeliveryPostgresStorage) Retracted(ctx context.Context, limit uint64) ([]model.Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM deliveries d
+		JOIN articles a ON a.id = d.article_id
+		WHERE d.status = $1 AND d.kind <> $2 AND a.retracted_at IS NOT NULL
+		ORDER BY d.id
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, model.DeliverySent, model.PostDigest, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// MarkFailed records that the post could not be delivered, it won't be retried. messageIDs are the
+// messages of the post left in the chat.
+func (s *DeliveryPostgresStorage) MarkFailed(ctx context.Context, id int64, reason string, messageIDs []int) error {
+	query := `UPDATE deliveries SET status = $2, error = $3, message_id = $4, message_ids = $5 WHERE id = $1`
+
+	var messageID sql.NullInt64
+	if len(messageIDs) > 0 {
+		messageID = sql.NullInt64{Int64: int64(messageIDs[0]), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, query, id, model.DeliveryFailed, reason, messageID, formatMessageIDs(messageIDs))
+	return err
+}
+
+// Release drops a claim that wasn't sent, so the article can be posted to the chat later.
+func (s *DeliveryPostgresStorage) Release(ctx context.Context, id int64) error {
+	query := `DELETE FROM deliveries WHERE id = $1 AND status = $2`
+
+	_, err := s.db.ExecContext(ctx, query, id, model.DeliveryClaimed)
+	return err
+}
+
+const deliveryColumns = `d.id, d.article_id, d.chat_id, d.status, d.kind, d.message_id, d.message_ids, d.error,
+	d.claimed_at, d.sent_at, d.edited_at`
+
+func scanDeliveries(rows *sql.Rows) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+	for rows.Next() {
+		var dbDelivery dbDelivery
+		if err := rows.Scan(
+			&dbDelivery.ID, &dbDelivery.ArticleID, &dbDelivery.ChatID, &dbDelivery.Status, &dbDelivery.Kind,
+			&dbDelivery.MessageID, &dbDelivery.MessageIDs, &dbDelivery.Error,
+			&dbDelivery.ClaimedAt, &dbDelivery.SentAt, &dbDelivery.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *modelDeliveryFromDB(dbDelivery))
+	}
+
+	return deliveries, rows.Err()
+}
+
+// formatMessageIDs stores message IDs as a comma separated list.
+func formatMessageIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseMessageIDs(s string) []int {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+type dbDelivery struct {
+	ID         int64         `db:"id"`
+	ArticleID  int64         `db:"article_id"`
+	ChatID     int64         `db:"chat_id"`
+	Status     string        `db:"status"`
+	Kind       string        `db:"kind"`
+	MessageID  sql.NullInt64 `db:"message_id"`
+	MessageIDs string        `db:"message_ids"`
+	Error      string        `db:"error"`
+	ClaimedAt  time.Time     `db:"claimed_at"`
+	SentAt     sql.NullTime  `db:"sent_at"`
+	EditedAt   sql.NullTime  `db:"edited_at"`
+}
+
+func modelDeliveryFromDB(dbDelivery dbDelivery) *model.Delivery {
+	delivery := &model.Delivery{
+		ID:         dbDelivery.ID,
+		ArticleID:  dbDelivery.ArticleID,
+		ChatID:     dbDelivery.ChatID,
+		Status:     dbDelivery.Status,
+		Kind:       dbDelivery.Kind,
+		MessageID:  int(dbDelivery.MessageID.Int64),
+		MessageIDs: parseMessageIDs(dbDelivery.MessageIDs),
+		Error:      dbDelivery.Error,
+		ClaimedAt:  dbDelivery.ClaimedAt,
+		SentAt:     dbDelivery.SentAt.Time,
+		EditedAt:   dbDelivery.EditedAt.Time,
+	}
+
+	// Posts sent before all message IDs were stored.
+	if len(delivery.MessageIDs) == 0 && delivery.MessageID != 0 {
+		delivery.MessageIDs = []int{delivery.MessageID}
+	}
+	if delivery.Kind == "" {
+		delivery.Kind = model.PostText
+	}
+
+	return delivery
+}
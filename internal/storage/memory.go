@@ -0,0 +1,767 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+// NewMemory returns repositories keeping everything in memory, for tests and trying the bot out
+// without a database. They behave like the PostgreSQL ones, see package storagetest.
+func NewMemory() *Storage {
+	db := &memoryDB{summaries: make(map[summaryKey]string)}
+
+	return &Storage{
+		Sources:     &SourceMemoryStorage{db: db},
+		Articles:    &ArticleMemoryStorage{db: db},
+		Channels:    &ChannelMemoryStorage{db: db},
+		Deliveries:  &DeliveryMemoryStorage{db: db},
+		DeadLetters: &DeadLetterMemoryStorage{db: db},
+	}
+}
+
+// memoryDB holds the tables shared by the memory repositories, e.g. articles are queued
+// depending on their deliveries.
+type memoryDB struct {
+	mu sync.Mutex
+
+	sources     []model.Source
+	articles    []model.Article
+	summaries   map[summaryKey]string
+	channels    []model.Channel
+	deliveries  []model.Delivery
+	deadLetters []model.DeadLetter
+
+	lastSourceID, lastArticleID, lastDeliveryID, lastDeadLetterID int64
+}
+
+type summaryKey struct {
+	articleID int64
+	language  string
+}
+
+// memoryNow returns the current time like the database stores it.
+func memoryNow() time.Time {
+	return time.Now().UTC()
+}
+
+func (db *memoryDB) source(id int64) *model.Source {
+	for i := range db.sources {
+		if db.sources[i].ID == id {
+			return &db.sources[i]
+		}
+	}
+	return nil
+}
+
+func (db *memoryDB) article(id int64) *model.Article {
+	for i := range db.articles {
+		if db.articles[i].ID == id {
+			return &db.articles[i]
+		}
+	}
+	return nil
+}
+
+func (db *memoryDB) channel(id int64) *model.Channel {
+	for i := range db.channels {
+		if db.channels[i].ID == id {
+			return &db.channels[i]
+		}
+	}
+	return nil
+}
+
+func (db *memoryDB) delivery(id int64) *model.Delivery {
+	for i := range db.deliveries {
+		if db.deliveries[i].ID == id {
+			return &db.deliveries[i]
+		}
+	}
+	return nil
+}
+
+func (db *memoryDB) delivered(articleID, chatID int64) bool {
+	return slices.ContainsFunc(db.deliveries, func(d model.Delivery) bool {
+		return d.ArticleID == articleID && d.ChatID == chatID
+	})
+}
+
+// selectArticles returns copies of the articles matching the filter, at most limit of them if it isn't zero.
+func (db *memoryDB) selectArticles(match func(a model.Article) bool, order func(a, b model.Article) int, limit uint64) []model.Article {
+	var articles []model.Article
+	for _, article := range db.articles {
+		if match(article) {
+			articles = append(articles, article)
+		}
+	}
+
+	if order != nil {
+		slices.SortStableFunc(articles, order)
+	}
+	if limit > 0 && uint64(len(articles)) > limit {
+		articles = articles[:limit]
+	}
+	return articles
+}
+
+// selectDeliveries returns copies of the sent posts matching the filter ordered by ID.
+func (db *memoryDB) selectDeliveries(match func(d model.Delivery) bool, limit uint64) []model.Delivery {
+	var deliveries []model.Delivery
+	for _, delivery := range db.deliveries {
+		if !match(delivery) {
+			continue
+		}
+		delivery.MessageIDs = slices.Clone(delivery.MessageIDs)
+		deliveries = append(deliveries, delivery)
+		if limit > 0 && uint64(len(deliveries)) == limit {
+			break
+		}
+	}
+	return deliveries
+}
+
+func newestFirst(a, b model.Article) int {
+	return b.PublishedAt.Compare(a.PublishedAt)
+}
+
+// notBefore reports whether t is set and not before since, like "t >= since" in SQL.
+func notBefore(t, since time.Time) bool {
+	return !t.IsZero() && !t.Before(since)
+}
+
+type SourceMemoryStorage struct {
+	db *memoryDB
+}
+
+func (s *SourceMemoryStorage) Sources(_ context.Context) ([]model.Source, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return slices.Clone(s.db.sources), nil
+}
+
+func (s *SourceMemoryStorage) SourceByID(_ context.Context, id int64) (*model.Source, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, source := range s.db.sources {
+		if source.ID == id {
+			return &source, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *SourceMemoryStorage) Add(_ context.Context, source model.Source) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.lastSourceID++
+	s.db.sources = append(s.db.sources, model.Source{
+		ID:        s.db.lastSourceID,
+		Name:      source.Name,
+		FeedURL:   source.FeedURL,
+		Priority:  1,
+		Tags:      normalizeTags(source.Tags),
+		Language:  source.Language,
+		CreatedAt: memoryNow(),
+	})
+
+	return s.db.lastSourceID, nil
+}
+
+// Update saves the fields admins edit: the name, feed URL, priority, tags, language,
+// pause and homepage. The priority must be positive.
+func (s *SourceMemoryStorage) Update(_ context.Context, source model.Source) error {
+	if source.Priority <= 0 {
+		return fmt.Errorf("invalid priority %g of source %d, it must be positive", source.Priority, source.ID)
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored := s.db.source(source.ID)
+	if stored == nil {
+		return sql.ErrNoRows
+	}
+
+	stored.Name = source.Name
+	stored.FeedURL = source.FeedURL
+	stored.Priority = source.Priority
+	stored.Tags = normalizeTags(source.Tags)
+	stored.Language = source.Language
+	stored.Paused = source.Paused
+	stored.HomepageURL = source.HomepageURL
+	stored.UpdatedAt = memoryNow()
+	return nil
+}
+
+// SetFeedInfo saves the description and icon of the source's feed, and its homepage unless one
+// is set already.
+func (s *SourceMemoryStorage) SetFeedInfo(_ context.Context, id int64, homepageURL, description, iconURL string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored := s.db.source(id)
+	if stored == nil {
+		return nil
+	}
+
+	if stored.HomepageURL == "" {
+		stored.HomepageURL = homepageURL
+	}
+	stored.Description = description
+	stored.IconURL = iconURL
+	stored.UpdatedAt = memoryNow()
+	return nil
+}
+
+// Delete removes the source with its articles.
+func (s *SourceMemoryStorage) Delete(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.sources = slices.DeleteFunc(s.db.sources, func(source model.Source) bool { return source.ID == id })
+
+	var removed []int64
+	s.db.articles = slices.DeleteFunc(s.db.articles, func(article model.Article) bool {
+		if article.SourceID == id {
+			removed = append(removed, article.ID)
+			return true
+		}
+		return false
+	})
+	s.db.deliveries = slices.DeleteFunc(s.db.deliveries, func(d model.Delivery) bool { return slices.Contains(removed, d.ArticleID) })
+	for key := range s.db.summaries {
+		if slices.Contains(removed, key.articleID) {
+			delete(s.db.summaries, key)
+		}
+	}
+
+	return nil
+}
+
+type ArticleMemoryStorage struct {
+	db *memoryDB
+}
+
+// Store adds the article. If an article with the same link exists, its title is updated unless
+// an admin edited it.
+func (s *ArticleMemoryStorage) Store(_ context.Context, article model.Article) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for i := range s.db.articles {
+		stored := &s.db.articles[i]
+		if stored.Link != article.Link {
+			continue
+		}
+
+		if !stored.TitleEdited && stored.Title != article.Title {
+			stored.Title = article.Title
+			stored.UpdatedAt = memoryNow()
+		}
+		return nil
+	}
+
+	s.db.lastArticleID++
+	s.db.articles = append(s.db.articles, model.Article{
+		ID:          s.db.lastArticleID,
+		SourceID:    article.SourceID,
+		Title:       article.Title,
+		Link:        article.Link,
+		Summary:     article.Summary,
+		Content:     article.Content,
+		Language:    article.Language,
+		ImageURL:    article.ImageURL,
+		PublishedAt: article.PublishedAt.UTC(),
+		CreatedAt:   memoryNow(),
+	})
+
+	return nil
+}
+
+func (s *ArticleMemoryStorage) AllNotPosted(_ context.Context, since time.Time, limit uint64) ([]model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectArticles(func(a model.Article) bool {
+		return a.PostedAt.IsZero() && !a.Skipped && a.RetractedAt.IsZero() && notBefore(a.PublishedAt, since)
+	}, newestFirst, limit), nil
+}
+
+// NotDeliveredTo returns the newest articles published since the given time that were not posted to the chat.
+// Skipped, scheduled and retracted articles are not returned.
+func (s *ArticleMemoryStorage) NotDeliveredTo(_ context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectArticles(func(a model.Article) bool {
+		return notBefore(a.PublishedAt, since) && !a.Skipped && a.ScheduledAt.IsZero() && a.RetractedAt.IsZero() &&
+			!s.db.delivered(a.ID, chatID)
+	}, newestFirst, limit), nil
+}
+
+// MarkPosted records when the article was first posted.
+func (s *ArticleMemoryStorage) MarkPosted(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if article := s.db.article(id); article != nil && article.PostedAt.IsZero() {
+		article.PostedAt = memoryNow()
+	}
+	return nil
+}
+
+// PostedCountsBySource returns the number of articles posted since the given time per source ID.
+func (s *ArticleMemoryStorage) PostedCountsBySource(_ context.Context, since time.Time) (map[int64]int, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	counts := make(map[int64]int)
+	for _, article := range s.db.articles {
+		if article.SourceID != 0 && notBefore(article.PostedAt, since) {
+			counts[article.SourceID]++
+		}
+	}
+	return counts, nil
+}
+
+// ArticleByID returns the article with the given ID or nil if there is no such article.
+func (s *ArticleMemoryStorage) ArticleByID(_ context.Context, id int64) (*model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if article := s.db.article(id); article != nil {
+		found := *article
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// ArticleByLink returns the article with the given link or nil if there is no such article.
+func (s *ArticleMemoryStorage) ArticleByLink(_ context.Context, link string) (*model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, article := range s.db.articles {
+		if article.Link == link {
+			return &article, nil
+		}
+	}
+	return nil, nil
+}
+
+// Scheduled returns the articles scheduled since the given time that were not posted to the chat,
+// in the order they are scheduled.
+func (s *ArticleMemoryStorage) Scheduled(_ context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectArticles(func(a model.Article) bool {
+		return notBefore(a.ScheduledAt, since) && !a.Skipped && a.RetractedAt.IsZero() && !s.db.delivered(a.ID, chatID)
+	}, func(a, b model.Article) int {
+		return a.ScheduledAt.Compare(b.ScheduledAt)
+	}, limit), nil
+}
+
+// Schedule sets the time the article is posted at regardless of the queue.
+func (s *ArticleMemoryStorage) Schedule(_ context.Context, id int64, at time.Time) error {
+	return s.update(id, func(article *model.Article) {
+		article.ScheduledAt = at.UTC()
+		article.Skipped = false
+	})
+}
+
+// Skip keeps the article from being posted.
+func (s *ArticleMemoryStorage) Skip(_ context.Context, id int64) error {
+	return s.update(id, func(article *model.Article) {
+		article.Skipped = true
+		article.ScheduledAt = time.Time{}
+	})
+}
+
+// UpdateTitle replaces the title of the article with one edited by an admin.
+func (s *ArticleMemoryStorage) UpdateTitle(_ context.Context, id int64, title string) error {
+	return s.update(id, func(article *model.Article) {
+		article.Title = title
+		article.TitleEdited = true
+		article.UpdatedAt = memoryNow()
+	})
+}
+
+// PublishedSince returns the articles of the source published since the given time that are not retracted.
+func (s *ArticleMemoryStorage) PublishedSince(_ context.Context, sourceID int64, since time.Time) ([]model.Article, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectArticles(func(a model.Article) bool {
+		return a.SourceID == sourceID && notBefore(a.PublishedAt, since) && a.RetractedAt.IsZero()
+	}, nil, 0), nil
+}
+
+// MarkRetracted records that the article was removed from its feed.
+func (s *ArticleMemoryStorage) MarkRetracted(_ context.Context, id int64) error {
+	return s.update(id, func(article *model.Article) {
+		if article.RetractedAt.IsZero() {
+			article.RetractedAt = memoryNow()
+		}
+	})
+}
+
+// ClaimModeration marks the article as pending moderation if it is not moderated yet or
+// was postponed until now.
+func (s *ArticleMemoryStorage) ClaimModeration(_ context.Context, id int64, now time.Time) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	article := s.db.article(id)
+	if article == nil {
+		return false, nil
+	}
+
+	due := article.ModerationStatus == model.ModerationPostponed && !article.PostponedUntil.IsZero() && !article.PostponedUntil.After(now)
+	if article.ModerationStatus != "" && !due {
+		return false, nil
+	}
+
+	article.ModerationStatus = model.ModerationPending
+	article.PostponedUntil = time.Time{}
+	return true, nil
+}
+
+// SetModerationStatus records the moderation decision on the article, postponedUntil is only
+// stored for postponed articles.
+func (s *ArticleMemoryStorage) SetModerationStatus(_ context.Context, id int64, status string, postponedUntil time.Time) error {
+	return s.update(id, func(article *model.Article) {
+		article.ModerationStatus = status
+		article.PostponedUntil = time.Time{}
+		if status == model.ModerationPostponed {
+			article.PostponedUntil = postponedUntil.UTC()
+		}
+	})
+}
+
+// CountModeration returns the number of articles with the given moderation status.
+func (s *ArticleMemoryStorage) CountModeration(_ context.Context, status string) (int, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	count := 0
+	for _, article := range s.db.articles {
+		if article.ModerationStatus == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Summary returns the summary of the article written in the given language.
+// An empty string is returned if there is no such summary yet.
+func (s *ArticleMemoryStorage) Summary(_ context.Context, articleID int64, language string) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.summaries[summaryKey{articleID, language}], nil
+}
+
+// Summaries returns the summaries of the articles by article ID and language.
+func (s *ArticleMemoryStorage) Summaries(_ context.Context, articleIDs []int64) (map[int64]map[string]string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	summaries := make(map[int64]map[string]string)
+	for key, summary := range s.db.summaries {
+		if !slices.Contains(articleIDs, key.articleID) {
+			continue
+		}
+		if summaries[key.articleID] == nil {
+			summaries[key.articleID] = make(map[string]string)
+		}
+		summaries[key.articleID][key.language] = summary
+	}
+	return summaries, nil
+}
+
+func (s *ArticleMemoryStorage) StoreSummary(_ context.Context, articleID int64, language, summary string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.summaries[summaryKey{articleID, language}] = summary
+	return nil
+}
+
+func (s *ArticleMemoryStorage) update(id int64, fn func(article *model.Article)) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if article := s.db.article(id); article != nil {
+		fn(article)
+	}
+	return nil
+}
+
+type ChannelMemoryStorage struct {
+	db *memoryDB
+}
+
+func (s *ChannelMemoryStorage) Channels(_ context.Context) ([]model.Channel, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	channels := slices.Clone(s.db.channels)
+	slices.SortStableFunc(channels, func(a, b model.Channel) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return channels, nil
+}
+
+// Upsert adds the channel with the defaults of the channels table or updates the language and
+// moderation of an existing one.
+func (s *ChannelMemoryStorage) Upsert(_ context.Context, channel model.Channel) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if stored := s.db.channel(channel.ID); stored != nil {
+		stored.Language = channel.Language
+		stored.Moderated = channel.Moderated
+		stored.Tags = channel.Tags
+		return nil
+	}
+
+	s.db.channels = append(s.db.channels, model.Channel{
+		ID:            channel.ID,
+		Language:      channel.Language,
+		Moderated:     channel.Moderated,
+		Tags:          channel.Tags,
+		Timezone:      "UTC",
+		CatchUp:       model.CatchUpSpread,
+		DigestTime:    "09:00",
+		DigestWeekday: time.Monday,
+		DigestSize:    10,
+		CreatedAt:     memoryNow(),
+	})
+	return nil
+}
+
+// ClaimDigest records that the digest scheduled at the given time is being sent. It returns false
+// if it was already sent.
+func (s *ChannelMemoryStorage) ClaimDigest(_ context.Context, id int64, scheduledAt time.Time) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	channel := s.db.channel(id)
+	if channel == nil || (!channel.LastDigestAt.IsZero() && !channel.LastDigestAt.Before(scheduledAt)) {
+		return false, nil
+	}
+
+	channel.LastDigestAt = scheduledAt.UTC()
+	return true, nil
+}
+
+// ReleaseDigest drops the claim of the digest scheduled at the given time, so that it is sent
+// on the next try. previous is the time the digest sent before it was scheduled at, zero for none.
+func (s *ChannelMemoryStorage) ReleaseDigest(_ context.Context, id int64, scheduledAt, previous time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if channel := s.db.channel(id); channel != nil && channel.LastDigestAt.Equal(scheduledAt) {
+		channel.LastDigestAt = previous.UTC()
+	}
+	return nil
+}
+
+// ClaimSlot reserves posting to the channel until the given time if its next post is due now.
+func (s *ChannelMemoryStorage) ClaimSlot(_ context.Context, id int64, now, until time.Time) (bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	channel := s.db.channel(id)
+	if channel == nil || channel.NextPostAt.After(now) {
+		return false, nil
+	}
+
+	channel.NextPostAt = until.UTC()
+	return true, nil
+}
+
+// SetNextPost sets the time the channel is posted to next.
+func (s *ChannelMemoryStorage) SetNextPost(_ context.Context, id int64, at time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if channel := s.db.channel(id); channel != nil {
+		channel.NextPostAt = at.UTC()
+	}
+	return nil
+}
+
+type DeliveryMemoryStorage struct {
+	db *memoryDB
+}
+
+// Claim reserves posting the article to the chat. It returns false if the article was already
+// posted there or claimed less than lease before now.
+func (s *DeliveryMemoryStorage) Claim(_ context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error) {
+	return s.claim(articleID, chatID, now, lease, false)
+}
+
+// Reclaim is Claim for posts an admin asks for: a failed delivery is taken over too.
+func (s *DeliveryMemoryStorage) Reclaim(_ context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error) {
+	return s.claim(articleID, chatID, now, lease, true)
+}
+
+func (s *DeliveryMemoryStorage) claim(articleID, chatID int64, now time.Time, lease time.Duration, failed bool) (int64, bool, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now = now.UTC()
+	for i := range s.db.deliveries {
+		delivery := &s.db.deliveries[i]
+		if delivery.ArticleID != articleID || delivery.ChatID != chatID {
+			continue
+		}
+
+		expired := delivery.Status == model.DeliveryClaimed && delivery.ClaimedAt.Before(now.Add(-lease))
+		if !expired && !(failed && delivery.Status == model.DeliveryFailed) {
+			return 0, false, nil
+		}
+		delivery.Status = model.DeliveryClaimed
+		delivery.Error = ""
+		delivery.ClaimedAt = now
+		return delivery.ID, true, nil
+	}
+
+	s.db.lastDeliveryID++
+	s.db.deliveries = append(s.db.deliveries, model.Delivery{
+		ID:        s.db.lastDeliveryID,
+		ArticleID: articleID,
+		ChatID:    chatID,
+		Status:    model.DeliveryClaimed,
+		ClaimedAt: now,
+	})
+	return s.db.lastDeliveryID, true, nil
+}
+
+// MarkSent records the messages of the post.
+func (s *DeliveryMemoryStorage) MarkSent(_ context.Context, id int64, kind string, messageIDs []int) error {
+	return s.update(id, func(delivery *model.Delivery) {
+		delivery.Status = model.DeliverySent
+		delivery.Kind = kind
+		delivery.MessageID = 0
+		if len(messageIDs) > 0 {
+			delivery.MessageID = messageIDs[0]
+		}
+		delivery.MessageIDs = slices.Clone(messageIDs)
+		delivery.SentAt = memoryNow()
+	})
+}
+
+// MarkEdited records that the post was edited, messageIDs are the messages left after editing.
+func (s *DeliveryMemoryStorage) MarkEdited(_ context.Context, id int64, messageIDs []int) error {
+	return s.update(id, func(delivery *model.Delivery) {
+		delivery.MessageIDs = slices.Clone(messageIDs)
+		delivery.EditedAt = memoryNow()
+	})
+}
+
+// MarkDeleted records that the messages of the post were deleted.
+func (s *DeliveryMemoryStorage) MarkDeleted(_ context.Context, id int64) error {
+	return s.update(id, func(delivery *model.Delivery) {
+		delivery.Status = model.DeliveryDeleted
+		delivery.EditedAt = memoryNow()
+	})
+}
+
+// Sent returns the posts of the article that were sent and not deleted.
+func (s *DeliveryMemoryStorage) Sent(_ context.Context, articleID int64) ([]model.Delivery, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectDeliveries(func(d model.Delivery) bool {
+		return d.ArticleID == articleID && d.Status == model.DeliverySent
+	}, 0), nil
+}
+
+// Outdated returns the posts whose article title changed after they were sent or last edited.
+// Digests are not edited, so they are not returned.
+func (s *DeliveryMemoryStorage) Outdated(_ context.Context, limit uint64) ([]model.Delivery, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectDeliveries(func(d model.Delivery) bool {
+		article := s.db.article(d.ArticleID)
+		if d.Status != model.DeliverySent || d.Kind == model.PostDigest || article == nil {
+			return false
+		}
+		return article.UpdatedAt.After(cmp.Or(d.EditedAt, d.SentAt))
+	}, limit), nil
+}
+
+// Retracted returns the posts of articles that were removed from their feeds.
+func (s *DeliveryMemoryStorage) Retracted(_ context.Context, limit uint64) ([]model.Delivery, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.selectDeliveries(func(d model.Delivery) bool {
+		article := s.db.article(d.ArticleID)
+		return d.Status == model.DeliverySent && d.Kind != model.PostDigest && article != nil && !article.RetractedAt.IsZero()
+	}, limit), nil
+}
+
+// MarkFailed records that the post could not be delivered, it won't be retried. messageIDs are the
+// messages of the post left in the chat.
+func (s *DeliveryMemoryStorage) MarkFailed(_ context.Context, id int64, reason string, messageIDs []int) error {
+	return s.update(id, func(delivery *model.Delivery) {
+		delivery.Status = model.DeliveryFailed
+		delivery.Error = reason
+		delivery.MessageID = 0
+		if len(messageIDs) > 0 {
+			delivery.MessageID = messageIDs[0]
+		}
+		delivery.MessageIDs = slices.Clone(messageIDs)
+	})
+}
+
+// Release drops a claim that wasn't sent, so the article can be posted to the chat later.
+func (s *DeliveryMemoryStorage) Release(_ context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.deliveries = slices.DeleteFunc(s.db.deliveries, func(d model.Delivery) bool {
+		return d.ID == id && d.Status == model.DeliveryClaimed
+	})
+	return nil
+}
+
+func (s *DeliveryMemoryStorage) update(id int64, fn func(delivery *model.Delivery)) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if delivery := s.db.delivery(id); delivery != nil {
+		fn(delivery)
+	}
+	return nil
+}
+
+type DeadLetterMemoryStorage struct {
+	db *memoryDB
+}
+
+func (s *DeadLetterMemoryStorage) Store(_ context.Context, deadLetter model.DeadLetter) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.lastDeadLetterID++
+	deadLetter.ID = s.db.lastDeadLetterID
+	deadLetter.CreatedAt = memoryNow()
+	s.db.deadLetters = append(s.db.deadLetters, deadLetter)
+	return nil
+}
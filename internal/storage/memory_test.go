@@ -0,0 +1,14 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage/storagetest"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storage.Storage {
+		return storage.NewMemory()
+	})
+}
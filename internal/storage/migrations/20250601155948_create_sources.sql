@@ -1,6 +1,6 @@
 -- +goose Up
 -- +goose StatementBegin
-CREATE TABLE sources
+CREATE TABLE IF NOT EXISTS sources
 (
     id         SERIAL PRIMARY KEY,
     name       VARCHAR(255) NOT NULL,
@@ -1,6 +1,6 @@
 -- +goose Up
 -- +goose StatementBegin
-CREATE TABLE articles
+CREATE TABLE IF NOT EXISTS articles
 (
     id           BIGSERIAL PRIMARY KEY,
     source_id    BIGINT       NOT NULL,
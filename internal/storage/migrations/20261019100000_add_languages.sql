@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS channels
+(
+    id         BIGINT PRIMARY KEY,
+    language   VARCHAR(8) NOT NULL DEFAULT '',
+    created_at TIMESTAMP  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS article_summaries
+(
+    article_id BIGINT     NOT NULL,
+    language   VARCHAR(8) NOT NULL,
+    summary    TEXT       NOT NULL,
+    created_at TIMESTAMP  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_id, language),
+    CONSTRAINT fk_article_summaries_article_id
+        FOREIGN KEY (article_id)
+            REFERENCES articles (id)
+            ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS article_summaries;
+DROP TABLE IF EXISTS channels;
+ALTER TABLE articles DROP COLUMN IF EXISTS language;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS template   TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS parse_mode VARCHAR(16) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS template,
+    DROP COLUMN IF EXISTS parse_mode;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS send_photo       BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS link_preview     VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS read_more_button BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS discuss_url      TEXT        NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS send_photo,
+    DROP COLUMN IF EXISTS link_preview,
+    DROP COLUMN IF EXISTS read_more_button,
+    DROP COLUMN IF EXISTS discuss_url;
+
+ALTER TABLE articles DROP COLUMN IF EXISTS image_url;
+-- +goose StatementEnd
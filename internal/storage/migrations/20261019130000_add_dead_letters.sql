@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS dead_letters
+(
+    id         BIGSERIAL PRIMARY KEY,
+    chat_id    BIGINT      NOT NULL,
+    method     VARCHAR(64) NOT NULL,
+    params     TEXT        NOT NULL,
+    error      TEXT        NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dead_letters;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS timezone        VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS digest          VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS digest_time     VARCHAR(5)  NOT NULL DEFAULT '09:00',
+    ADD COLUMN IF NOT EXISTS digest_weekday  SMALLINT    NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS digest_size     INT         NOT NULL DEFAULT 10,
+    ADD COLUMN IF NOT EXISTS digest_overview BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_digest_at  TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS digest,
+    DROP COLUMN IF EXISTS digest_time,
+    DROP COLUMN IF EXISTS digest_weekday,
+    DROP COLUMN IF EXISTS digest_size,
+    DROP COLUMN IF EXISTS digest_overview,
+    DROP COLUMN IF EXISTS last_digest_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles DROP COLUMN IF EXISTS claimed_at;
+
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS posting_windows TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS catch_up        VARCHAR(16) NOT NULL DEFAULT 'spread',
+    ADD COLUMN IF NOT EXISTS next_post_at    TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS posting_windows,
+    DROP COLUMN IF EXISTS catch_up,
+    DROP COLUMN IF EXISTS next_post_at;
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS postponed_until   TIMESTAMP;
+
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS moderated BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN IF EXISTS moderated;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS moderation_status,
+    DROP COLUMN IF EXISTS postponed_until;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ALTER COLUMN source_id DROP NOT NULL;
+
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS skipped      BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS scheduled_at,
+    DROP COLUMN IF EXISTS skipped;
+
+DELETE FROM articles WHERE source_id IS NULL;
+
+ALTER TABLE articles ALTER COLUMN source_id SET NOT NULL;
+-- +goose StatementEnd
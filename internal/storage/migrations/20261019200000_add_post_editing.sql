@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN IF NOT EXISTS title_edited BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS updated_at   TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS retracted_at TIMESTAMP;
+
+ALTER TABLE deliveries
+    ADD COLUMN IF NOT EXISTS kind        VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS message_ids TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS edited_at   TIMESTAMP;
+
+-- Posts sent before the kind was stored: articles of a digest share its first message.
+UPDATE deliveries SET kind = 'digest'
+WHERE kind = '' AND (
+    chat_id IN (SELECT id FROM channels WHERE digest <> '')
+    OR (chat_id, message_id) IN (SELECT chat_id, message_id FROM deliveries GROUP BY chat_id, message_id HAVING COUNT(*) > 1)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE deliveries
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS message_ids,
+    DROP COLUMN IF EXISTS edited_at;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS title_edited,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS retracted_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN IF NOT EXISTS language     VARCHAR(8) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS paused       BOOLEAN    NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS homepage_url TEXT       NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS description  TEXT       NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS icon_url     TEXT       NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS updated_at   TIMESTAMP;
+
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS tags TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN IF EXISTS tags;
+
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS paused,
+    DROP COLUMN IF EXISTS homepage_url,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS icon_url,
+    DROP COLUMN IF EXISTS updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content TEXT NOT NULL DEFAULT '';
+
+-- article_search_config returns the text search configuration stemming words of the language.
+CREATE OR REPLACE FUNCTION article_search_config(language TEXT) RETURNS regconfig
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE AS
+$$
+SELECT CASE language
+    WHEN 'en' THEN 'english'::regconfig
+    WHEN 'ru' THEN 'russian'::regconfig
+    WHEN 'de' THEN 'german'::regconfig
+    WHEN 'fr' THEN 'french'::regconfig
+    WHEN 'es' THEN 'spanish'::regconfig
+    ELSE 'simple'::regconfig
+END
+$$;
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(article_search_config(language), title), 'A') ||
+    setweight(to_tsvector(article_search_config(language), summary), 'B') ||
+    setweight(to_tsvector(article_search_config(language), content), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_articles_search ON articles USING GIN (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_articles_search;
+ALTER TABLE articles DROP COLUMN IF EXISTS search;
+DROP FUNCTION IF EXISTS article_search_config(TEXT);
+ALTER TABLE articles DROP COLUMN IF EXISTS content;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+// Package migrations embeds the SQL migrations of the database schema, see package migrate.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the PostgreSQL migrations, named <version>_<name>.sql in the goose format.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// SQLite holds the SQLite versions of the migrations in FS, with the same names.
+var SQLite, _ = fs.Sub(sqlite, "sqlite")
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite can't drop NOT NULL later, source_id is nullable from the start, see 20261019190000.
+CREATE TABLE IF NOT EXISTS articles
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    source_id    BIGINT,
+    title        VARCHAR(255) NOT NULL,
+    summary      TEXT         NOT NULL,
+    link         TEXT         NOT NULL UNIQUE,
+    published_at TIMESTAMP    NOT NULL,
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    posted_at    TIMESTAMP,
+    CONSTRAINT fk_articles_source_id
+        FOREIGN KEY (source_id)
+            REFERENCES sources (id)
+            ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS articles;
+-- +goose StatementEnd
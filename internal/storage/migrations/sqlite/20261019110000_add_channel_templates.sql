@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels ADD COLUMN template TEXT NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN parse_mode VARCHAR(16) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN template;
+ALTER TABLE channels DROP COLUMN parse_mode;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE channels ADD COLUMN send_photo BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE channels ADD COLUMN link_preview VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN read_more_button BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE channels ADD COLUMN discuss_url TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN send_photo;
+ALTER TABLE channels DROP COLUMN link_preview;
+ALTER TABLE channels DROP COLUMN read_more_button;
+ALTER TABLE channels DROP COLUMN discuss_url;
+
+ALTER TABLE articles DROP COLUMN image_url;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS dead_letters
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    chat_id    BIGINT      NOT NULL,
+    method     VARCHAR(64) NOT NULL,
+    params     TEXT        NOT NULL,
+    error      TEXT        NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dead_letters;
+-- +goose StatementEnd
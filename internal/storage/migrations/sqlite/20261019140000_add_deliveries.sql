@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN claimed_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS deliveries
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id BIGINT      NOT NULL,
+    chat_id    BIGINT      NOT NULL,
+    status     VARCHAR(16) NOT NULL,
+    message_id BIGINT,
+    error      TEXT        NOT NULL DEFAULT '',
+    claimed_at TIMESTAMP   NOT NULL,
+    sent_at    TIMESTAMP,
+    CONSTRAINT uq_deliveries_article_chat UNIQUE (article_id, chat_id),
+    CONSTRAINT fk_deliveries_article_id
+        FOREIGN KEY (article_id)
+            REFERENCES articles (id)
+            ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS deliveries;
+ALTER TABLE articles DROP COLUMN claimed_at;
+-- +goose StatementEnd
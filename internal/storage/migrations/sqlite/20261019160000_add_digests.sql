@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE channels ADD COLUMN digest VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN digest_time VARCHAR(5) NOT NULL DEFAULT '09:00';
+ALTER TABLE channels ADD COLUMN digest_weekday SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE channels ADD COLUMN digest_size INT NOT NULL DEFAULT 10;
+ALTER TABLE channels ADD COLUMN digest_overview BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE channels ADD COLUMN last_digest_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN timezone;
+ALTER TABLE channels DROP COLUMN digest;
+ALTER TABLE channels DROP COLUMN digest_time;
+ALTER TABLE channels DROP COLUMN digest_weekday;
+ALTER TABLE channels DROP COLUMN digest_size;
+ALTER TABLE channels DROP COLUMN digest_overview;
+ALTER TABLE channels DROP COLUMN last_digest_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles DROP COLUMN claimed_at;
+
+ALTER TABLE channels ADD COLUMN posting_windows TEXT NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN catch_up VARCHAR(16) NOT NULL DEFAULT 'spread';
+ALTER TABLE channels ADD COLUMN next_post_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN posting_windows;
+ALTER TABLE channels DROP COLUMN catch_up;
+ALTER TABLE channels DROP COLUMN next_post_at;
+
+ALTER TABLE articles ADD COLUMN claimed_at TIMESTAMP;
+-- +goose StatementEnd
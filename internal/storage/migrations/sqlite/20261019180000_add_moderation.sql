@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN moderation_status VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN postponed_until TIMESTAMP;
+
+ALTER TABLE channels ADD COLUMN moderated BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN moderated;
+
+ALTER TABLE articles DROP COLUMN moderation_status;
+ALTER TABLE articles DROP COLUMN postponed_until;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- source_id is already nullable, see 20250601160042.
+ALTER TABLE articles ADD COLUMN scheduled_at TIMESTAMP;
+ALTER TABLE articles ADD COLUMN skipped BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles DROP COLUMN scheduled_at;
+ALTER TABLE articles DROP COLUMN skipped;
+
+DELETE FROM articles WHERE source_id IS NULL;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN title_edited BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE articles ADD COLUMN updated_at TIMESTAMP;
+ALTER TABLE articles ADD COLUMN retracted_at TIMESTAMP;
+
+ALTER TABLE deliveries ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN message_ids TEXT NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN edited_at TIMESTAMP;
+
+-- Posts sent before the kind was stored: articles of a digest share its first message.
+UPDATE deliveries SET kind = 'digest'
+WHERE kind = '' AND (
+    chat_id IN (SELECT id FROM channels WHERE digest <> '')
+    OR (chat_id, message_id) IN (SELECT chat_id, message_id FROM deliveries GROUP BY chat_id, message_id HAVING COUNT(*) > 1)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE deliveries DROP COLUMN kind;
+ALTER TABLE deliveries DROP COLUMN message_ids;
+ALTER TABLE deliveries DROP COLUMN edited_at;
+
+ALTER TABLE articles DROP COLUMN title_edited;
+ALTER TABLE articles DROP COLUMN updated_at;
+ALTER TABLE articles DROP COLUMN retracted_at;
+-- +goose StatementEnd
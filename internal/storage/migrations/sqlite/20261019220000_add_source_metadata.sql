@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN paused BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sources ADD COLUMN homepage_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN icon_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN updated_at TIMESTAMP;
+
+ALTER TABLE channels ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN tags;
+
+ALTER TABLE sources DROP COLUMN language;
+ALTER TABLE sources DROP COLUMN paused;
+ALTER TABLE sources DROP COLUMN homepage_url;
+ALTER TABLE sources DROP COLUMN description;
+ALTER TABLE sources DROP COLUMN icon_url;
+ALTER TABLE sources DROP COLUMN updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite has no text search configurations, articles are searched with LIKE, see ArticleSQLiteStorage.Search.
+ALTER TABLE articles ADD COLUMN content TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles DROP COLUMN content;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage/storagetest"
+)
+
+// TestPostgresStorage runs on the database of TEST_POSTGRES_DSN, its tables are emptied.
+func TestPostgresStorage(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	storagetest.Run(t, func(t *testing.T) *storage.Storage {
+		database := openDatabase(t, dsn)
+
+		truncate := `TRUNCATE sources, articles, article_summaries, channels, deliveries, dead_letters RESTART IDENTITY CASCADE`
+		if _, err := database.ExecContext(context.Background(), truncate); err != nil {
+			t.Fatalf("failed to empty tables: %v", err)
+		}
+
+		return newStorage(t, database)
+	})
+}
+
+func openDatabase(t *testing.T, dsn string) *db.Database {
+	t.Helper()
+
+	database, err := db.Open(dsn)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	return database
+}
+
+func newStorage(t *testing.T, database *db.Database) *storage.Storage {
+	t.Helper()
+
+	s, err := storage.New(database)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+// headlineOptions are the ts_headline options: matches are marked as model.SearchResult expects
+// and up to two fragments of the text are shown.
+const headlineOptions = `StartSel=` + model.HighlightStart + `, StopSel=` + model.HighlightEnd +
+	`, MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=" … "`
+
+// headlineWords is the number of words of the headlines built without PostgreSQL.
+const headlineWords = 30
+
+// searchable is the condition on the articles that can be found. Articles a moderator rejected
+// or hasn't reviewed yet must not be shared, postponed ones were approved for later.
+const searchable = `NOT skipped AND retracted_at IS NULL AND moderation_status NOT IN ('pending', 'rejected')`
+
+// Search returns a page of the articles matching the query and the number of all matches. The
+// query has the syntax of web search engines: words, "quoted phrases", OR and -excluded words.
+// Words are stemmed in every language with a text search configuration, so "generics" finds
+// "generic", and the best matches come first. Skipped and retracted articles are not found, nor
+// the ones rejected or still pending in moderation.
+// An empty query finds all articles, newest first, without headlines.
+func (s *ArticlePostgresStorage) Search(ctx context.Context, query string, offset, limit uint64) ([]model.SearchResult, int, error) {
+	if strings.TrimSpace(query) == "" {
+		return s.recent(ctx, offset, limit)
+	}
+
+	// The query is stemmed in every language rather than in the language of each article,
+	// so that it is the same for all rows and the index on search is used.
+	q := `
+		WITH q AS (
+			SELECT websearch_to_tsquery('simple', $1) || websearch_to_tsquery('english', $1) ||
+				websearch_to_tsquery('russian', $1) || websearch_to_tsquery('german', $1) ||
+				websearch_to_tsquery('french', $1) || websearch_to_tsquery('spanish', $1) AS query
+		)
+		SELECT ` + articleColumns + `,
+			ts_headline(article_search_config(language), CASE WHEN summary <> '' THEN summary ELSE content END, q.query, $4),
+			ts_rank(search, q.query), COUNT(*) OVER ()
+		FROM articles, q
+		WHERE search @@ q.query AND ` + searchable + `
+		ORDER BY ts_rank(search, q.query) DESC, published_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, q, query, limit, offset, headlineOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		results []model.SearchResult
+		total   int
+	)
+	for rows.Next() {
+		var (
+			dbArticle dbArticle
+			result    model.SearchResult
+		)
+		if err := rows.Scan(append(dbArticle.dest(), &result.Headline, &result.Rank, &total)...); err != nil {
+			return nil, 0, err
+		}
+		result.Article = *modelArticleFromDB(dbArticle)
+		result.Headline = plainText(result.Headline)
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
+func (s *ArticlePostgresStorage) recent(ctx context.Context, offset, limit uint64) ([]model.SearchResult, int, error) {
+	q := `
+		SELECT ` + articleColumns + `, COUNT(*) OVER ()
+		FROM articles
+		WHERE ` + searchable + `
+		ORDER BY published_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		results []model.SearchResult
+		total   int
+	)
+	for rows.Next() {
+		var dbArticle dbArticle
+		if err := rows.Scan(append(dbArticle.dest(), &total)...); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, model.SearchResult{Article: *modelArticleFromDB(dbArticle)})
+	}
+
+	return results, total, rows.Err()
+}
+
+// Search returns a page of the articles matching the query like the PostgreSQL repository,
+// newest first. SQLite has no text search configurations, words are not stemmed.
+func (s *ArticleSQLiteStorage) Search(ctx context.Context, query string, offset, limit uint64) ([]model.SearchResult, int, error) {
+	terms := searchTerms(query)
+	if len(terms.any) == 0 && len(terms.none) == 0 {
+		return s.recent(ctx, offset, limit)
+	}
+
+	var args []any
+	contains := func(term string) string {
+		args = append(args, "%"+likeEscaper.Replace(term)+"%")
+		return fmt.Sprintf(`(title LIKE $%[1]d ESCAPE '\' OR summary LIKE $%[1]d ESCAPE '\' OR content LIKE $%[1]d ESCAPE '\')`, len(args))
+	}
+
+	var alternatives []string
+	for _, words := range terms.any {
+		var conditions []string
+		for _, word := range words {
+			conditions = append(conditions, contains(word))
+		}
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+	condition := "TRUE"
+	if len(alternatives) > 0 {
+		condition = "(" + strings.Join(alternatives, " OR ") + ")"
+	}
+	for _, word := range terms.none {
+		condition += " AND NOT " + contains(word)
+	}
+	args = append(args, limit, offset)
+
+	q := `
+		SELECT ` + articleColumns + `, CASE WHEN summary <> '' THEN summary ELSE content END, COUNT(*) OVER ()
+		FROM articles
+		WHERE ` + condition + ` AND ` + searchable + `
+		ORDER BY published_at DESC
+		LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		results []model.SearchResult
+		total   int
+	)
+	for rows.Next() {
+		var (
+			dbArticle dbArticle
+			text      string
+		)
+		if err := rows.Scan(append(dbArticle.dest(), &text, &total)...); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, model.SearchResult{
+			Article:  *modelArticleFromDB(dbArticle),
+			Headline: headline(text, terms.words()),
+		})
+	}
+
+	return results, total, rows.Err()
+}
+
+// Search returns a page of the articles matching the query like the SQLite repository, newest first.
+func (s *ArticleMemoryStorage) Search(_ context.Context, query string, offset, limit uint64) ([]model.SearchResult, int, error) {
+	terms := searchTerms(query)
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	articles := s.db.selectArticles(func(a model.Article) bool {
+		held := a.ModerationStatus == model.ModerationPending || a.ModerationStatus == model.ModerationRejected
+		return !a.Skipped && a.RetractedAt.IsZero() && !held && terms.match(a.Title+"\n"+a.Summary+"\n"+a.Content)
+	}, newestFirst, 0)
+
+	total := len(articles)
+	articles = articles[min(offset, uint64(total)):]
+	if limit > 0 && uint64(len(articles)) > limit {
+		articles = articles[:limit]
+	}
+
+	results := make([]model.SearchResult, 0, len(articles))
+	for _, article := range articles {
+		result := model.SearchResult{Article: article}
+		if strings.TrimSpace(query) != "" {
+			text := article.Summary
+			if text == "" {
+				text = article.Content
+			}
+			result.Headline = headline(text, terms.words())
+		}
+		results = append(results, result)
+	}
+	return results, total, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// terms are the lowercase words of a search query: a text matches if it contains all words of
+// any of the alternatives separated by OR, if there are any, and none of the -excluded words.
+// Phrases are searched as separate words.
+type terms struct {
+	any  [][]string
+	none []string
+}
+
+func searchTerms(query string) terms {
+	var (
+		t     terms
+		words []string
+	)
+	for _, word := range strings.Fields(query) {
+		if word == "OR" || word == "or" {
+			if len(words) > 0 {
+				t.any = append(t.any, words)
+			}
+			words = nil
+			continue
+		}
+
+		word = strings.ToLower(strings.Trim(word, `"`))
+		if excluded, ok := strings.CutPrefix(word, "-"); ok {
+			if excluded = strings.Trim(excluded, `"`); excluded != "" {
+				t.none = append(t.none, excluded)
+			}
+			continue
+		}
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	if len(words) > 0 {
+		t.any = append(t.any, words)
+	}
+	return t
+}
+
+func (t terms) match(text string) bool {
+	text = strings.ToLower(text)
+	contains := func(word string) bool { return strings.Contains(text, word) }
+
+	if slices.ContainsFunc(t.none, contains) {
+		return false
+	}
+	return len(t.any) == 0 || slices.ContainsFunc(t.any, func(words []string) bool {
+		return !slices.ContainsFunc(words, func(word string) bool { return !contains(word) })
+	})
+}
+
+// words returns the words to highlight.
+func (t terms) words() []string {
+	return slices.Concat(t.any...)
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips the HTML tags and entities of feed summaries and collapses whitespace.
+func plainText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(s, " "))), " ")
+}
+
+// headline returns the words of the text around the first match of the query words, marking
+// the words containing one of them as ts_headline does.
+func headline(text string, query []string) string {
+	words := strings.Fields(plainText(text))
+
+	matches := func(word string) bool {
+		word = strings.ToLower(word)
+		return slices.ContainsFunc(query, func(q string) bool { return strings.Contains(word, q) })
+	}
+
+	start := max(0, slices.IndexFunc(words, matches)-headlineWords/3)
+	end := min(len(words), start+headlineWords)
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("… ")
+	}
+	for i, word := range words[start:end] {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if matches(word) {
+			word = model.HighlightStart + word + model.HighlightEnd
+		}
+		sb.WriteString(word)
+	}
+	if end < len(words) {
+		sb.WriteString(" …")
+	}
+	return sb.String()
+}
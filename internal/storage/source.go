@@ -3,6 +3,9 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/amir-amirov/go-news-feed-bot/internal/model"
@@ -10,7 +13,7 @@ import (
 )
 
 type SourcePostgresStorage struct {
-	db *sql.DB
+	db querier
 }
 
 func NewSourcePostgresStorage(db *sql.DB) *SourcePostgresStorage {
@@ -21,7 +24,7 @@ func NewSourcePostgresStorage(db *sql.DB) *SourcePostgresStorage {
 
 func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, error) {
 
-	query := `SELECT id, name, feed_url, created_at FROM sources;`
+	query := `SELECT ` + sourceColumns + ` FROM sources ORDER BY id;`
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -32,40 +35,33 @@ func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, er
 
 	var sources []model.Source
 	for rows.Next() {
-		var dbSrc dbSource
-		if err := rows.Scan(&dbSrc.ID, &dbSrc.Name, &dbSrc.FeedURL, &dbSrc.CreatedAt); err != nil {
+		source, err := scanSource(rows)
+		if err != nil {
 			return nil, err
 		}
-		sources = append(sources, *modelSourceFromDB(dbSrc))
+		sources = append(sources, *source)
 	}
 
-	return sources, nil
+	return sources, rows.Err()
 }
 
 func (s *SourcePostgresStorage) SourceByID(ctx context.Context, id int64) (*model.Source, error) {
 	// query := `SELECT * FROM sources WHERE id = $1` // not recommended
 	// Since we are scanning into a dbSource struct, we'd use a more specific query
-	query := `SELECT id, name, feed_url, created_at FROM sources WHERE id = $1`
+	query := `SELECT ` + sourceColumns + ` FROM sources WHERE id = $1`
 
-	var source dbSource
-
-	row := s.db.QueryRowContext(ctx, query, id)
-	if err := row.Scan(&source.ID, &source.Name, &source.FeedURL, &source.CreatedAt); err != nil {
-		return nil, err
-	}
-
-	return modelSourceFromDB(source), nil
+	return scanSource(s.db.QueryRowContext(ctx, query, id))
 }
 
 func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (int64, error) {
 	query := `
-		INSERT INTO sources (name, feed_url)
-		VALUES ($1, $2)
+		INSERT INTO sources (name, feed_url, tags, language)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id
 	`
 
 	var id int64
-	err := s.db.QueryRowContext(ctx, query, source.Name, source.FeedURL).Scan(&id)
+	err := s.db.QueryRowContext(ctx, query, source.Name, source.FeedURL, formatTags(source.Tags), source.Language).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -73,24 +69,129 @@ func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (i
 	return id, nil
 }
 
+// Update saves the fields admins edit: the name, feed URL, priority, tags, language,
+// pause and homepage. The priority must be positive.
+func (s *SourcePostgresStorage) Update(ctx context.Context, source model.Source) error {
+	if source.Priority <= 0 {
+		return fmt.Errorf("invalid priority %g of source %d, it must be positive", source.Priority, source.ID)
+	}
+
+	query := `
+		UPDATE sources
+		SET name = $2, feed_url = $3, priority = $4, tags = $5, language = $6, paused = $7, homepage_url = $8,
+			updated_at = $9::timestamp
+		WHERE id = $1
+	`
+
+	res, err := s.db.ExecContext(ctx, query,
+		source.ID, source.Name, source.FeedURL, source.Priority, formatTags(source.Tags), source.Language, source.Paused,
+		source.HomepageURL, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+	return requireRow(res)
+}
+
+// SetFeedInfo saves the description and icon of the source's feed, and its homepage unless one
+// is set already.
+func (s *SourcePostgresStorage) SetFeedInfo(ctx context.Context, id int64, homepageURL, description, iconURL string) error {
+	query := `
+		UPDATE sources
+		SET homepage_url = CASE WHEN homepage_url = '' THEN $2 ELSE homepage_url END,
+			description = $3, icon_url = $4, updated_at = $5::timestamp
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, homepageURL, description, iconURL, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
 func (s *SourcePostgresStorage) Delete(ctx context.Context, id int64) error {
-	query := `DELETTE FROM source WHERE id = $1`
+	query := `DELETE FROM sources WHERE id = $1`
 	_, err := s.db.ExecContext(ctx, query, id)
 	return err
 }
 
+const sourceColumns = `id, name, feed_url, priority, tags, language, paused, homepage_url, description, icon_url,
+	created_at, updated_at`
+
+func scanSource(row scanner) (*model.Source, error) {
+	var dbSrc dbSource
+	if err := row.Scan(
+		&dbSrc.ID, &dbSrc.Name, &dbSrc.FeedURL, &dbSrc.Priority, &dbSrc.Tags, &dbSrc.Language, &dbSrc.Paused,
+		&dbSrc.HomepageURL, &dbSrc.Description, &dbSrc.IconURL, &dbSrc.CreatedAt, &dbSrc.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return modelSourceFromDB(dbSrc), nil
+}
+
+// requireRow returns sql.ErrNoRows if the statement changed no rows.
+func requireRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 type dbSource struct {
-	ID        int64     `db:"id"`
-	Name      string    `db:"name"`
-	FeedURL   string    `db:"feed_url"`
-	CreatedAt time.Time `db:"created_at"`
+	ID          int64        `db:"id"`
+	Name        string       `db:"name"`
+	FeedURL     string       `db:"feed_url"`
+	Priority    float64      `db:"priority"`
+	Tags        string       `db:"tags"`
+	Language    string       `db:"language"`
+	Paused      bool         `db:"paused"`
+	HomepageURL string       `db:"homepage_url"`
+	Description string       `db:"description"`
+	IconURL     string       `db:"icon_url"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   sql.NullTime `db:"updated_at"`
 }
 
 func modelSourceFromDB(dbSource dbSource) *model.Source {
 	return &model.Source{
-		ID:        dbSource.ID,
-		Name:      dbSource.Name,
-		FeedURL:   dbSource.FeedURL,
-		CreatedAt: dbSource.CreatedAt,
+		ID:          dbSource.ID,
+		Name:        dbSource.Name,
+		FeedURL:     dbSource.FeedURL,
+		Priority:    dbSource.Priority,
+		Tags:        parseTags(dbSource.Tags),
+		Language:    dbSource.Language,
+		Paused:      dbSource.Paused,
+		HomepageURL: dbSource.HomepageURL,
+		Description: dbSource.Description,
+		IconURL:     dbSource.IconURL,
+		CreatedAt:   dbSource.CreatedAt,
+		UpdatedAt:   dbSource.UpdatedAt.Time,
+	}
+}
+
+// formatTags stores tags as a comma separated list.
+func formatTags(tags []string) string {
+	return strings.Join(normalizeTags(tags), ",")
+}
+
+// normalizeTags lowercases the tags and drops empty and repeated ones. Commas can't be stored
+// and are replaced with spaces.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(tag), ",", " ")), " ")
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func parseTags(s string) []string {
+	if s == "" {
+		return nil
 	}
+	return strings.Split(s, ",")
 }
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+)
+
+// The SQLite repositories run the queries of the PostgreSQL ones through sqliteDB.
+
+type SourceSQLiteStorage struct{ SourcePostgresStorage }
+
+func NewSourceSQLiteStorage(db *sql.DB) *SourceSQLiteStorage {
+	return &SourceSQLiteStorage{SourcePostgresStorage{db: sqliteDB{db}}}
+}
+
+type ArticleSQLiteStorage struct{ ArticlePostgresStorage }
+
+func NewArticleSQLiteStorage(db *sql.DB) *ArticleSQLiteStorage {
+	return &ArticleSQLiteStorage{ArticlePostgresStorage{db: sqliteDB{db}}}
+}
+
+type ChannelSQLiteStorage struct{ ChannelPostgresStorage }
+
+func NewChannelSQLiteStorage(db *sql.DB) *ChannelSQLiteStorage {
+	return &ChannelSQLiteStorage{ChannelPostgresStorage{db: sqliteDB{db}}}
+}
+
+type DeliverySQLiteStorage struct{ DeliveryPostgresStorage }
+
+func NewDeliverySQLiteStorage(db *sql.DB) *DeliverySQLiteStorage {
+	return &DeliverySQLiteStorage{DeliveryPostgresStorage{db: sqliteDB{db}}}
+}
+
+type DeadLetterSQLiteStorage struct{ DeadLetterPostgresStorage }
+
+func NewDeadLetterSQLiteStorage(db *sql.DB) *DeadLetterSQLiteStorage {
+	return &DeadLetterSQLiteStorage{DeadLetterPostgresStorage{db: sqliteDB{db}}}
+}
+
+var (
+	timestampParam = regexp.MustCompile(`\$(\d+)::timestamp\b`)
+	param          = regexp.MustCompile(`\$(\d+)`)
+)
+
+// sqliteLayout is the format of CURRENT_TIMESTAMP and datetime(), timestamps are compared as text.
+const sqliteLayout = "2006-01-02 15:04:05"
+
+// sqliteDB adapts PostgreSQL queries to SQLite: $1 parameters become ?1, $1::timestamp casts
+// become datetime(?1) and time.Time arguments are formatted like SQLite's own timestamps.
+type sqliteDB struct {
+	db *sql.DB
+}
+
+func (d sqliteDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (d sqliteDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (d sqliteDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.db.QueryRowContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func sqliteQuery(query string) string {
+	query = timestampParam.ReplaceAllString(query, "datetime(?$1)")
+	return param.ReplaceAllString(query, "?$1")
+}
+
+func sqliteArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			arg = t.UTC().Format(sqliteLayout)
+		}
+		converted[i] = arg
+	}
+	return converted
+}
@@ -0,0 +1,16 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage/storagetest"
+)
+
+func TestSQLiteStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storage.Storage {
+		dsn := "sqlite:" + filepath.Join(t.TempDir(), "news.db")
+		return newStorage(t, openDatabase(t, dsn))
+	})
+}
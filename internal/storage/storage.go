@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/db"
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+)
+
+// querier runs the queries of the repositories. They are written for PostgreSQL, see sqliteDB.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type SourceRepository interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	Add(ctx context.Context, source model.Source) (int64, error)
+	Update(ctx context.Context, source model.Source) error
+	SetFeedInfo(ctx context.Context, id int64, homepageURL, description, iconURL string) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type ArticleRepository interface {
+	Store(ctx context.Context, article model.Article) error
+	AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
+	NotDeliveredTo(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error)
+	MarkPosted(ctx context.Context, id int64) error
+	PostedCountsBySource(ctx context.Context, since time.Time) (map[int64]int, error)
+	ArticleByID(ctx context.Context, id int64) (*model.Article, error)
+	ArticleByLink(ctx context.Context, link string) (*model.Article, error)
+	Scheduled(ctx context.Context, chatID int64, since time.Time, limit uint64) ([]model.Article, error)
+	Schedule(ctx context.Context, id int64, at time.Time) error
+	Skip(ctx context.Context, id int64) error
+	UpdateTitle(ctx context.Context, id int64, title string) error
+	PublishedSince(ctx context.Context, sourceID int64, since time.Time) ([]model.Article, error)
+	MarkRetracted(ctx context.Context, id int64) error
+	ClaimModeration(ctx context.Context, id int64, now time.Time) (bool, error)
+	SetModerationStatus(ctx context.Context, id int64, status string, postponedUntil time.Time) error
+	CountModeration(ctx context.Context, status string) (int, error)
+	Summary(ctx context.Context, articleID int64, language string) (string, error)
+	Summaries(ctx context.Context, articleIDs []int64) (map[int64]map[string]string, error)
+	StoreSummary(ctx context.Context, articleID int64, language, summary string) error
+	Search(ctx context.Context, query string, offset, limit uint64) ([]model.SearchResult, int, error)
+}
+
+type ChannelRepository interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+	Upsert(ctx context.Context, channel model.Channel) error
+	ClaimDigest(ctx context.Context, id int64, scheduledAt time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, id int64, scheduledAt, previous time.Time) error
+	ClaimSlot(ctx context.Context, id int64, now, until time.Time) (bool, error)
+	SetNextPost(ctx context.Context, id int64, at time.Time) error
+}
+
+type DeliveryRepository interface {
+	Claim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error)
+	Reclaim(ctx context.Context, articleID, chatID int64, now time.Time, lease time.Duration) (int64, bool, error)
+	MarkSent(ctx context.Context, id int64, kind string, messageIDs []int) error
+	MarkEdited(ctx context.Context, id int64, messageIDs []int) error
+	MarkDeleted(ctx context.Context, id int64) error
+	Sent(ctx context.Context, articleID int64) ([]model.Delivery, error)
+	Outdated(ctx context.Context, limit uint64) ([]model.Delivery, error)
+	Retracted(ctx context.Context, limit uint64) ([]model.Delivery, error)
+	MarkFailed(ctx context.Context, id int64, reason string, messageIDs []int) error
+	Release(ctx context.Context, id int64) error
+}
+
+type DeadLetterRepository interface {
+	Store(ctx context.Context, deadLetter model.DeadLetter) error
+}
+
+// Storage holds the repositories of a database.
+type Storage struct {
+	Sources     SourceRepository
+	Articles    ArticleRepository
+	Channels    ChannelRepository
+	Deliveries  DeliveryRepository
+	DeadLetters DeadLetterRepository
+}
+
+// New returns the repositories of the database's backend.
+func New(database *db.Database) (*Storage, error) {
+	switch database.Backend {
+	case db.Postgres:
+		return &Storage{
+			Sources:     NewSourcePostgresStorage(database.DB),
+			Articles:    NewArticlePostgresStorage(database.DB),
+			Channels:    NewChannelPostgresStorage(database.DB),
+			Deliveries:  NewDeliveryPostgresStorage(database.DB),
+			DeadLetters: NewDeadLetterPostgresStorage(database.DB),
+		}, nil
+	case db.SQLite:
+		return &Storage{
+			Sources:     NewSourceSQLiteStorage(database.DB),
+			Articles:    NewArticleSQLiteStorage(database.DB),
+			Channels:    NewChannelSQLiteStorage(database.DB),
+			Deliveries:  NewDeliverySQLiteStorage(database.DB),
+			DeadLetters: NewDeadLetterSQLiteStorage(database.DB),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database backend %q", database.Backend)
+	}
+}
@@ -0,0 +1,684 @@
+// Package storagetest checks that the repositories of a database backend behave as the
+// notifier and the fetcher expect. Every backend runs the same suite, see Run.
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/model"
+	"github.com/amir-amirov/go-news-feed-bot/internal/storage"
+)
+
+// Open returns the repositories of an empty database with all migrations applied.
+type Open func(t *testing.T) *storage.Storage
+
+// Run runs the conformance tests, each on a database returned by open.
+func Run(t *testing.T, open Open) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s *storage.Storage)
+	}{
+		{"Sources", testSources},
+		{"Articles", testArticles},
+		{"Scheduling", testScheduling},
+		{"Moderation", testModeration},
+		{"Deliveries", testDeliveries},
+		{"Leases", testLeases},
+		{"Channels", testChannels},
+		{"DeadLetters", testDeadLetters},
+		{"Search", testSearch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+// now is truncated to seconds, timestamps are stored with at least that precision.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func addSource(t *testing.T, s *storage.Storage) int64 {
+	t.Helper()
+
+	id, err := s.Sources.Add(context.Background(), model.Source{Name: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom"})
+	if err != nil {
+		t.Fatalf("Add source failed: %v", err)
+	}
+	return id
+}
+
+func storeArticle(t *testing.T, s *storage.Storage, article model.Article) *model.Article {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := s.Articles.Store(ctx, article); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	stored, err := s.Articles.ArticleByLink(ctx, article.Link)
+	if err != nil {
+		t.Fatalf("ArticleByLink failed: %v", err)
+	}
+	if stored == nil {
+		t.Fatalf("article %s not found after storing", article.Link)
+	}
+	return stored
+}
+
+func links(articles []model.Article) []string {
+	var links []string
+	for _, article := range articles {
+		links = append(links, article.Link)
+	}
+	return links
+}
+
+func testSources(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+
+	id := addSource(t, s)
+	otherID, err := s.Sources.Add(ctx, model.Source{Name: "Other", FeedURL: "https://example.com/feed", Tags: []string{"Tech", " Go ", "tech", ""}})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	sources, err := s.Sources.Sources(ctx)
+	if err != nil {
+		t.Fatalf("Sources failed: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("got %d sources, want 2", len(sources))
+	}
+
+	source, err := s.Sources.SourceByID(ctx, id)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+	if source.Name != "Go Blog" || source.FeedURL != "https://go.dev/blog/feed.atom" || source.Priority != 1 || source.CreatedAt.IsZero() {
+		t.Errorf("unexpected source %+v", source)
+	}
+
+	other, err := s.Sources.SourceByID(ctx, otherID)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+	if !slices.Equal(other.Tags, []string{"tech", "go"}) {
+		t.Errorf("got tags %q, want normalized [tech go]", other.Tags)
+	}
+
+	other.Name = "Other, renamed"
+	other.Tags = []string{"Go", "news"}
+	other.Language = "de"
+	other.Paused = true
+	other.Priority = 0
+	if err := s.Sources.Update(ctx, *other); err == nil {
+		t.Error("Update succeeded with a zero priority")
+	}
+	other.Priority = 2
+	if err := s.Sources.Update(ctx, *other); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := s.Sources.SetFeedInfo(ctx, otherID, "https://example.com", "All about Go", "https://example.com/icon.png"); err != nil {
+		t.Fatalf("SetFeedInfo failed: %v", err)
+	}
+	updated, err := s.Sources.SourceByID(ctx, otherID)
+	if err != nil {
+		t.Fatalf("SourceByID failed: %v", err)
+	}
+	if updated.Name != "Other, renamed" || !slices.Equal(updated.Tags, []string{"go", "news"}) || updated.Language != "de" ||
+		!updated.Paused || updated.Priority != 2 || updated.UpdatedAt.IsZero() {
+		t.Errorf("source not updated: %+v", updated)
+	}
+	if updated.HomepageURL != "https://example.com" || updated.Description != "All about Go" || updated.IconURL != "https://example.com/icon.png" {
+		t.Errorf("feed info not saved: %+v", updated)
+	}
+
+	// The feed doesn't override a homepage set by an admin.
+	updated.HomepageURL = "https://example.com/go"
+	if err := s.Sources.Update(ctx, *updated); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := s.Sources.SetFeedInfo(ctx, otherID, "https://example.com", "", ""); err != nil {
+		t.Fatalf("SetFeedInfo failed: %v", err)
+	}
+	if updated, _ := s.Sources.SourceByID(ctx, otherID); updated == nil || updated.HomepageURL != "https://example.com/go" {
+		t.Errorf("homepage overridden by the feed: %+v", updated)
+	}
+
+	if err := s.Sources.Update(ctx, model.Source{ID: otherID + 100, Name: "Unknown", Priority: 1}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Update of an unknown source returned %v, want sql.ErrNoRows", err)
+	}
+
+	if err := s.Sources.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Sources.SourceByID(ctx, id); err == nil {
+		t.Error("deleted source is still returned")
+	}
+}
+
+func testArticles(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	sourceID := addSource(t, s)
+	now := now()
+
+	newest := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Newest", Link: "https://go.dev/1", Language: "en", PublishedAt: now.Add(-time.Hour)})
+	storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Older", Link: "https://go.dev/2", PublishedAt: now.Add(-2 * time.Hour)})
+	storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Old", Link: "https://go.dev/3", PublishedAt: now.Add(-48 * time.Hour)})
+	manual := storeArticle(t, s, model.Article{Title: "Manual", Link: "https://example.com/post", PublishedAt: now.Add(-3 * time.Hour)})
+
+	if newest.SourceID != sourceID || newest.Title != "Newest" || newest.Language != "en" || !newest.PublishedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("unexpected article %+v", newest)
+	}
+	if manual.SourceID != 0 {
+		t.Errorf("got source %d of an article without source", manual.SourceID)
+	}
+
+	if article, err := s.Articles.ArticleByID(ctx, newest.ID); err != nil || article == nil || article.Link != newest.Link {
+		t.Errorf("ArticleByID returned %+v, %v", article, err)
+	}
+	if article, err := s.Articles.ArticleByLink(ctx, "https://go.dev/unknown"); err != nil || article != nil {
+		t.Errorf("ArticleByLink of an unknown link returned %+v, %v", article, err)
+	}
+
+	// Storing a known link updates the title unless an admin edited it.
+	updated := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Newest, updated", Link: "https://go.dev/1", PublishedAt: now.Add(-time.Hour)})
+	if updated.ID != newest.ID || updated.Title != "Newest, updated" || updated.UpdatedAt.IsZero() {
+		t.Errorf("title not updated: %+v", updated)
+	}
+	if err := s.Articles.UpdateTitle(ctx, newest.ID, "Edited"); err != nil {
+		t.Fatalf("UpdateTitle failed: %v", err)
+	}
+	edited := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Newest, again", Link: "https://go.dev/1", PublishedAt: now.Add(-time.Hour)})
+	if edited.Title != "Edited" || !edited.TitleEdited {
+		t.Errorf("edited title overwritten: %+v", edited)
+	}
+
+	since := now.Add(-24 * time.Hour)
+	queued, err := s.Articles.NotDeliveredTo(ctx, 1, since, 10)
+	if err != nil {
+		t.Fatalf("NotDeliveredTo failed: %v", err)
+	}
+	if want := []string{"https://go.dev/1", "https://go.dev/2", "https://example.com/post"}; !slices.Equal(links(queued), want) {
+		t.Errorf("NotDeliveredTo returned %v, want %v", links(queued), want)
+	}
+
+	if err := s.Articles.Skip(ctx, manual.ID); err != nil {
+		t.Fatalf("Skip failed: %v", err)
+	}
+	if err := s.Articles.MarkRetracted(ctx, newest.ID); err != nil {
+		t.Fatalf("MarkRetracted failed: %v", err)
+	}
+	queued, err = s.Articles.NotDeliveredTo(ctx, 1, since, 10)
+	if err != nil {
+		t.Fatalf("NotDeliveredTo failed: %v", err)
+	}
+	if want := []string{"https://go.dev/2"}; !slices.Equal(links(queued), want) {
+		t.Errorf("NotDeliveredTo returned %v after skipping and retracting, want %v", links(queued), want)
+	}
+
+	published, err := s.Articles.PublishedSince(ctx, sourceID, since)
+	if err != nil {
+		t.Fatalf("PublishedSince failed: %v", err)
+	}
+	if want := []string{"https://go.dev/2"}; !slices.Equal(links(published), want) {
+		t.Errorf("PublishedSince returned %v, want %v", links(published), want)
+	}
+
+	notPosted, err := s.Articles.AllNotPosted(ctx, since, 10)
+	if err != nil {
+		t.Fatalf("AllNotPosted failed: %v", err)
+	}
+	if len(notPosted) != 1 {
+		t.Fatalf("AllNotPosted returned %v", links(notPosted))
+	}
+	if err := s.Articles.MarkPosted(ctx, notPosted[0].ID); err != nil {
+		t.Fatalf("MarkPosted failed: %v", err)
+	}
+	counts, err := s.Articles.PostedCountsBySource(ctx, since)
+	if err != nil {
+		t.Fatalf("PostedCountsBySource failed: %v", err)
+	}
+	if len(counts) != 1 || counts[sourceID] != 1 {
+		t.Errorf("PostedCountsBySource returned %v", counts)
+	}
+
+	summary, err := s.Articles.Summary(ctx, newest.ID, "ru")
+	if err != nil || summary != "" {
+		t.Errorf("Summary before storing returned %q, %v", summary, err)
+	}
+	for _, text := range []string{"first", "second"} {
+		if err := s.Articles.StoreSummary(ctx, newest.ID, "ru", text); err != nil {
+			t.Fatalf("StoreSummary failed: %v", err)
+		}
+	}
+	if summary, err := s.Articles.Summary(ctx, newest.ID, "ru"); err != nil || summary != "second" {
+		t.Errorf("Summary returned %q, %v", summary, err)
+	}
+	if err := s.Articles.StoreSummary(ctx, newest.ID, "en", "english"); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+	summaries, err := s.Articles.Summaries(ctx, []int64{newest.ID, manual.ID})
+	if err != nil {
+		t.Fatalf("Summaries failed: %v", err)
+	}
+	if want := map[int64]map[string]string{newest.ID: {"ru": "second", "en": "english"}}; !reflect.DeepEqual(summaries, want) {
+		t.Errorf("Summaries returned %v, want %v", summaries, want)
+	}
+}
+
+func testScheduling(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	now := now()
+
+	article := storeArticle(t, s, model.Article{SourceID: addSource(t, s), Title: "Scheduled", Link: "https://go.dev/s", PublishedAt: now})
+
+	at := now.Add(time.Hour)
+	if err := s.Articles.Schedule(ctx, article.ID, at); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	queued, err := s.Articles.NotDeliveredTo(ctx, 1, now.Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("NotDeliveredTo failed: %v", err)
+	}
+	if len(queued) != 0 {
+		t.Errorf("scheduled article is queued: %v", links(queued))
+	}
+
+	scheduled, err := s.Articles.Scheduled(ctx, 1, now, 10)
+	if err != nil {
+		t.Fatalf("Scheduled failed: %v", err)
+	}
+	if len(scheduled) != 1 || !scheduled[0].ScheduledAt.Equal(at) {
+		t.Fatalf("Scheduled returned %+v", scheduled)
+	}
+
+	if err := s.Articles.Skip(ctx, article.ID); err != nil {
+		t.Fatalf("Skip failed: %v", err)
+	}
+	if scheduled, err := s.Articles.Scheduled(ctx, 1, now, 10); err != nil || len(scheduled) != 0 {
+		t.Errorf("Scheduled returned %v, %v after skipping", links(scheduled), err)
+	}
+}
+
+func testModeration(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	now := now()
+
+	article := storeArticle(t, s, model.Article{SourceID: addSource(t, s), Title: "Moderated", Link: "https://go.dev/m", PublishedAt: now})
+
+	if ok, err := s.Articles.ClaimModeration(ctx, article.ID, now); err != nil || !ok {
+		t.Fatalf("ClaimModeration returned %v, %v", ok, err)
+	}
+	if ok, err := s.Articles.ClaimModeration(ctx, article.ID, now); err != nil || ok {
+		t.Errorf("second ClaimModeration returned %v, %v", ok, err)
+	}
+	if count, err := s.Articles.CountModeration(ctx, model.ModerationPending); err != nil || count != 1 {
+		t.Errorf("CountModeration returned %d, %v", count, err)
+	}
+
+	// Posting to channels that aren't moderated doesn't take the article out of the moderation chat.
+	if err := s.Articles.MarkPosted(ctx, article.ID); err != nil {
+		t.Fatalf("MarkPosted failed: %v", err)
+	}
+	if count, err := s.Articles.CountModeration(ctx, model.ModerationPending); err != nil || count != 1 {
+		t.Errorf("CountModeration of a posted article returned %d, %v", count, err)
+	}
+
+	until := now.Add(time.Hour)
+	if err := s.Articles.SetModerationStatus(ctx, article.ID, model.ModerationPostponed, until); err != nil {
+		t.Fatalf("SetModerationStatus failed: %v", err)
+	}
+	postponed, err := s.Articles.ArticleByID(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("ArticleByID failed: %v", err)
+	}
+	if postponed.ModerationStatus != model.ModerationPostponed || !postponed.PostponedUntil.Equal(until) {
+		t.Errorf("unexpected moderation of %+v", postponed)
+	}
+
+	if ok, err := s.Articles.ClaimModeration(ctx, article.ID, now); err != nil || ok {
+		t.Errorf("ClaimModeration before the article is due returned %v, %v", ok, err)
+	}
+	if ok, err := s.Articles.ClaimModeration(ctx, article.ID, until); err != nil || !ok {
+		t.Errorf("ClaimModeration of a due article returned %v, %v", ok, err)
+	}
+}
+
+func testDeliveries(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	now := now()
+	const chatID = -100123
+
+	article := storeArticle(t, s, model.Article{SourceID: addSource(t, s), Title: "Delivered", Link: "https://go.dev/d", PublishedAt: now})
+
+	id, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Claim returned %v, %v", ok, err)
+	}
+	if _, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now, time.Minute); err != nil || ok {
+		t.Errorf("Claim of a claimed article returned %v, %v", ok, err)
+	}
+	if queued, err := s.Articles.NotDeliveredTo(ctx, chatID, now.Add(-time.Hour), 10); err != nil || len(queued) != 0 {
+		t.Errorf("NotDeliveredTo returned claimed article: %v, %v", links(queued), err)
+	}
+
+	if err := s.Deliveries.Release(ctx, id); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if id, ok, err = s.Deliveries.Claim(ctx, article.ID, chatID, now, time.Minute); err != nil || !ok {
+		t.Fatalf("Claim after Release returned %v, %v", ok, err)
+	}
+
+	if err := s.Deliveries.MarkSent(ctx, id, model.PostText, []int{10, 11}); err != nil {
+		t.Fatalf("MarkSent failed: %v", err)
+	}
+	sent, err := s.Deliveries.Sent(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("Sent failed: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("got %d sent posts, want 1", len(sent))
+	}
+	if post := sent[0]; post.ChatID != chatID || post.Kind != model.PostText || post.MessageID != 10 ||
+		!slices.Equal(post.MessageIDs, []int{10, 11}) || post.SentAt.IsZero() {
+		t.Errorf("unexpected post %+v", post)
+	}
+	if err := s.Deliveries.Release(ctx, id); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now.Add(time.Hour), 0); err != nil || ok {
+		t.Errorf("Claim of a sent article returned %v, %v", ok, err)
+	}
+
+	if err := s.Articles.MarkRetracted(ctx, article.ID); err != nil {
+		t.Fatalf("MarkRetracted failed: %v", err)
+	}
+	retracted, err := s.Deliveries.Retracted(ctx, 10)
+	if err != nil {
+		t.Fatalf("Retracted failed: %v", err)
+	}
+	if len(retracted) != 1 || retracted[0].ID != id {
+		t.Fatalf("Retracted returned %+v", retracted)
+	}
+
+	if err := s.Deliveries.MarkDeleted(ctx, id); err != nil {
+		t.Fatalf("MarkDeleted failed: %v", err)
+	}
+	if sent, err := s.Deliveries.Sent(ctx, article.ID); err != nil || len(sent) != 0 {
+		t.Errorf("Sent returned %+v, %v after deleting", sent, err)
+	}
+	if retracted, err := s.Deliveries.Retracted(ctx, 10); err != nil || len(retracted) != 0 {
+		t.Errorf("Retracted returned %+v, %v after deleting", retracted, err)
+	}
+}
+
+// claimOnce runs claim concurrently like several instances would and returns how many claims succeeded.
+func claimOnce(t *testing.T, claim func() (bool, error)) int {
+	t.Helper()
+
+	var (
+		wg      sync.WaitGroup
+		claimed atomic.Int32
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ok, err := claim()
+			if err != nil {
+				t.Errorf("claim failed: %v", err)
+			}
+			if ok {
+				claimed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return int(claimed.Load())
+}
+
+func testLeases(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	now := now()
+	const chatID = -100123
+
+	sourceID := addSource(t, s)
+	article := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Leased", Link: "https://go.dev/lease", PublishedAt: now})
+	contended := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Contended", Link: "https://go.dev/contended", PublishedAt: now})
+
+	// The claim of an instance that crashed two minutes ago is taken over once its lease expires.
+	id, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now.Add(-2*time.Minute), time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Claim returned %v, %v", ok, err)
+	}
+	if _, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now.Add(-90*time.Second), time.Minute); err != nil || ok {
+		t.Errorf("Claim before the lease expired returned %v, %v", ok, err)
+	}
+	takenOver, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now, time.Minute)
+	if err != nil || !ok || takenOver != id {
+		t.Fatalf("Claim after the lease expired returned %d, %v, %v, want delivery %d", takenOver, ok, err, id)
+	}
+	if _, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now.Add(30*time.Second), time.Minute); err != nil || ok {
+		t.Errorf("Claim of a taken over delivery returned %v, %v", ok, err)
+	}
+
+	// A released claim is free at once, whatever the lease.
+	if err := s.Deliveries.Release(ctx, id); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	released, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now, time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("Claim after Release returned %v, %v", ok, err)
+	}
+
+	// Failed deliveries are only claimed again by Reclaim, for posts an admin asks for.
+	if err := s.Deliveries.MarkFailed(ctx, released, "Bad Request", nil); err != nil {
+		t.Fatalf("MarkFailed failed: %v", err)
+	}
+	if _, ok, err := s.Deliveries.Claim(ctx, article.ID, chatID, now.Add(time.Hour), time.Minute); err != nil || ok {
+		t.Errorf("Claim of a failed delivery returned %v, %v", ok, err)
+	}
+	reclaimed, ok, err := s.Deliveries.Reclaim(ctx, article.ID, chatID, now, time.Hour)
+	if err != nil || !ok || reclaimed != released {
+		t.Fatalf("Reclaim of a failed delivery returned %d, %v, %v, want delivery %d", reclaimed, ok, err, released)
+	}
+	if _, ok, err := s.Deliveries.Reclaim(ctx, article.ID, chatID, now, time.Hour); err != nil || ok {
+		t.Errorf("Reclaim of a claimed delivery returned %v, %v", ok, err)
+	}
+	if err := s.Deliveries.MarkSent(ctx, reclaimed, model.PostText, []int{1}); err != nil {
+		t.Fatalf("MarkSent failed: %v", err)
+	}
+	if _, ok, err := s.Deliveries.Reclaim(ctx, article.ID, chatID, now.Add(time.Hour), time.Minute); err != nil || ok {
+		t.Errorf("Reclaim of a sent delivery returned %v, %v", ok, err)
+	}
+
+	// Only one of the instances claiming a delivery at the same time gets it.
+	claimed := claimOnce(t, func() (bool, error) {
+		_, ok, err := s.Deliveries.Claim(ctx, contended.ID, chatID, now, time.Minute)
+		return ok, err
+	})
+	if claimed != 1 {
+		t.Errorf("%d concurrent delivery claims succeeded, want 1", claimed)
+	}
+
+	if err := s.Channels.Upsert(ctx, model.Channel{ID: chatID}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if claimed := claimOnce(t, func() (bool, error) {
+		return s.Channels.ClaimSlot(ctx, chatID, now, now.Add(time.Minute))
+	}); claimed != 1 {
+		t.Errorf("%d concurrent slot claims succeeded, want 1", claimed)
+	}
+	if ok, err := s.Channels.ClaimSlot(ctx, chatID, now.Add(30*time.Second), now.Add(time.Hour)); err != nil || ok {
+		t.Errorf("ClaimSlot before the lease expired returned %v, %v", ok, err)
+	}
+	if ok, err := s.Channels.ClaimSlot(ctx, chatID, now.Add(time.Minute), now.Add(time.Hour)); err != nil || !ok {
+		t.Errorf("ClaimSlot after the lease expired returned %v, %v", ok, err)
+	}
+	// Setting the next post releases the slot.
+	if err := s.Channels.SetNextPost(ctx, chatID, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("SetNextPost failed: %v", err)
+	}
+	if ok, err := s.Channels.ClaimSlot(ctx, chatID, now.Add(2*time.Minute), now.Add(time.Hour)); err != nil || !ok {
+		t.Errorf("ClaimSlot of a released slot returned %v, %v", ok, err)
+	}
+
+	if claimed := claimOnce(t, func() (bool, error) {
+		return s.Articles.ClaimModeration(ctx, contended.ID, now)
+	}); claimed != 1 {
+		t.Errorf("%d concurrent moderation claims succeeded, want 1", claimed)
+	}
+}
+
+func testChannels(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	now := now()
+	const id = -100123
+
+	if err := s.Channels.Upsert(ctx, model.Channel{ID: id, Language: "en"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if err := s.Channels.Upsert(ctx, model.Channel{ID: id, Language: "ru", Moderated: true, Tags: "go,-jobs"}); err != nil {
+		t.Fatalf("Upsert of an existing channel failed: %v", err)
+	}
+
+	channels, err := s.Channels.Channels(ctx)
+	if err != nil {
+		t.Fatalf("Channels failed: %v", err)
+	}
+	if len(channels) != 1 {
+		t.Fatalf("got %d channels, want 1", len(channels))
+	}
+	if ch := channels[0]; ch.ID != id || ch.Language != "ru" || !ch.Moderated || ch.Tags != "go,-jobs" || ch.Timezone != "UTC" ||
+		ch.CatchUp != model.CatchUpSpread || ch.DigestTime != "09:00" || ch.DigestSize != 10 || ch.CreatedAt.IsZero() {
+		t.Errorf("unexpected channel %+v", ch)
+	}
+
+	if ok, err := s.Channels.ClaimSlot(ctx, id, now, now.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("ClaimSlot returned %v, %v", ok, err)
+	}
+	if ok, err := s.Channels.ClaimSlot(ctx, id, now, now.Add(time.Hour)); err != nil || ok {
+		t.Errorf("ClaimSlot before the next post returned %v, %v", ok, err)
+	}
+	if err := s.Channels.SetNextPost(ctx, id, now); err != nil {
+		t.Fatalf("SetNextPost failed: %v", err)
+	}
+	if ok, err := s.Channels.ClaimSlot(ctx, id, now, now.Add(time.Hour)); err != nil || !ok {
+		t.Errorf("ClaimSlot of a due post returned %v, %v", ok, err)
+	}
+
+	if ok, err := s.Channels.ClaimDigest(ctx, id, now); err != nil || !ok {
+		t.Fatalf("ClaimDigest returned %v, %v", ok, err)
+	}
+	if ok, err := s.Channels.ClaimDigest(ctx, id, now); err != nil || ok {
+		t.Errorf("ClaimDigest of a sent digest returned %v, %v", ok, err)
+	}
+	if ok, err := s.Channels.ClaimDigest(ctx, id, now.Add(24*time.Hour)); err != nil || !ok {
+		t.Errorf("ClaimDigest of the next digest returned %v, %v", ok, err)
+	}
+
+	// A released digest can be claimed again, the previous one stays sent.
+	if err := s.Channels.ReleaseDigest(ctx, id, now.Add(24*time.Hour), now); err != nil {
+		t.Fatalf("ReleaseDigest failed: %v", err)
+	}
+	if ok, err := s.Channels.ClaimDigest(ctx, id, now); err != nil || ok {
+		t.Errorf("ClaimDigest of a sent digest after a release returned %v, %v", ok, err)
+	}
+	if ok, err := s.Channels.ClaimDigest(ctx, id, now.Add(24*time.Hour)); err != nil || !ok {
+		t.Errorf("ClaimDigest of a released digest returned %v, %v", ok, err)
+	}
+}
+
+func testDeadLetters(t *testing.T, s *storage.Storage) {
+	deadLetter := model.DeadLetter{ChatID: -100123, Method: "sendMessage", Params: `{"text":"hello"}`, Error: "Bad Request"}
+	if err := s.DeadLetters.Store(context.Background(), deadLetter); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+}
+
+func testSearch(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	sourceID := addSource(t, s)
+	now := now()
+
+	aliases := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Generic type aliases", Link: "https://go.dev/aliases", Language: "en",
+		Summary: "<p>Type aliases may now have <b>type parameters</b>, a gopher favourite.</p>", PublishedAt: now.Add(-time.Hour)})
+	storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Faster maps", Link: "https://go.dev/maps", Language: "en",
+		Content: "Swiss tables make every gopher's maps faster.", PublishedAt: now.Add(-2 * time.Hour)})
+	storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Gopher conference", Link: "https://go.dev/conference", Language: "en",
+		Summary: "Talks about maps and aliases.", PublishedAt: now.Add(-3 * time.Hour)})
+	skipped := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Skipped gopher news", Link: "https://go.dev/skipped", PublishedAt: now})
+	if err := s.Articles.Skip(ctx, skipped.ID); err != nil {
+		t.Fatalf("Skip failed: %v", err)
+	}
+	for i, status := range []string{model.ModerationPending, model.ModerationRejected} {
+		held := storeArticle(t, s, model.Article{SourceID: sourceID, Title: "Gopher news " + status, Link: "https://go.dev/" + status,
+			Language: "en", Summary: "Type parameters for gophers.", PublishedAt: now.Add(-time.Duration(i) * time.Minute)})
+		if err := s.Articles.SetModerationStatus(ctx, held.ID, status, time.Time{}); err != nil {
+			t.Fatalf("SetModerationStatus failed: %v", err)
+		}
+	}
+
+	results, total, err := s.Articles.Search(ctx, "parameters", 0, 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].Article.ID != aliases.ID {
+		t.Fatalf("got %d results of %d, want the aliases article: %+v", len(results), total, results)
+	}
+	if headline := results[0].Headline; !strings.Contains(headline, model.HighlightStart+"parameters") || strings.Contains(headline, "<b>") {
+		t.Errorf("got headline %q, want plain text with the match highlighted", headline)
+	}
+
+	// The content is searched too, skipped articles and the ones held in moderation are not.
+	var found []string
+	for offset := uint64(0); offset < 4; offset += 2 {
+		results, total, err := s.Articles.Search(ctx, "swiss OR gopher", offset, 2)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if total != 3 {
+			t.Errorf("got %d matches, want 3", total)
+		}
+		for _, result := range results {
+			found = append(found, result.Article.Link)
+		}
+	}
+	slices.Sort(found)
+	if want := []string{"https://go.dev/aliases", "https://go.dev/conference", "https://go.dev/maps"}; !slices.Equal(found, want) {
+		t.Errorf("got pages of %q, want %q", found, want)
+	}
+
+	if results, _, err := s.Articles.Search(ctx, "aliases -conference", 0, 10); err != nil || len(results) != 1 || results[0].Article.ID != aliases.ID {
+		t.Errorf("Search with an excluded word returned %+v, %v", results, err)
+	}
+	if results, total, err := s.Articles.Search(ctx, "rust", 0, 10); err != nil || len(results) != 0 || total != 0 {
+		t.Errorf("Search without matches returned %+v, %d, %v", results, total, err)
+	}
+
+	// An empty query lists the newest articles.
+	results, total, err = s.Articles.Search(ctx, " ", 0, 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 3 || len(results) != 2 || results[0].Article.ID != aliases.ID || results[0].Headline != "" {
+		t.Errorf("Search without a query returned %+v, %d", results, total)
+	}
+}
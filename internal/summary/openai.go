@@ -3,28 +3,64 @@ package summary
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
+	"github.com/amir-amirov/go-news-feed-bot/internal/lang"
+	"github.com/amir-amirov/go-news-feed-bot/internal/sentence"
 	"github.com/sashabaranov/go-openai"
 )
 
+// DefaultPrompt is used when no prompt template is configured.
+const DefaultPrompt = `You are a news editor of a Telegram channel about software development.
+Summarize the article "{{.Title}}"{{with .Source}} published by {{.}}{{end}} in {{.LanguageName}}.
+Use at most {{.MaxSentences}} sentences, do not use markdown and do not add links.`
+
+// DigestPrompt is used to write the overview of a digest.
+const DigestPrompt = `You are a news editor of a Telegram channel about software development.
+The user sends the titles and summaries of the articles included in "{{.Title}}".
+Write an overview of the main topics in {{.LanguageName}} in at most {{.MaxSentences}} sentences,
+do not list every article, do not use markdown and do not add links.`
+
+// PromptData holds the variables available in prompt templates.
+type PromptData struct {
+	Title        string
+	Source       string
+	Language     string // ISO 639-1 code of the language the summary must be written in
+	MaxSentences int
+}
+
+// LanguageName returns the English name of the target language.
+func (d PromptData) LanguageName() string {
+	return lang.Name(d.Language)
+}
+
 type OpenAISummarizer struct {
-	client  *openai.Client
-	prompt  string
-	model   string
-	enabled bool
-	mu      sync.Mutex
+	client       *openai.Client
+	prompt       *template.Template
+	digestPrompt *template.Template
+	model        string
+	enabled      bool
+	mu           sync.Mutex
 }
 
-func NewOpenAISummarizer(apiKey, model, prompt string) *OpenAISummarizer {
+// NewOpenAISummarizer creates a summarizer, prompt is a text/template executed with PromptData.
+func NewOpenAISummarizer(apiKey, model, prompt string) (*OpenAISummarizer, error) {
+	tmpl, err := parsePrompt(prompt)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &OpenAISummarizer{
-		client:  openai.NewClient(apiKey),
-		prompt:  prompt,
-		model:   model,
-		enabled: true,
+		client:       openai.NewClient(apiKey),
+		prompt:       tmpl,
+		digestPrompt: template.Must(template.New("digest").Parse(DigestPrompt)),
+		model:        model,
+		enabled:      true,
 	}
 
 	log.Printf("openai summarizer is enabled: %v", apiKey != "")
@@ -33,10 +69,36 @@ func NewOpenAISummarizer(apiKey, model, prompt string) *OpenAISummarizer {
 		s.enabled = true
 	}
 
-	return s
+	return s, nil
 }
 
-func (s *OpenAISummarizer) Summarizer(text string) (string, error) {
+// Reconfigure changes the model and prompt template, articles being summarized keep the old ones.
+func (s *OpenAISummarizer) Reconfigure(model, prompt string) error {
+	tmpl, err := parsePrompt(prompt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.model, s.prompt = model, tmpl
+	return nil
+}
+
+func parsePrompt(prompt string) (*template.Template, error) {
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+
+	tmpl, err := template.New("prompt").Parse(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func (s *OpenAISummarizer) Summarizer(text string, data PromptData) (string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -45,12 +107,34 @@ func (s *OpenAISummarizer) Summarizer(text string) (string, error) {
 		return SmartTrim(text, 100), nil
 	}
 
+	return s.complete(s.prompt, text, data)
+}
+
+// Overview writes an overview of a digest, text holds the titles and summaries of its articles.
+// An empty overview is returned if the summarizer is disabled.
+func (s *OpenAISummarizer) Overview(text string, data PromptData) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.enabled {
+		return "", nil
+	}
+
+	return s.complete(s.digestPrompt, text, data)
+}
+
+func (s *OpenAISummarizer) complete(tmpl *template.Template, text string, data PromptData) (string, error) {
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to execute prompt template: %w", err)
+	}
+
 	request := openai.ChatCompletionRequest{
 		Model: s.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
-				Content: s.prompt,
+				Content: prompt.String(),
 			},
 			{
 				Role:    openai.ChatMessageRoleUser,
@@ -75,33 +159,12 @@ func (s *OpenAISummarizer) Summarizer(text string) (string, error) {
 	}
 
 	rawSummary := strings.TrimSpace(resp.Choices[0].Message.Content)
-	if strings.HasSuffix(rawSummary, ".") {
-		return rawSummary, nil
-	}
-
-	// cut all after the last ".":
-	sentences := strings.Split(rawSummary, ".")
 
-	return strings.Join(sentences[:len(sentences)-1], ".") + ".", nil
+	// The response may be cut off by MaxTokens in the middle of a sentence.
+	return sentence.TrimIncomplete(rawSummary), nil
 }
 
+// SmartTrim returns as many leading sentences of text as fit into maxWords words.
 func SmartTrim(text string, maxWords int) string {
-	sentences := strings.FieldsFunc(text, func(r rune) bool {
-		return r == '.' || r == '!' || r == '?'
-	})
-
-	var result []string
-	wordCount := 0
-
-	for _, sentence := range sentences {
-		trimmed := strings.TrimSpace(sentence)
-		words := strings.Fields(trimmed)
-		if wordCount+len(words) > maxWords {
-			break
-		}
-		result = append(result, trimmed)
-		wordCount += len(words)
-	}
-
-	return strings.Join(result, ". ") + "."
+	return sentence.TruncateWords(text, maxWords)
 }
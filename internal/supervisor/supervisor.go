@@ -0,0 +1,146 @@
+// Package supervisor runs the long-lived workers of the bot, restarting them when they crash
+// and waiting for them to finish their current work on shutdown.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backoff between restarts of a crashed worker: it doubles on every crash up to the maximum,
+// and starts over once the worker has run for longer than the maximum.
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// Worker runs until the context is done. Returning earlier, with or without an error, or
+// panicking is a crash.
+type Worker func(ctx context.Context) error
+
+type worker struct {
+	name string
+	run  Worker
+}
+
+type Supervisor struct {
+	workers         []worker
+	shutdownTimeout time.Duration
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+}
+
+// New returns a supervisor waiting at most shutdownTimeout for the workers to stop.
+func New(shutdownTimeout time.Duration) *Supervisor {
+	return &Supervisor{
+		shutdownTimeout: shutdownTimeout,
+		minBackoff:      minBackoff,
+		maxBackoff:      maxBackoff,
+	}
+}
+
+// WithBackoff sets the minimum and maximum delay between restarts of a crashed worker.
+func (s *Supervisor) WithBackoff(minDelay, maxDelay time.Duration) *Supervisor {
+	s.minBackoff, s.maxBackoff = minDelay, maxDelay
+	return s
+}
+
+// Add adds a worker to run. Workers must be added before Run.
+func (s *Supervisor) Add(name string, run Worker) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// Run runs the workers until the context is done and then waits for them to return. It
+// returns an error if some of them haven't returned within the shutdown timeout.
+func (s *Supervisor) Run(ctx context.Context) error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running = make(map[string]bool, len(s.workers))
+	)
+
+	for _, w := range s.workers {
+		running[w.name] = true
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.supervise(ctx, w)
+
+			mu.Lock()
+			delete(running, w.name)
+			mu.Unlock()
+		}()
+	}
+
+	<-ctx.Done()
+	log.Printf("Shutting down, waiting %v for workers to stop", s.shutdownTimeout)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("All workers stopped")
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		mu.Lock()
+		defer mu.Unlock()
+
+		names := make([]string, 0, len(running))
+		for _, w := range s.workers {
+			if running[w.name] {
+				names = append(names, w.name)
+			}
+		}
+		return fmt.Errorf("workers %s didn't stop within %v", strings.Join(names, ", "), s.shutdownTimeout)
+	}
+}
+
+// supervise runs the worker, restarting it with backoff until the context is done.
+func (s *Supervisor) supervise(ctx context.Context, w worker) {
+	backoff := s.minBackoff
+	for {
+		started := time.Now()
+		err := runWorker(ctx, w.run)
+		if ctx.Err() != nil {
+			log.Printf("Worker %s stopped", w.name)
+			return
+		}
+
+		if time.Since(started) > s.maxBackoff {
+			backoff = s.minBackoff
+		}
+		if err == nil {
+			err = fmt.Errorf("returned before shutdown")
+		}
+		log.Printf("[ERROR] worker %s crashed: %v, restarting in %v", w.name, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			log.Printf("Worker %s stopped", w.name)
+			return
+		}
+		backoff = min(2*backoff, s.maxBackoff)
+	}
+}
+
+// runWorker runs the worker, turning a panic into an error.
+func runWorker(ctx context.Context, run Worker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return run(ctx)
+}
@@ -0,0 +1,97 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-amirov/go-news-feed-bot/internal/supervisor"
+)
+
+func TestSupervisor_RestartsCrashedWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing, panicking atomic.Int32
+	s := supervisor.New(time.Second).WithBackoff(time.Millisecond, 10*time.Millisecond)
+	s.Add("failing", func(ctx context.Context) error {
+		if failing.Add(1) < 3 {
+			return errors.New("boom")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.Add("panicking", func(ctx context.Context) error {
+		if panicking.Add(1) < 3 {
+			panic("boom")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for failing.Load() < 3 || panicking.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("workers were started %d and %d times, want 3", failing.Load(), panicking.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if failing.Load() != 3 || panicking.Load() != 3 {
+		t.Errorf("workers were started %d and %d times after shutdown, want 3", failing.Load(), panicking.Load())
+	}
+}
+
+func TestSupervisor_WaitsForWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var finished atomic.Bool
+	s := supervisor.New(time.Second)
+	s.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+
+	cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Run() returned before the worker finished")
+	}
+}
+
+func TestSupervisor_ShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	s := supervisor.New(50 * time.Millisecond)
+	s.Add("stuck", func(ctx context.Context) error {
+		<-stuck
+		return nil
+	})
+	s.Add("quick", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	cancel()
+	err := s.Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "stuck") || strings.Contains(err.Error(), "quick") {
+		t.Fatalf("Run() error = %v, want an error naming the stuck worker", err)
+	}
+}